@@ -0,0 +1,177 @@
+// Package apikey authenticates machine-to-machine callers with API keys. A key is
+// "<prefix>_<id>_<secret>", only the SHA-256 hash of the secret is stored, and it
+// carries the subject, the scopes and the expiration time of the caller.
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrKeyInvalid = errors.New("apikey: invalid key")
+	ErrKeyExpired = fmt.Errorf("%w: key expired", ErrKeyInvalid)
+)
+
+const (
+	idSize     = 8
+	secretSize = 32
+)
+
+// Key is a stored API key.
+type Key struct {
+	ID string `json:"id"`
+	// Name describes the key, e.g. the caller using it.
+	Name    string   `json:"name,omitempty"`
+	Subject string   `json:"sub"`
+	Scopes  []string `json:"scopes,omitempty"`
+	// Hash is the hex SHA-256 hash of the secret.
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"createdAt"`
+	// ExpiresAt is zero for a key that doesn't expire.
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+}
+
+// Expired reports whether the key is expired at now.
+func (k *Key) Expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+// HasScopes reports whether the key has all the scopes.
+func (k *Key) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(k.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// Claims returns the claims of the key, so that the key is authorized as a token,
+// e.g. by the authz engine with the "scopes" claim as the roles.
+func (k *Key) Claims() *Claims {
+	c := &Claims{
+		Name:   k.Name,
+		Scopes: slices.Clone(k.Scopes),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       k.ID,
+			Subject:  k.Subject,
+			IssuedAt: jwt.NewNumericDate(k.CreatedAt),
+		},
+	}
+	if !k.ExpiresAt.IsZero() {
+		c.ExpiresAt = jwt.NewNumericDate(k.ExpiresAt)
+	}
+	return c
+}
+
+// Claims are the claims of an API key.
+type Claims struct {
+	Name   string   `json:"name,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Manager creates, verifies and revokes the API keys.
+type Manager struct {
+	store   Store
+	options *Options
+	now     func() time.Time
+}
+
+func New(store Store, opts ...Option) *Manager {
+	return &Manager{
+		store:   store,
+		options: Apply(opts...),
+		now:     time.Now,
+	}
+}
+
+// Create stores a new key with the name, the subject, the scopes and the expiration
+// time of key, its ID, hash and creation time are set. It returns the key to hand to
+// the caller, it can't be recovered from the store.
+func (m *Manager) Create(ctx context.Context, key *Key) (string, error) {
+	id, err := randomHex(idSize)
+	if err != nil {
+		return "", err
+	}
+	secret, err := randomHex(secretSize)
+	if err != nil {
+		return "", err
+	}
+
+	key.ID = id
+	key.Hash = hash(secret)
+	key.CreatedAt = m.now()
+	if key.ExpiresAt.IsZero() && m.options.Expired > 0 {
+		key.ExpiresAt = key.CreatedAt.Add(m.options.Expired)
+	}
+	if err := m.store.Save(ctx, key); err != nil {
+		return "", err
+	}
+	return m.options.Prefix + "_" + id + "_" + secret, nil
+}
+
+// Verify returns the stored key of apiKey, ErrKeyInvalid if apiKey is unknown or
+// its secret doesn't match, ErrKeyExpired if it is expired.
+func (m *Manager) Verify(ctx context.Context, apiKey string) (*Key, error) {
+	id, secret, ok := m.parse(apiKey)
+	if !ok {
+		return nil, ErrKeyInvalid
+	}
+	key, err := m.store.Get(ctx, id)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, ErrKeyInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hash(secret)), []byte(key.Hash)) != 1 {
+		return nil, ErrKeyInvalid
+	}
+	if key.Expired(m.now()) {
+		return nil, ErrKeyExpired
+	}
+	return key, nil
+}
+
+// Revoke deletes the key of id.
+func (m *Manager) Revoke(ctx context.Context, id string) error {
+	return m.store.Delete(ctx, id)
+}
+
+// parse splits apiKey into its ID and secret.
+func (m *Manager) parse(apiKey string) (id, secret string, ok bool) {
+	rest, ok := strings.CutPrefix(apiKey, m.options.Prefix+"_")
+	if !ok {
+		return "", "", false
+	}
+	id, secret, ok = strings.Cut(rest, "_")
+	if !ok || len(id) != 2*idSize || len(secret) != 2*secretSize {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
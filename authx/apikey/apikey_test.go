@@ -0,0 +1,110 @@
+package apikey
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/apus-run/van/cache/memory"
+	"github.com/apus-run/van/cache/mocks"
+)
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(memory.New())
+	m := New(store, WithPrefix("test"))
+
+	key := &Key{Name: "billing", Subject: "svc-billing", Scopes: []string{"orders:read", "orders:write"}}
+	apiKey, err := m.Create(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(apiKey, "test_"+key.ID+"_"))
+	assert.NotContains(t, key.Hash, strings.TrimPrefix(apiKey, "test_"+key.ID+"_"))
+
+	got, err := m.Verify(ctx, apiKey)
+	require.NoError(t, err)
+	assert.Equal(t, "svc-billing", got.Subject)
+	assert.True(t, got.HasScopes("orders:read"))
+	assert.False(t, got.HasScopes("orders:read", "users:read"))
+	assert.Equal(t, []string{"orders:read", "orders:write"}, got.Claims().Scopes)
+
+	for _, invalid := range []string{
+		"",
+		"test_" + key.ID,
+		strings.Replace(apiKey, "test_", "vk_", 1),
+		apiKey[:len(apiKey)-1] + "0",
+		"test_0000000000000000_" + strings.Repeat("0", 64),
+	} {
+		if invalid == apiKey {
+			continue
+		}
+		_, err = m.Verify(ctx, invalid)
+		assert.ErrorIs(t, err, ErrKeyInvalid, invalid)
+	}
+
+	require.NoError(t, m.Revoke(ctx, key.ID))
+	_, err = m.Verify(ctx, apiKey)
+	assert.ErrorIs(t, err, ErrKeyInvalid)
+}
+
+func TestManager_Expired(t *testing.T) {
+	ctx := context.Background()
+	m := New(NewStore(memory.New()), WithExpired(time.Hour))
+
+	key := &Key{Subject: "svc"}
+	apiKey, err := m.Create(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, key.CreatedAt.Add(time.Hour), key.ExpiresAt)
+	assert.NotNil(t, key.Claims().ExpiresAt)
+
+	_, err = m.Verify(ctx, apiKey)
+	require.NoError(t, err)
+
+	m.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = m.Verify(ctx, apiKey)
+	assert.ErrorIs(t, err, ErrKeyExpired)
+	assert.ErrorIs(t, err, ErrKeyInvalid)
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockCmdable(ctrl)
+	hash := make(map[string]string)
+	// 键保存在没有 TTL 的 hash 中
+	client.EXPECT().HSet(gomock.Any(), "apikeys", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ string, values ...any) *redis.IntCmd {
+			hash[values[0].(string)] = values[1].(string)
+			return redis.NewIntResult(1, nil)
+		})
+	client.EXPECT().HGet(gomock.Any(), "apikeys", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _, field string) *redis.StringCmd {
+			val, ok := hash[field]
+			if !ok {
+				return redis.NewStringResult("", redis.Nil)
+			}
+			return redis.NewStringResult(val, nil)
+		}).Times(2)
+	client.EXPECT().HDel(gomock.Any(), "apikeys", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ string, fields ...string) *redis.IntCmd {
+			delete(hash, fields[0])
+			return redis.NewIntResult(1, nil)
+		})
+
+	m := New(NewRedisStore(client, "apikeys"))
+	key := &Key{Subject: "svc"}
+	apiKey, err := m.Create(ctx, key)
+	require.NoError(t, err)
+	got, err := m.Verify(ctx, apiKey)
+	require.NoError(t, err)
+	assert.Equal(t, "svc", got.Subject)
+
+	require.NoError(t, m.Revoke(ctx, key.ID))
+	_, err = m.Verify(ctx, apiKey)
+	assert.ErrorIs(t, err, ErrKeyInvalid)
+}
@@ -0,0 +1,42 @@
+package apikey
+
+import "time"
+
+// Option is api key manager option.
+type Option func(*Options)
+
+type Options struct {
+	// Prefix of the issued keys, it tells them apart from other secrets, e.g. in secret scanners.
+	Prefix string
+	// Expired is the lifetime of the keys created without an expiration time, 0 for no expiration.
+	Expired time.Duration
+}
+
+// DefaultOptions .
+func DefaultOptions() *Options {
+	return &Options{
+		Prefix: "vk",
+	}
+}
+
+func Apply(opts ...Option) *Options {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithPrefix set the prefix of the issued keys, "vk" by default.
+func WithPrefix(prefix string) Option {
+	return func(o *Options) {
+		o.Prefix = prefix
+	}
+}
+
+// WithExpired set the lifetime of the keys created without an expiration time (default no expiration).
+func WithExpired(expired time.Duration) Option {
+	return func(o *Options) {
+		o.Expired = expired
+	}
+}
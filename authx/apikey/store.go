@@ -0,0 +1,123 @@
+package apikey
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/apus-run/van/cache"
+)
+
+var ErrKeyNotFound = errors.New("apikey: key not found")
+
+// Store stores the keys by their ID, the secrets are stored as hashes only.
+type Store interface {
+	// Save stores the key, replacing the key of the same ID.
+	Save(ctx context.Context, key *Key) error
+	// Get returns the key of id, ErrKeyNotFound if there is none.
+	Get(ctx context.Context, id string) (*Key, error)
+	// Delete deletes the key of id.
+	Delete(ctx context.Context, id string) error
+}
+
+// NewStore returns a Store keeping the keys as JSON in s, the expired keys are evicted by s.
+// s must not evict the other keys, e.g. an LRU storage or a redis with an eviction
+// policy silently revokes them, use NewRedisStore for a durable store.
+func NewStore(s cache.Storage) Store {
+	return &cacheStore{storage: s}
+}
+
+type cacheStore struct {
+	storage cache.Storage
+}
+
+func storeKey(id string) string { return "apikey:" + id }
+
+func (s *cacheStore) Save(ctx context.Context, key *Key) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	var exp time.Duration
+	if !key.ExpiresAt.IsZero() {
+		if exp = time.Until(key.ExpiresAt); exp <= 0 {
+			return s.storage.Delete(ctx, storeKey(key.ID))
+		}
+	}
+	return s.storage.Set(ctx, storeKey(key.ID), string(data), exp)
+}
+
+func (s *cacheStore) Get(ctx context.Context, id string) (*Key, error) {
+	val, err := s.storage.Get(ctx, storeKey(id))
+	if errors.Is(err, cache.ErrKeyNotExist) || errors.Is(err, cache.ErrItemExpired) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	switch v := val.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return nil, fmt.Errorf("apikey: unexpected stored value %T", val)
+	}
+	key := new(Key)
+	if err := json.Unmarshal(data, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (s *cacheStore) Delete(ctx context.Context, id string) error {
+	return s.storage.Delete(ctx, storeKey(id))
+}
+
+// NewRedisStore returns a Store keeping the keys as JSON in the redis hash of key,
+// without TTL, so that they aren't evicted. The expired keys are kept until they
+// are deleted, Manager.Verify rejects them.
+func NewRedisStore(client redis.Cmdable, key string) Store {
+	return &redisStore{client: client, key: key}
+}
+
+type redisStore struct {
+	client redis.Cmdable
+	key    string
+}
+
+func (s *redisStore) Save(ctx context.Context, key *Key) error {
+	if key.Expired(time.Now()) {
+		return s.Delete(ctx, key.ID)
+	}
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	return s.client.HSet(ctx, s.key, key.ID, string(data)).Err()
+}
+
+func (s *redisStore) Get(ctx context.Context, id string) (*Key, error) {
+	val, err := s.client.HGet(ctx, s.key, id).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	key := new(Key)
+	if err := json.Unmarshal([]byte(val), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (s *redisStore) Delete(ctx context.Context, id string) error {
+	return s.client.HDel(ctx, s.key, id).Err()
+}
@@ -0,0 +1,102 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/authx/jwt/store/memory"
+)
+
+func TestJwtAuth_SignClaims(t *testing.T) {
+	ctx := context.Background()
+	j := NewJwtAuth[*CustomClaims](nil)
+
+	for _, id := range []uint64{1, 2} {
+		token, err := j.SignClaims(ctx, &CustomClaims{
+			UserID:           id,
+			UserAgent:        "van",
+			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		})
+		require.NoError(t, err)
+		assert.InDelta(t, time.Now().Add(time.Hour).Unix(), token.GetExpireAt(), 1)
+
+		claims, err := j.ParseClaims(ctx, token.GetToken())
+		require.NoError(t, err)
+		assert.Equal(t, id, claims.UserID)
+		assert.Equal(t, "van", claims.UserAgent)
+	}
+
+	// 没有 WithClaims 时不能使用静态 claims 签名
+	_, err := j.Sign(ctx)
+	assert.ErrorIs(t, err, ErrClaimsMissing)
+}
+
+func TestJwtAuth_ClaimsKinds(t *testing.T) {
+	ctx := context.Background()
+
+	values := NewJwtAuth[jwt.RegisteredClaims](nil)
+	token, err := values.SignClaims(ctx, jwt.RegisteredClaims{Subject: "1"})
+	require.NoError(t, err)
+	rc, err := values.ParseClaims(ctx, token.GetToken())
+	require.NoError(t, err)
+	assert.Equal(t, "1", rc.Subject)
+
+	maps := NewJwtAuth[jwt.MapClaims](nil)
+	token, err = maps.SignClaims(ctx, jwt.MapClaims{"sub": "2", "role": "admin"})
+	require.NoError(t, err)
+	mc, err := maps.ParseClaims(ctx, token.GetToken())
+	require.NoError(t, err)
+	assert.Equal(t, "admin", mc["role"])
+
+	claims := NewJwtAuth[jwt.Claims](nil)
+	c, err := claims.ParseClaims(ctx, token.GetToken())
+	require.NoError(t, err)
+	sub, err := c.GetSubject()
+	require.NoError(t, err)
+	assert.Equal(t, "2", sub)
+}
+
+func TestJwtAuth_RefreshClaims(t *testing.T) {
+	ctx := context.Background()
+	j := NewJwtAuth[*CustomClaims](memory.NewStore())
+	newClaims := func(id uint64, agent string) *CustomClaims {
+		now := time.Now()
+		return &CustomClaims{
+			UserID:    id,
+			UserAgent: agent,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "1",
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			},
+		}
+	}
+
+	pair, err := j.SignPairClaims(ctx, newClaims(1, "login"))
+	require.NoError(t, err)
+
+	// 加载失败时 refresh token 没有被用掉
+	errLoad := errors.New("load failed")
+	_, err = j.RefreshClaims(ctx, pair.RefreshToken, func(ctx context.Context, subject string) (*CustomClaims, error) {
+		return nil, errLoad
+	})
+	assert.ErrorIs(t, err, errLoad)
+
+	next, err := j.RefreshClaims(ctx, pair.RefreshToken, func(ctx context.Context, subject string) (*CustomClaims, error) {
+		assert.Equal(t, "1", subject)
+		return newClaims(1, "refresh"), nil
+	})
+	require.NoError(t, err)
+	claims, err := j.ParseClaims(ctx, next.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "refresh", claims.UserAgent)
+
+	_, err = j.Refresh(ctx, next.RefreshToken)
+	assert.ErrorIs(t, err, ErrClaimsMissing)
+}
@@ -0,0 +1,321 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
+)
+
+// JWKSPath is the well-known path of the JSON Web Key Set.
+const JWKSPath = "/.well-known/jwks.json"
+
+var ErrUnsupportedJWK = errors.New("unsupported json web key")
+
+// JWK is a public JSON Web Key, RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC and OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var b64 = base64.RawURLEncoding
+
+// NewJWK returns the public JWK of key.
+func NewJWK(key *Key) (JWK, error) {
+	jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = b64.EncodeToString(pub.N.Bytes())
+		jwk.E = b64.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = b64.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = b64.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = b64.EncodeToString(pub)
+	default:
+		return JWK{}, fmt.Errorf("%w: %T", ErrUnsupportedJWK, key.Public)
+	}
+	return jwk, nil
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of the key.
+func (k JWK) Thumbprint() (string, error) {
+	var members any
+	switch k.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{k.E, k.Kty, k.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{k.Crv, k.Kty, k.X, k.Y}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{k.Crv, k.Kty, k.X}
+	default:
+		return "", fmt.Errorf("%w: kty %q", ErrUnsupportedJWK, k.Kty)
+	}
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return b64.EncodeToString(sum[:]), nil
+}
+
+// Key returns the verifying key of the JWK. Without "alg" the method is
+// RS256 for RSA keys, the ES method of the curve for EC keys, and EdDSA for Ed25519 keys.
+func (k JWK) Key() (*Key, error) {
+	var (
+		public crypto.PublicKey
+		method jwt.SigningMethod
+	)
+	switch k.Kty {
+	case "RSA":
+		n, err := b64.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := b64.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		public = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		method = jwt.SigningMethodRS256
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve, method = elliptic.P256(), jwt.SigningMethodES256
+		case "P-384":
+			curve, method = elliptic.P384(), jwt.SigningMethodES384
+		case "P-521":
+			curve, method = elliptic.P521(), jwt.SigningMethodES512
+		default:
+			return nil, fmt.Errorf("%w: crv %q", ErrUnsupportedJWK, k.Crv)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := b64.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		public = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("%w: crv %q", ErrUnsupportedJWK, k.Crv)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: invalid Ed25519 key", ErrUnsupportedJWK)
+		}
+		public, method = ed25519.PublicKey(x), jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("%w: kty %q", ErrUnsupportedJWK, k.Kty)
+	}
+
+	if k.Alg != "" {
+		if method = jwt.GetSigningMethod(k.Alg); method == nil {
+			return nil, fmt.Errorf("%w: alg %q", ErrUnsupportedJWK, k.Alg)
+		}
+	}
+	key, err := NewPublicKey(method, public)
+	if err != nil {
+		return nil, err
+	}
+	if k.Kid != "" {
+		key.ID = k.Kid
+	}
+	return key, nil
+}
+
+// KeySet returns a key set verifying with the signing keys of the JWKS,
+// the keys of other uses or unsupported types are skipped.
+func (s *JWKS) KeySet() *KeySet {
+	ks := NewKeySet(nil, 0)
+	for _, jwk := range s.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.Key(); err == nil {
+			ks.Add(key)
+		}
+	}
+	return ks
+}
+
+// RemoteOption is a RemoteKeySet option.
+type RemoteOption func(*RemoteKeySet)
+
+// WithRemoteClient set the http client fetching the JWKS.
+func WithRemoteClient(client *http.Client) RemoteOption {
+	return func(r *RemoteKeySet) {
+		r.client = client
+	}
+}
+
+// WithRemoteRefresh set the interval refreshing the JWKS (default 1h).
+func WithRemoteRefresh(interval time.Duration) RemoteOption {
+	return func(r *RemoteKeySet) {
+		r.refresh = interval
+	}
+}
+
+// WithRemoteMinRefetch set the minimum interval between two fetches caused by
+// an unknown "kid" (default 1m).
+func WithRemoteMinRefetch(interval time.Duration) RemoteOption {
+	return func(r *RemoteKeySet) {
+		r.minRefetch = interval
+	}
+}
+
+// RemoteKeySet verifies tokens with the JWKS published by another service. The JWKS
+// is fetched on the first use, refreshed periodically and refetched for an unknown "kid".
+type RemoteKeySet struct {
+	url        string
+	client     *http.Client
+	refresh    time.Duration
+	minRefetch time.Duration
+
+	mu        sync.RWMutex
+	keys      *KeySet
+	fetchedAt time.Time
+	group     singleflight.Group
+}
+
+func NewRemoteKeySet(url string, opts ...RemoteOption) *RemoteKeySet {
+	r := &RemoteKeySet{
+		url:        url,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		refresh:    time.Hour,
+		minRefetch: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Keyfunc is the jwt.Keyfunc of the remote key set.
+func (r *RemoteKeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, err := r.key(kid)
+	if err != nil {
+		return nil, err
+	}
+	return verifyKey(key, token)
+}
+
+func (r *RemoteKeySet) key(kid string) (*Key, error) {
+	r.mu.RLock()
+	keys, fetchedAt := r.keys, r.fetchedAt
+	r.mu.RUnlock()
+
+	var (
+		key   *Key
+		found bool
+	)
+	if keys != nil {
+		key, found = keys.Key(kid)
+	}
+	age := time.Since(fetchedAt)
+	switch {
+	case found && age < r.refresh:
+		return key, nil
+	case !found && keys != nil && age < r.minRefetch:
+		return nil, ErrKeyNotFound
+	}
+
+	keys, err := r.fetch()
+	if err != nil {
+		// keep verifying with the known key while the JWKS can't be refreshed
+		if found {
+			return key, nil
+		}
+		return nil, err
+	}
+	if key, found = keys.Key(kid); !found {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+func (r *RemoteKeySet) fetch() (*KeySet, error) {
+	v, err, _ := r.group.Do(r.url, func() (any, error) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, r.url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetch jwks %s: %s", r.url, resp.Status)
+		}
+
+		jwks := new(JWKS)
+		if err := json.NewDecoder(resp.Body).Decode(jwks); err != nil {
+			return nil, fmt.Errorf("decode jwks %s: %w", r.url, err)
+		}
+		keys := jwks.KeySet()
+
+		r.mu.Lock()
+		r.keys, r.fetchedAt = keys, time.Now()
+		r.mu.Unlock()
+		return keys, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*KeySet), nil
+}
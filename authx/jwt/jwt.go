@@ -2,7 +2,11 @@ package jwt
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/apus-run/van/authx"
@@ -14,61 +18,116 @@ var (
 	ErrUnSupportSigningMethod = errors.New("wrong signing method")
 	ErrSignToken              = errors.New("can not sign token. is the key correct")
 	ErrGetKey                 = errors.New("can not get key while signing token")
+	ErrClaimsMissing          = errors.New("claims are missing, set them with WithClaims")
+	ErrStorerUnsupported      = errors.New("the storer is not a RotatingStorer")
+	// ErrTokenRevoked the token is blacklisted, or its family or user is logged out.
+	ErrTokenRevoked = fmt.Errorf("%w: token is revoked", ErrTokenInvalid)
+	// ErrTokenReused a rotated refresh token is used again, the whole family is revoked.
+	ErrTokenReused = fmt.Errorf("%w: refresh token is reused", ErrTokenInvalid)
 )
 
-// JwtAuth implement the authx.Authenticator interface.
+var _ authx.Authenticator[*jwt.RegisteredClaims] = (*JwtAuth[*jwt.RegisteredClaims])(nil)
 
-type JwtAuth struct {
+// JwtAuth implement the authx.Authenticator interface, it signs and verifies the tokens
+// of the claims C, usually a pointer to a claims struct such as *jwt.RegisteredClaims.
+type JwtAuth[C jwt.Claims] struct {
 	*options
 	store Storer
 }
 
-func NewJwtAuth(store Storer, opts ...Option) *JwtAuth {
+func NewJwtAuth[C jwt.Claims](store Storer, opts ...Option) *JwtAuth[C] {
 	options := Apply(opts...)
-	return &JwtAuth{
+	return &JwtAuth[C]{
 		options: options,
 		store:   store,
 	}
 }
 
-func (j *JwtAuth) Sign(ctx context.Context) (authx.Token, error) {
-	now := time.Now()
-	expiresAt := now.Add(j.expired)
+// Sign signs the claims of WithClaims.
+func (j *JwtAuth[C]) Sign(ctx context.Context) (authx.Token, error) {
+	if j.claims == nil {
+		return nil, ErrClaimsMissing
+	}
+	return j.sign(j.claims())
+}
+
+// SignClaims signs the claims of a user, such as its subject, roles and tenant.
+func (j *JwtAuth[C]) SignClaims(ctx context.Context, claims C) (authx.Token, error) {
+	return j.sign(claims)
+}
 
-	tokenString, err := j.GenerateToken(ctx)
+func (j *JwtAuth[C]) sign(claims jwt.Claims) (authx.Token, error) {
+	tokenString, err := j.generate(claims, nil)
 	if err != nil {
 		return nil, err
 	}
 	tokenInfo := &tokenInfo{
 		Token:     tokenString,
 		Type:      j.tokenType,
-		ExpiresAt: expiresAt.Unix(),
+		ExpiresAt: j.expiresAt(claims, time.Now()).Unix(),
 	}
 	return tokenInfo, nil
 }
 
-func (j *JwtAuth) Destroy(ctx context.Context, refreshToken string) error {
-	claims, err := j.ParseClaims(ctx, refreshToken)
+// expiresAt returns the expiration time of claims, now plus the expiration of the
+// options if claims don't expire.
+func (j *JwtAuth[C]) expiresAt(claims jwt.Claims, now time.Time) time.Time {
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		return exp.Time
+	}
+	return now.Add(j.expired)
+}
+
+// Destroy revokes a token: the family of a refresh token, so the session is logged out,
+// or an access token until it expires.
+func (j *JwtAuth[C]) Destroy(ctx context.Context, token string) error {
+	if isRefreshToken(token) {
+		claims, err := j.parseRefresh(ctx, token)
+		if err != nil {
+			return err
+		}
+		return j.callStore(func(store Storer) error {
+			return store.Set(ctx, familyKey(claims.Family), "1", j.refreshExpired)
+		})
+	}
+
+	claims, err := j.ParseClaims(ctx, token)
 	if err != nil {
 		return err
 	}
 
 	// If storage is set, put the unexpired token in
 	store := func(store Storer) error {
-		expired := time.Until(claims.ExpiresAt.Time)
-		return store.Set(ctx, refreshToken, "1", expired)
+		return store.Set(ctx, token, "1", time.Until(j.expiresAt(claims, time.Now())))
 	}
 	return j.callStore(store)
 }
 
-func (j *JwtAuth) ParseClaims(ctx context.Context, accessToken string) (*jwt.RegisteredClaims, error) {
+// DestroyAll logs the user out everywhere, the tokens of subject issued before now are revoked.
+// It requires a RotatingStorer.
+func (j *JwtAuth[C]) DestroyAll(ctx context.Context, subject string) error {
+	return j.callStore(func(store Storer) error {
+		if _, ok := store.(RotatingStorer); !ok {
+			return ErrStorerUnsupported
+		}
+		return store.Set(ctx, userKey(subject), time.Now().Unix(), max(j.expired, j.refreshExpired))
+	})
+}
+
+// ParseClaims verifies accessToken and returns its claims.
+func (j *JwtAuth[C]) ParseClaims(ctx context.Context, accessToken string) (C, error) {
+	var zero C
 	if accessToken == "" {
-		return nil, ErrTokenInvalid
+		return zero, ErrTokenInvalid
 	}
 
 	token, err := j.ParseToken(ctx, accessToken)
 	if err != nil {
-		return nil, err
+		return zero, err
+	}
+	claims, ok := typedClaims[C](token.Claims)
+	if !ok {
+		return zero, ErrTokenInvalid
 	}
 
 	store := func(store Storer) error {
@@ -78,46 +137,166 @@ func (j *JwtAuth) ParseClaims(ctx context.Context, accessToken string) (*jwt.Reg
 		}
 
 		if exists {
-			return ErrTokenInvalid
+			return ErrTokenRevoked
 		}
 
-		return nil
+		// the access tokens of a revoked family are revoked with its refresh tokens
+		if family, _ := token.Header[familyHeader].(string); family != "" {
+			if err := checkFamily(ctx, store, family); err != nil {
+				return err
+			}
+		}
+
+		return checkUser(ctx, store, registeredClaims(claims))
 	}
 
 	if err := j.callStore(store); err != nil {
-		return nil, err
+		return zero, err
 	}
 
-	return token.Claims.(*jwt.RegisteredClaims), nil
+	return claims, nil
+}
+
+// checkFamily returns ErrTokenRevoked if the token family is revoked.
+func checkFamily(ctx context.Context, store Storer, family string) error {
+	revoked, err := store.Check(ctx, familyKey(family))
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+	return nil
 }
 
-func (j *JwtAuth) ParseToken(ctx context.Context, accessToken string) (token *jwt.Token, err error) {
-	if j.claims != nil {
-		token, err = jwt.ParseWithClaims(accessToken, j.claims(), j.keyfunc)
-	} else {
-		token, err = jwt.Parse(accessToken, j.keyfunc)
+// checkUser returns ErrTokenRevoked if the subject of claims logged out everywhere
+// after the token was issued, it requires a RotatingStorer.
+func checkUser(ctx context.Context, store Storer, claims *jwt.RegisteredClaims) error {
+	rs, ok := store.(RotatingStorer)
+	if !ok || claims.Subject == "" {
+		return nil
+	}
+	val, err := rs.Get(ctx, userKey(claims.Subject))
+	if err != nil || val == "" {
+		return err
+	}
+	logoutAt, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return err
+	}
+	// iat has a precision of seconds, the tokens issued in the second of the logout stay valid
+	if claims.IssuedAt == nil || claims.IssuedAt.Unix() < logoutAt {
+		return ErrTokenRevoked
 	}
+	return nil
+}
+
+// registeredClaims returns the registered claims of claims, custom claims are
+// read through the getters of jwt.Claims.
+func registeredClaims(claims jwt.Claims) *jwt.RegisteredClaims {
+	if rc, ok := claims.(*jwt.RegisteredClaims); ok {
+		return rc
+	}
+	rc := new(jwt.RegisteredClaims)
+	rc.Issuer, _ = claims.GetIssuer()
+	rc.Subject, _ = claims.GetSubject()
+	rc.Audience, _ = claims.GetAudience()
+	rc.ExpiresAt, _ = claims.GetExpirationTime()
+	rc.NotBefore, _ = claims.GetNotBefore()
+	rc.IssuedAt, _ = claims.GetIssuedAt()
+	if mc, ok := claims.(jwt.MapClaims); ok {
+		rc.ID, _ = mc["jti"].(string)
+	}
+	return rc
+}
+
+func (j *JwtAuth[C]) ParseToken(ctx context.Context, accessToken string) (token *jwt.Token, err error) {
+	token, err = jwt.ParseWithClaims(accessToken, j.newClaims(), j.keyfunc)
 
 	// 过期的, 伪造的, 都可以认为是无效token
 	if err != nil || !token.Valid {
 		return nil, ErrTokenInvalid
 	}
 
-	if token.Method != j.signingMethod {
-		return nil, ErrUnSupportSigningMethod
+	// a refresh token is not an access token
+	if typ, _ := token.Header["typ"].(string); typ == refreshTokenType {
+		return nil, ErrTokenInvalid
+	}
+
+	if err := j.checkMethod(token); err != nil {
+		return nil, err
 	}
 
 	return token, nil
 }
 
-func (j *JwtAuth) GenerateToken(ctx context.Context) (string, error) {
-	token := jwt.NewWithClaims(j.signingMethod, j.claims())
-	if j.tokenHeader != nil {
-		for k, v := range j.tokenHeader {
-			token.Header[k] = v
+// GenerateToken signs the claims of WithClaims.
+func (j *JwtAuth[C]) GenerateToken(ctx context.Context) (string, error) {
+	if j.claims == nil {
+		return "", ErrClaimsMissing
+	}
+	return j.generate(j.claims(), nil)
+}
+
+// newClaims returns the claims a token is parsed into: a new C, or a *C for a struct C.
+// For an interface C they are the claims of WithClaims, or jwt.MapClaims.
+func (j *JwtAuth[C]) newClaims() jwt.Claims {
+	t := reflect.TypeFor[C]()
+	switch t.Kind() {
+	case reflect.Pointer:
+		return reflect.New(t.Elem()).Interface().(jwt.Claims)
+	case reflect.Map:
+		return reflect.MakeMap(t).Interface().(jwt.Claims)
+	case reflect.Interface:
+		if j.claims != nil {
+			return j.claims()
+		}
+		return jwt.MapClaims{}
+	default:
+		return reflect.New(t).Interface().(jwt.Claims)
+	}
+}
+
+// typedClaims returns the parsed claims as C.
+func typedClaims[C jwt.Claims](claims jwt.Claims) (C, bool) {
+	if c, ok := claims.(C); ok {
+		return c, true
+	}
+	// a struct C is parsed into a *C
+	if v := reflect.ValueOf(claims); v.Kind() == reflect.Pointer {
+		c, ok := v.Elem().Interface().(C)
+		return c, ok
+	}
+	var zero C
+	return zero, false
+}
+
+// generate signs claims with the token headers and the extra headers.
+func (j *JwtAuth[C]) generate(claims jwt.Claims, header map[string]any) (string, error) {
+	method := j.signingMethod
+	var kid string
+	if j.keySet != nil {
+		if key := j.keySet.Current(); key != nil {
+			method, kid = key.Method, key.ID
 		}
 	}
-	key, err := j.keyfunc(token)
+
+	// the logout everywhere of checkUser compares the iat of the tokens
+	if iat, err := claims.GetIssuedAt(); err == nil && iat == nil {
+		claims = issuedClaims{Claims: claims, issuedAt: jwt.NewNumericDate(time.Now())}
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	for k, v := range j.tokenHeader {
+		token.Header[k] = v
+	}
+	for k, v := range header {
+		token.Header[k] = v
+	}
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	key, err := j.signingKey(token)
 	if err != nil {
 		return "", ErrGetKey
 	}
@@ -129,7 +308,41 @@ func (j *JwtAuth) GenerateToken(ctx context.Context) (string, error) {
 	return tokenStr, nil
 }
 
-func (j *JwtAuth) callStore(fn func(Storer) error) error {
+// issuedClaims adds the iat claim to the claims lacking it when they are signed,
+// without modifying them.
+type issuedClaims struct {
+	jwt.Claims
+	issuedAt *jwt.NumericDate
+}
+
+func (c issuedClaims) GetIssuedAt() (*jwt.NumericDate, error) {
+	return c.issuedAt, nil
+}
+
+func (c issuedClaims) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(c.Claims)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if m["iat"], err = json.Marshal(c.issuedAt); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// checkMethod checks the algorithm of token, unless the keyfunc of a key set checked it.
+func (j *JwtAuth[C]) checkMethod(token *jwt.Token) error {
+	if !j.keyAlg && token.Method != j.signingMethod {
+		return ErrUnSupportSigningMethod
+	}
+	return nil
+}
+
+func (j *JwtAuth[C]) callStore(fn func(Storer) error) error {
 	if store := j.store; store != nil {
 		return fn(store)
 	}
@@ -67,7 +67,7 @@ func TestNewJwtAuth(t *testing.T) {
 
 	store := redis.NewStore(nil, "authx")
 
-	j, err := NewJwtAuth(store, opts...).Sign(context.Background())
+	j, err := NewJwtAuth[*CustomClaims](store, opts...).Sign(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
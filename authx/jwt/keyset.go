@@ -0,0 +1,314 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrKeyNotFound = fmt.Errorf("%w: key not found", ErrTokenInvalid)
+
+// Key is an asymmetric key of a KeySet.
+type Key struct {
+	// ID is the "kid" header of the tokens signed by the key,
+	// the RFC 7638 thumbprint of the public key by default.
+	ID     string
+	Method jwt.SigningMethod
+	// Private signs the tokens, nil for a key that only verifies.
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// NewKey returns the key of private for method.
+func NewKey(method jwt.SigningMethod, private crypto.Signer) (*Key, error) {
+	return newKey(method, private, private.Public())
+}
+
+// NewPublicKey returns a key verifying the tokens of method.
+func NewPublicKey(method jwt.SigningMethod, public crypto.PublicKey) (*Key, error) {
+	return newKey(method, nil, public)
+}
+
+func newKey(method jwt.SigningMethod, private crypto.Signer, public crypto.PublicKey) (*Key, error) {
+	if !compatible(method, public) {
+		return nil, fmt.Errorf("%w: %T for %s", ErrUnSupportSigningMethod, public, method.Alg())
+	}
+	k := &Key{Method: method, Private: private, Public: public}
+	jwk, err := NewJWK(k)
+	if err != nil {
+		return nil, err
+	}
+	if k.ID, err = jwk.Thumbprint(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// compatible reports whether public verifies the tokens of method.
+func compatible(method jwt.SigningMethod, public crypto.PublicKey) bool {
+	switch m := method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := public.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		pub, ok := public.(*ecdsa.PublicKey)
+		return ok && pub.Curve.Params().BitSize == m.CurveBits
+	case *jwt.SigningMethodEd25519:
+		_, ok := public.(ed25519.PublicKey)
+		return ok
+	}
+	return false
+}
+
+// GenerateKey generates a key for method: RSA 2048 bits for RS and PS methods,
+// the curve of ES256, ES384 and ES512, and Ed25519 for EdDSA.
+func GenerateKey(method jwt.SigningMethod) (*Key, error) {
+	var (
+		private crypto.Signer
+		err     error
+	)
+	switch method.Alg() {
+	case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ES384":
+		private, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case "ES512":
+		private, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case "EdDSA":
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, ErrUnSupportSigningMethod
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(method, private)
+}
+
+// LoadKeyFile loads the PEM encoded private key of method from path.
+func LoadKeyFile(method jwt.SigningMethod, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var private crypto.Signer
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		private, err = jwt.ParseRSAPrivateKeyFromPEM(data)
+	case *jwt.SigningMethodECDSA:
+		private, err = jwt.ParseECPrivateKeyFromPEM(data)
+	case *jwt.SigningMethodEd25519:
+		var key crypto.PrivateKey
+		if key, err = jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			private = key.(crypto.Signer)
+		}
+	default:
+		return nil, ErrUnSupportSigningMethod
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load key %s: %w", path, err)
+	}
+	return NewKey(method, private)
+}
+
+// LoadPublicKeyFile loads the PEM encoded public key of method from path.
+func LoadPublicKeyFile(method jwt.SigningMethod, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var public crypto.PublicKey
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		public, err = jwt.ParseRSAPublicKeyFromPEM(data)
+	case *jwt.SigningMethodECDSA:
+		public, err = jwt.ParseECPublicKeyFromPEM(data)
+	case *jwt.SigningMethodEd25519:
+		public, err = jwt.ParseEdPublicKeyFromPEM(data)
+	default:
+		return nil, ErrUnSupportSigningMethod
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load public key %s: %w", path, err)
+	}
+	return NewPublicKey(method, public)
+}
+
+// KeySet holds the current signing key, and the keys still verifying tokens:
+// the keys added with Add, and the rotated keys until their grace period ends.
+type KeySet struct {
+	mu      sync.RWMutex
+	current *Key
+	keys    map[string]*Key
+	// retired rotated keys and the end of their grace period
+	retired map[string]time.Time
+	grace   time.Duration
+	now     func() time.Time
+}
+
+// NewKeySet returns a key set signing with current, the rotated keys verify tokens
+// for grace, it should be at least the lifetime of the tokens.
+// current can be nil for a key set that only verifies.
+func NewKeySet(current *Key, grace time.Duration) *KeySet {
+	s := &KeySet{
+		current: current,
+		keys:    make(map[string]*Key),
+		retired: make(map[string]time.Time),
+		grace:   grace,
+		now:     time.Now,
+	}
+	if current != nil {
+		s.keys[current.ID] = current
+	}
+	return s
+}
+
+// Current returns the signing key, nil if there is none.
+func (s *KeySet) Current() *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// Add adds a key verifying tokens, such as the next key published ahead of a rotation.
+func (s *KeySet) Add(key *Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[key.ID] = key
+	delete(s.retired, key.ID)
+}
+
+// Rotate makes next the signing key, the previous one verifies tokens for the grace period.
+func (s *KeySet) Rotate(next *Key) error {
+	if next.Private == nil {
+		return ErrGetKey
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if s.current != nil && s.current.ID != next.ID {
+		s.retired[s.current.ID] = now.Add(s.grace)
+	}
+	s.current = next
+	s.keys[next.ID] = next
+	delete(s.retired, next.ID)
+	s.prune(now)
+	return nil
+}
+
+// AutoRotate rotates to a key of generate every interval, until ctx is done or generate fails.
+// It blocks, and is usually called in its own goroutine.
+func (s *KeySet) AutoRotate(ctx context.Context, interval time.Duration, generate func() (*Key, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			key, err := generate()
+			if err != nil {
+				return err
+			}
+			if err := s.Rotate(key); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Key returns the verifying key of id.
+func (s *KeySet) Key(id string) (*Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, false
+	}
+	if end, retired := s.retired[id]; retired && !s.now().Before(end) {
+		return nil, false
+	}
+	return key, true
+}
+
+// Keys returns the verifying keys.
+func (s *KeySet) Keys() []*Key {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(s.now())
+	keys := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Keyfunc is the jwt.Keyfunc of the key set, it selects the key by the "kid" header.
+func (s *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.Key(kid)
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return verifyKey(key, token)
+}
+
+// JWKS returns the public keys as a JSON Web Key Set.
+func (s *KeySet) JWKS() (*JWKS, error) {
+	jwks := &JWKS{Keys: []JWK{}}
+	for _, key := range s.Keys() {
+		jwk, err := NewJWK(key)
+		if err != nil {
+			return nil, err
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks, nil
+}
+
+// prune removes the keys whose grace period ended.
+func (s *KeySet) prune(now time.Time) {
+	for id, end := range s.retired {
+		if !now.Before(end) {
+			delete(s.keys, id)
+			delete(s.retired, id)
+		}
+	}
+}
+
+// verifyKey returns the public key verifying token, the algorithm of token must be the one of key.
+func verifyKey(key *Key, token *jwt.Token) (any, error) {
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrUnSupportSigningMethod
+	}
+	return key.Public, nil
+}
+
+// signingKey returns the key signing token, the current key of the key set or the key of keyfunc.
+func (j *JwtAuth[C]) signingKey(token *jwt.Token) (any, error) {
+	if j.verifyOnly {
+		return nil, ErrGetKey
+	}
+	if j.keySet == nil {
+		return j.keyfunc(token)
+	}
+	key := j.keySet.Current()
+	if key == nil || key.Private == nil {
+		return nil, ErrGetKey
+	}
+	return key.Private, nil
+}
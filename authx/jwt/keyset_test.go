@@ -0,0 +1,207 @@
+package jwt
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func subjectClaims() jwt.Claims {
+	return &jwt.RegisteredClaims{Subject: "1"}
+}
+
+func TestKeySet_Sign(t *testing.T) {
+	ctx := context.Background()
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodRS256, jwt.SigningMethodES256, jwt.SigningMethodEdDSA} {
+		t.Run(method.Alg(), func(t *testing.T) {
+			key, err := GenerateKey(method)
+			require.NoError(t, err)
+			j := NewJwtAuth[*jwt.RegisteredClaims](nil, WithKeySet(NewKeySet(key, time.Hour)), WithClaims(subjectClaims))
+
+			tokenString, err := j.GenerateToken(ctx)
+			require.NoError(t, err)
+			token, err := j.ParseToken(ctx, tokenString)
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, token.Header["kid"])
+			assert.Equal(t, method.Alg(), token.Method.Alg())
+
+			// 其他 key 签名的 token 无效
+			other, err := GenerateKey(method)
+			require.NoError(t, err)
+			forged, err := NewJwtAuth[*jwt.RegisteredClaims](nil, WithKeySet(NewKeySet(other, time.Hour)), WithClaims(subjectClaims)).GenerateToken(ctx)
+			require.NoError(t, err)
+			_, err = j.ParseToken(ctx, forged)
+			assert.ErrorIs(t, err, ErrTokenInvalid)
+		})
+	}
+}
+
+func TestKeySet_Rotate(t *testing.T) {
+	ctx := context.Background()
+	first, err := GenerateKey(jwt.SigningMethodES256)
+	require.NoError(t, err)
+	ks := NewKeySet(first, time.Hour)
+	now := time.Now()
+	ks.now = func() time.Time { return now }
+	j := NewJwtAuth[*jwt.RegisteredClaims](nil, WithKeySet(ks), WithClaims(subjectClaims))
+
+	old, err := j.GenerateToken(ctx)
+	require.NoError(t, err)
+
+	second, err := GenerateKey(jwt.SigningMethodEdDSA)
+	require.NoError(t, err)
+	require.NoError(t, ks.Rotate(second))
+	assert.Equal(t, second, ks.Current())
+
+	token, err := j.GenerateToken(ctx)
+	require.NoError(t, err)
+	_, err = j.ParseToken(ctx, token)
+	require.NoError(t, err)
+
+	// 宽限期内旧 key 签名的 token 仍然有效
+	_, err = j.ParseToken(ctx, old)
+	require.NoError(t, err)
+	jwks, err := ks.JWKS()
+	require.NoError(t, err)
+	assert.Len(t, jwks.Keys, 2)
+
+	now = now.Add(time.Hour)
+	_, err = j.ParseToken(ctx, old)
+	assert.ErrorIs(t, err, ErrTokenInvalid)
+	jwks, err = ks.JWKS()
+	require.NoError(t, err)
+	assert.Len(t, jwks.Keys, 1)
+
+	public, err := NewPublicKey(second.Method, second.Public)
+	require.NoError(t, err)
+	assert.ErrorIs(t, ks.Rotate(public), ErrGetKey)
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodRS256, jwt.SigningMethodES256, jwt.SigningMethodEdDSA} {
+		t.Run(method.Alg(), func(t *testing.T) {
+			key, err := GenerateKey(method)
+			require.NoError(t, err)
+
+			der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+			require.NoError(t, err)
+			path := filepath.Join(dir, method.Alg()+".pem")
+			require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+			loaded, err := LoadKeyFile(method, path)
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, loaded.ID)
+
+			der, err = x509.MarshalPKIXPublicKey(key.Public)
+			require.NoError(t, err)
+			path = filepath.Join(dir, method.Alg()+".pub.pem")
+			require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+			public, err := LoadPublicKeyFile(method, path)
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, public.ID)
+			assert.Nil(t, public.Private)
+		})
+	}
+
+	_, err := LoadKeyFile(jwt.SigningMethodES256, filepath.Join(dir, "RS256.pem"))
+	assert.Error(t, err)
+}
+
+func TestJWKS(t *testing.T) {
+	ctx := context.Background()
+	var keys []*Key
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodRS256, jwt.SigningMethodES384, jwt.SigningMethodEdDSA} {
+		key, err := GenerateKey(method)
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	ks := NewKeySet(keys[0], time.Hour)
+	ks.Add(keys[1])
+	ks.Add(keys[2])
+
+	jwks, err := ks.JWKS()
+	require.NoError(t, err)
+	data, err := json.Marshal(jwks)
+	require.NoError(t, err)
+
+	parsed := new(JWKS)
+	require.NoError(t, json.Unmarshal(data, parsed))
+	verifier := NewJwtAuth[*jwt.RegisteredClaims](nil, WithKeySet(parsed.KeySet()), WithClaims(subjectClaims))
+	for _, key := range keys {
+		jwk, err := NewJWK(key)
+		require.NoError(t, err)
+		thumbprint, err := jwk.Thumbprint()
+		require.NoError(t, err)
+		assert.Equal(t, key.ID, thumbprint)
+
+		signer := NewJwtAuth[*jwt.RegisteredClaims](nil, WithKeySet(NewKeySet(key, time.Hour)), WithClaims(subjectClaims))
+		token, err := signer.GenerateToken(ctx)
+		require.NoError(t, err)
+		_, err = verifier.ParseToken(ctx, token)
+		assert.NoError(t, err, key.Method.Alg())
+	}
+
+	// 只用于验证的 key set 不能签名
+	_, err = verifier.GenerateToken(ctx)
+	assert.ErrorIs(t, err, ErrGetKey)
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	ctx := context.Background()
+	key, err := GenerateKey(jwt.SigningMethodES256)
+	require.NoError(t, err)
+	ks := NewKeySet(key, time.Hour)
+
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		jwks, err := ks.JWKS()
+		require.NoError(t, err)
+		_ = json.NewEncoder(w).Encode(jwks)
+	}))
+	defer srv.Close()
+
+	signer := NewJwtAuth[*jwt.RegisteredClaims](nil, WithKeySet(ks), WithClaims(subjectClaims))
+	remote := NewRemoteKeySet(srv.URL, WithRemoteMinRefetch(50*time.Millisecond))
+	verifier := NewJwtAuth[*jwt.RegisteredClaims](nil, WithRemoteKeySet(remote), WithClaims(subjectClaims))
+
+	token, err := signer.GenerateToken(ctx)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = verifier.ParseToken(ctx, token)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// 轮换后未知的 kid 触发重新获取, 但有最小间隔
+	next, err := GenerateKey(jwt.SigningMethodES256)
+	require.NoError(t, err)
+	require.NoError(t, ks.Rotate(next))
+	token, err = signer.GenerateToken(ctx)
+	require.NoError(t, err)
+	_, err = verifier.ParseToken(ctx, token)
+	assert.ErrorIs(t, err, ErrTokenInvalid)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = verifier.ParseToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+
+	// 远程 key set 只能验证, 不能签发
+	_, err = verifier.GenerateToken(ctx)
+	assert.ErrorIs(t, err, ErrGetKey)
+	assert.Equal(t, int32(2), fetches.Load())
+}
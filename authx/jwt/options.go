@@ -20,17 +20,26 @@ type options struct {
 	claims        func() jwt.Claims
 	tokenHeader   map[string]any
 
-	expired   time.Duration
-	keyfunc   jwt.Keyfunc
-	tokenType string
+	expired        time.Duration
+	refreshExpired time.Duration
+	keyfunc        jwt.Keyfunc
+	tokenType      string
+
+	// keySet signs with its current key
+	keySet *KeySet
+	// the keyfunc checks the algorithm of the tokens instead of signingMethod
+	keyAlg bool
+	// the keyfunc returns public keys only, the tokens can't be signed
+	verifyOnly bool
 }
 
 // DefaultOptions .
 func DefaultOptions() *options {
 	return &options{
-		tokenType:     "Bearer",
-		expired:       2 * time.Hour,
-		signingMethod: jwt.SigningMethodHS256,
+		tokenType:      "Bearer",
+		expired:        2 * time.Hour,
+		refreshExpired: 7 * 24 * time.Hour,
+		signingMethod:  jwt.SigningMethodHS256,
 		keyfunc: func(token *jwt.Token) (any, error) {
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 				return nil, ErrTokenInvalid
@@ -84,3 +93,31 @@ func WithExpired(expired time.Duration) Option {
 		o.expired = expired
 	}
 }
+
+// WithRefreshExpired set the refresh token expiration time (default 7 days).
+func WithRefreshExpired(expired time.Duration) Option {
+	return func(o *options) {
+		o.refreshExpired = expired
+	}
+}
+
+// WithKeySet signs with the current key of ks and its "kid" header,
+// and verifies with the key of the "kid" header.
+func WithKeySet(ks *KeySet) Option {
+	return func(o *options) {
+		o.keySet = ks
+		o.keyfunc = ks.Keyfunc
+		o.keyAlg = true
+		o.verifyOnly = false
+	}
+}
+
+// WithRemoteKeySet verifies with the JWKS published by another service, the tokens can't be signed.
+func WithRemoteKeySet(r *RemoteKeySet) Option {
+	return func(o *options) {
+		o.keySet = nil
+		o.keyfunc = r.Keyfunc
+		o.keyAlg = true
+		o.verifyOnly = true
+	}
+}
@@ -0,0 +1,193 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// refreshTokenType is the "typ" header of the refresh tokens.
+const refreshTokenType = "refresh+jwt"
+
+// familyHeader is the header of the access tokens holding the family of their refresh token.
+const familyHeader = "fam"
+
+// keys of the revocation state in the Storer.
+func refreshKey(id string) string    { return "refresh:" + id }
+func familyKey(family string) string { return "family:" + family }
+func userKey(subject string) string  { return "user:" + subject }
+
+// TokenPair is an access token and the refresh token issuing the next pair.
+type TokenPair struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	// Token type of the access token.
+	Type string `json:"type"`
+	// Access token expiration time
+	ExpiresAt int64 `json:"expiresAt"`
+	// Refresh token expiration time
+	RefreshExpiresAt int64 `json:"refreshExpiresAt"`
+}
+
+func (p *TokenPair) EncodeToJSON() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// refreshClaims are the claims of a refresh token. The refresh tokens rotated
+// from the same login share a family, it is revoked as a whole.
+type refreshClaims struct {
+	Family string `json:"fam"`
+	jwt.RegisteredClaims
+}
+
+// SignPair issues an access token of the claims of WithClaims and a refresh token of a new family.
+func (j *JwtAuth[C]) SignPair(ctx context.Context) (*TokenPair, error) {
+	if j.claims == nil {
+		return nil, ErrClaimsMissing
+	}
+	return j.signPair(j.claims(), uuid.NewString())
+}
+
+// SignPairClaims issues an access token of the claims of a user and a refresh token of a new family.
+func (j *JwtAuth[C]) SignPairClaims(ctx context.Context, claims C) (*TokenPair, error) {
+	return j.signPair(claims, uuid.NewString())
+}
+
+// Refresh rotates refreshToken: it is used up and a new pair of the same family is issued,
+// the access token has the claims of WithClaims.
+// Using a rotated refresh token again revokes the family and returns ErrTokenReused,
+// the access tokens of the family are revoked too.
+// Without a Storer the refresh tokens are not rotated, and reuse is not detected.
+func (j *JwtAuth[C]) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	if j.claims == nil {
+		return nil, ErrClaimsMissing
+	}
+	return j.refresh(ctx, refreshToken, func(ctx context.Context, subject string) (jwt.Claims, error) {
+		return j.claims(), nil
+	})
+}
+
+// RefreshClaims rotates refreshToken as Refresh, the access token has the claims
+// load returns for the subject of refreshToken, so roles changed since the login apply.
+func (j *JwtAuth[C]) RefreshClaims(ctx context.Context, refreshToken string,
+	load func(ctx context.Context, subject string) (C, error)) (*TokenPair, error) {
+	return j.refresh(ctx, refreshToken, func(ctx context.Context, subject string) (jwt.Claims, error) {
+		return load(ctx, subject)
+	})
+}
+
+func (j *JwtAuth[C]) refresh(ctx context.Context, refreshToken string,
+	load func(ctx context.Context, subject string) (jwt.Claims, error)) (*TokenPair, error) {
+	claims, err := j.parseRefresh(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	// the refresh token is not used up if the claims can't be loaded
+	access, err := load(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	rotate := func(store Storer) error {
+		ok, err := useRefresh(ctx, store, claims.ID, time.Until(claims.ExpiresAt.Time))
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if err := store.Set(ctx, familyKey(claims.Family), "1", j.refreshExpired); err != nil {
+			return err
+		}
+		return ErrTokenReused
+	}
+	if err := j.callStore(rotate); err != nil {
+		return nil, err
+	}
+
+	return j.signPair(access, claims.Family)
+}
+
+// useRefresh marks the refresh token of id used, and reports whether it wasn't used before.
+// A plain Storer is checked before it is set, so concurrent uses may both succeed.
+func useRefresh(ctx context.Context, store Storer, id string, expiration time.Duration) (bool, error) {
+	if rs, ok := store.(RotatingStorer); ok {
+		return rs.SetNX(ctx, refreshKey(id), "1", expiration)
+	}
+	used, err := store.Check(ctx, refreshKey(id))
+	if err != nil || used {
+		return false, err
+	}
+	return true, store.Set(ctx, refreshKey(id), "1", expiration)
+}
+
+func (j *JwtAuth[C]) signPair(claims jwt.Claims, family string) (*TokenPair, error) {
+	now := time.Now()
+	accessToken, err := j.generate(claims, map[string]any{familyHeader: family})
+	if err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims.GetSubject()
+	refreshExpiresAt := now.Add(j.refreshExpired)
+	refreshToken, err := j.generate(&refreshClaims{
+		Family: family,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(refreshExpiresAt),
+		},
+	}, map[string]any{"typ": refreshTokenType})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		Type:             j.tokenType,
+		ExpiresAt:        j.expiresAt(claims, now).Unix(),
+		RefreshExpiresAt: refreshExpiresAt.Unix(),
+	}, nil
+}
+
+// parseRefresh verifies refreshToken, its family and its user are not revoked.
+func (j *JwtAuth[C]) parseRefresh(ctx context.Context, refreshToken string) (*refreshClaims, error) {
+	claims := new(refreshClaims)
+	token, err := jwt.ParseWithClaims(refreshToken, claims, j.keyfunc, jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return nil, ErrTokenInvalid
+	}
+	if typ, _ := token.Header["typ"].(string); typ != refreshTokenType || claims.Family == "" {
+		return nil, ErrTokenInvalid
+	}
+	if err := j.checkMethod(token); err != nil {
+		return nil, err
+	}
+
+	store := func(store Storer) error {
+		if err := checkFamily(ctx, store, claims.Family); err != nil {
+			return err
+		}
+		return checkUser(ctx, store, &claims.RegisteredClaims)
+	}
+	if err := j.callStore(store); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// isRefreshToken reports whether the "typ" header of token is the one of the refresh tokens,
+// the token is not verified.
+func isRefreshToken(token string) bool {
+	t, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return false
+	}
+	typ, _ := t.Header["typ"].(string)
+	return typ == refreshTokenType
+}
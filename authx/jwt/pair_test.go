@@ -0,0 +1,152 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/authx/jwt/store/memory"
+)
+
+var _ RotatingStorer = (*memory.Store)(nil)
+
+func newPairAuth() *JwtAuth[*jwt.RegisteredClaims] {
+	return NewJwtAuth[*jwt.RegisteredClaims](memory.NewStore(), WithClaims(func() jwt.Claims {
+		now := time.Now()
+		return &jwt.RegisteredClaims{
+			Subject:   "1",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		}
+	}))
+}
+
+func TestJwtAuth_Refresh(t *testing.T) {
+	ctx := context.Background()
+	j := newPairAuth()
+
+	pair, err := j.SignPair(ctx)
+	require.NoError(t, err)
+	claims, err := j.ParseClaims(ctx, pair.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "1", claims.Subject)
+
+	// refresh token 不能作为 access token 使用, 反之亦然
+	_, err = j.ParseClaims(ctx, pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrTokenInvalid)
+	_, err = j.Refresh(ctx, pair.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenInvalid)
+
+	next, err := j.Refresh(ctx, pair.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, pair.RefreshToken, next.RefreshToken)
+
+	// 重复使用已轮换的 refresh token, 整个 family 被吊销, 包括已签发的 access token
+	_, err = j.Refresh(ctx, pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrTokenReused)
+	_, err = j.Refresh(ctx, next.RefreshToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	_, err = j.ParseClaims(ctx, next.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	// 其他登录不受影响
+	other, err := j.SignPair(ctx)
+	require.NoError(t, err)
+	_, err = j.Refresh(ctx, other.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestJwtAuth_Destroy(t *testing.T) {
+	ctx := context.Background()
+	j := newPairAuth()
+
+	pair, err := j.SignPair(ctx)
+	require.NoError(t, err)
+	require.NoError(t, j.Destroy(ctx, pair.AccessToken))
+	_, err = j.ParseClaims(ctx, pair.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	// 注销 refresh token 所在的 family
+	require.NoError(t, j.Destroy(ctx, pair.RefreshToken))
+	_, err = j.Refresh(ctx, pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestJwtAuth_DestroyAll(t *testing.T) {
+	ctx := context.Background()
+	j := newPairAuth()
+
+	pair, err := j.SignPair(ctx)
+	require.NoError(t, err)
+
+	// iat 的精度为秒
+	time.Sleep(time.Second)
+	require.NoError(t, j.DestroyAll(ctx, "1"))
+	_, err = j.ParseClaims(ctx, pair.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	_, err = j.Refresh(ctx, pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	// 之后的登录有效
+	pair, err = j.SignPair(ctx)
+	require.NoError(t, err)
+	_, err = j.ParseClaims(ctx, pair.AccessToken)
+	assert.NoError(t, err)
+	_, err = j.Refresh(ctx, pair.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestJwtAuth_DestroyAllWithoutIssuedAt(t *testing.T) {
+	ctx := context.Background()
+	// claims 没有 iat, 签发时补上
+	j := NewJwtAuth[*jwt.RegisteredClaims](memory.NewStore(), WithClaims(func() jwt.Claims {
+		return &jwt.RegisteredClaims{Subject: "1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+	}))
+
+	pair, err := j.SignPair(ctx)
+	require.NoError(t, err)
+	time.Sleep(time.Second)
+	require.NoError(t, j.DestroyAll(ctx, "1"))
+	_, err = j.ParseClaims(ctx, pair.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	// 重新登录
+	pair, err = j.SignPair(ctx)
+	require.NoError(t, err)
+	claims, err := j.ParseClaims(ctx, pair.AccessToken)
+	require.NoError(t, err)
+	assert.NotNil(t, claims.IssuedAt)
+
+	mc := jwt.MapClaims{"sub": "1"}
+	token, err := NewJwtAuth[jwt.MapClaims](j.store).SignClaims(ctx, mc)
+	require.NoError(t, err)
+	assert.NotContains(t, mc, "iat", "the claims are not modified")
+	_, err = NewJwtAuth[jwt.MapClaims](j.store).ParseClaims(ctx, token.GetToken())
+	assert.NoError(t, err)
+}
+
+// plainStorer 只实现 Storer
+type plainStorer struct {
+	Storer
+}
+
+func TestJwtAuth_PlainStorer(t *testing.T) {
+	ctx := context.Background()
+	j := newPairAuth()
+	j.store = plainStorer{Storer: memory.NewStore()}
+
+	pair, err := j.SignPair(ctx)
+	require.NoError(t, err)
+	next, err := j.Refresh(ctx, pair.RefreshToken)
+	require.NoError(t, err)
+	_, err = j.Refresh(ctx, pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrTokenReused)
+	_, err = j.ParseClaims(ctx, next.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	assert.ErrorIs(t, j.DestroyAll(ctx, "1"), ErrStorerUnsupported)
+}
@@ -6,13 +6,28 @@ import (
 )
 
 // Storer token storage interface.
+// It holds the blacklisted access tokens and the revoked token families.
 type Storer interface {
 	// Set Store token data and specify expiration time.
-	Set(ctx context.Context, accessToken string, val any, expiration time.Duration) error
+	Set(ctx context.Context, key string, val any, expiration time.Duration) error
 
 	// Delete token data from storage.
-	Delete(ctx context.Context, accessToken string) (bool, error)
+	Delete(ctx context.Context, key string) (bool, error)
 
 	// Check if token exists.
-	Check(ctx context.Context, accessToken string) (bool, error)
+	Check(ctx context.Context, key string) (bool, error)
+}
+
+// RotatingStorer is a Storer rotating the refresh tokens atomically and holding the
+// "logout everywhere" time of the users. The stores of the store packages implement it.
+// With a plain Storer a refresh token reused concurrently may be rotated twice,
+// and DestroyAll returns ErrStorerUnsupported.
+type RotatingStorer interface {
+	Storer
+
+	// SetNX Store token data only if the key does not exist, and report whether it was stored.
+	SetNX(ctx context.Context, key string, val any, expiration time.Duration) (bool, error)
+
+	// Get token data, an empty string if the key does not exist.
+	Get(ctx context.Context, key string) (string, error)
 }
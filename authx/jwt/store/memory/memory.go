@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type item struct {
+	val    string
+	expire time.Time
+}
+
+func (i item) expired(now time.Time) bool {
+	return !i.expire.IsZero() && !now.Before(i.expire)
+}
+
+// Store in-memory storage for a single instance, expired keys are removed lazily.
+type Store struct {
+	mu    sync.Mutex
+	items map[string]item
+	// the number of writes since the last cleanup
+	writes int
+}
+
+// NewStore create an *Store instance to handle token storage, deletion, and checking.
+func NewStore() *Store {
+	return &Store{items: make(map[string]item)}
+}
+
+// Set a key-value pair with an expiration time, 0 means no expiration.
+func (s *Store) Set(ctx context.Context, key string, val any, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(key, val, expiration)
+	return nil
+}
+
+// SetNX set a key-value pair with an expiration time only if the key does not exist.
+func (s *Store) SetNX(ctx context.Context, key string, val any, expiration time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i, ok := s.items[key]; ok && !i.expired(time.Now()) {
+		return false, nil
+	}
+	s.set(key, val, expiration)
+	return true, nil
+}
+
+// Get the value of the key, an empty string if the key does not exist.
+func (s *Store) Get(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.items[key]
+	if !ok || i.expired(time.Now()) {
+		return "", nil
+	}
+	return i.val, nil
+}
+
+// Delete the key.
+func (s *Store) Delete(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.items[key]
+	delete(s.items, key)
+	return ok && !i.expired(time.Now()), nil
+}
+
+// Check if the key exists.
+func (s *Store) Check(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.items[key]
+	return ok && !i.expired(time.Now()), nil
+}
+
+func (s *Store) set(key string, val any, expiration time.Duration) {
+	now := time.Now()
+	i := item{val: fmt.Sprint(val)}
+	if expiration > 0 {
+		i.expire = now.Add(expiration)
+	}
+	s.items[key] = i
+
+	// remove the expired keys every 1024 writes
+	s.writes++
+	if s.writes < 1024 {
+		return
+	}
+	s.writes = 0
+	for k, i := range s.items {
+		if i.expired(now) {
+			delete(s.items, k)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	ok, err := s.SetNX(ctx, "a", 1, 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.SetNX(ctx, "a", 2, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	val, err := s.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", val)
+
+	time.Sleep(60 * time.Millisecond)
+	exists, err := s.Check(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	ok, err = s.SetNX(ctx, "a", 2, 0)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	deleted, err := s.Delete(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	val, err = s.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Empty(t, val)
+}
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -27,6 +28,21 @@ func (s *Store) Set(ctx context.Context, accessToken string, val any, expiration
 	return cmd.Err()
 }
 
+// SetNX call the Redis client to set a key-value pair with an
+// expiration time only if the key does not exist.
+func (s *Store) SetNX(ctx context.Context, key string, val any, expiration time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, s.key(key), val, expiration).Result()
+}
+
+// Get get the value of the key in Redis, an empty string if the key does not exist.
+func (s *Store) Get(ctx context.Context, key string) (string, error) {
+	val, err := s.client.Get(ctx, s.key(key)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return val, err
+}
+
 // Delete delete the specified JWT Token in Redis.
 func (s *Store) Delete(ctx context.Context, accessToken string) (bool, error) {
 	cmd := s.client.Del(ctx, s.key(accessToken))
@@ -38,8 +54,6 @@ func (s *Store) Delete(ctx context.Context, accessToken string) (bool, error) {
 
 // Check check if the specified JWT Token exists in Redis.
 func (s *Store) Check(ctx context.Context, accessToken string) (bool, error) {
-	s.client.Get(ctx, s.key(accessToken))
-
 	cmd := s.client.Exists(ctx, s.key(accessToken))
 	if err := cmd.Err(); err != nil {
 		return false, err
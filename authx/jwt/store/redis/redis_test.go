@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/apus-run/van/cache/mocks"
+)
+
+func TestStore_SetNX(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cmd := mocks.NewMockCmdable(ctrl)
+	res := redis.NewBoolCmd(context.Background())
+	res.SetVal(false)
+	cmd.EXPECT().SetNX(gomock.Any(), "authx:refresh:1", "1", time.Minute).Return(res)
+
+	ok, err := NewStore(cmd, "authx:").SetNX(context.Background(), "refresh:1", "1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestStore_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cmd := mocks.NewMockCmdable(ctrl)
+	missing := redis.NewStringCmd(context.Background())
+	missing.SetErr(redis.Nil)
+	cmd.EXPECT().Get(gomock.Any(), "authx:user:1").Return(missing)
+	found := redis.NewStringCmd(context.Background())
+	found.SetVal("1700000000")
+	cmd.EXPECT().Get(gomock.Any(), "authx:user:2").Return(found)
+
+	s := NewStore(cmd, "authx:")
+	val, err := s.Get(context.Background(), "user:1")
+	require.NoError(t, err)
+	assert.Empty(t, val)
+	val, err = s.Get(context.Background(), "user:2")
+	require.NoError(t, err)
+	assert.Equal(t, "1700000000", val)
+}
@@ -0,0 +1,62 @@
+package signature
+
+import (
+	"net/textproto"
+	"time"
+)
+
+// Option is signer and verifier option.
+type Option func(*Options)
+
+type Options struct {
+	// SignedHeaders are signed besides the host, the timestamp and the nonce.
+	SignedHeaders []string
+	// MaxSkew is the maximum difference between the timestamp of a request and the time
+	// of the verifier, the nonces are remembered for twice as long.
+	MaxSkew time.Duration
+	// MaxBodySize is the maximum size of the bodies read by the verifier.
+	MaxBodySize int64
+}
+
+// DefaultOptions .
+func DefaultOptions() *Options {
+	return &Options{
+		SignedHeaders: []string{"Content-Type"},
+		MaxSkew:       5 * time.Minute,
+		MaxBodySize:   10 << 20,
+	}
+}
+
+func Apply(opts ...Option) *Options {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithSignedHeaders set the headers signed besides the host, the timestamp and the nonce,
+// "Content-Type" by default. The signer and the verifier must use the same headers.
+func WithSignedHeaders(headers ...string) Option {
+	return func(o *Options) {
+		o.SignedHeaders = make([]string, 0, len(headers))
+		for _, h := range headers {
+			o.SignedHeaders = append(o.SignedHeaders, textproto.CanonicalMIMEHeaderKey(h))
+		}
+	}
+}
+
+// WithMaxSkew set the maximum difference between the timestamp of a request and the
+// time of the verifier (default 5m).
+func WithMaxSkew(skew time.Duration) Option {
+	return func(o *Options) {
+		o.MaxSkew = skew
+	}
+}
+
+// WithMaxBodySize set the maximum size of the bodies read by the verifier (default 10MiB).
+func WithMaxBodySize(size int64) Option {
+	return func(o *Options) {
+		o.MaxBodySize = size
+	}
+}
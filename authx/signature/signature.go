@@ -0,0 +1,149 @@
+// Package signature authenticates machine-to-machine requests signed with a shared
+// secret by HMAC-SHA256. The signature covers a canonical form of the request,
+// including its body, a timestamp and a nonce, so that a request can neither be
+// altered nor replayed:
+//
+//	Authorization: HMAC-SHA256 Credential=<key id>, SignedHeaders=<h1;h2>, Signature=<hex>
+//	X-Van-Timestamp: <unix seconds>
+//	X-Van-Nonce: <random>
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	// Algorithm is the scheme of the Authorization header.
+	Algorithm = "HMAC-SHA256"
+
+	HeaderTimestamp = "X-Van-Timestamp"
+	HeaderNonce     = "X-Van-Nonce"
+)
+
+var (
+	ErrSignatureMissing = errors.New("signature: missing signature")
+	ErrSignatureInvalid = errors.New("signature: invalid signature")
+	// ErrUnknownKey is returned by a SecretFunc for an unknown key id.
+	ErrUnknownKey     = fmt.Errorf("%w: unknown key", ErrSignatureInvalid)
+	ErrRequestExpired = fmt.Errorf("%w: timestamp out of range", ErrSignatureInvalid)
+	ErrNonceReused    = fmt.Errorf("%w: nonce reused", ErrSignatureInvalid)
+	ErrBodyTooLarge   = fmt.Errorf("%w: body too large", ErrSignatureInvalid)
+)
+
+// signedHeaders returns the lowercase names of the signed headers, sorted.
+func signedHeaders(extra []string) []string {
+	headers := []string{"host", strings.ToLower(HeaderNonce), strings.ToLower(HeaderTimestamp)}
+	for _, h := range extra {
+		if h = strings.ToLower(h); !slices.Contains(headers, h) {
+			headers = append(headers, h)
+		}
+	}
+	slices.Sort(headers)
+	return headers
+}
+
+// canonicalRequest returns the canonical form of req:
+//
+//	METHOD
+//	escaped path
+//	sorted query
+//	name:value of each signed header
+//	signed header names joined by ";"
+//	hex SHA-256 of the body
+func canonicalRequest(req *http.Request, headers []string, body []byte) string {
+	var b strings.Builder
+	b.WriteString(req.Method)
+	b.WriteByte('\n')
+	b.WriteString(canonicalPath(req.URL))
+	b.WriteByte('\n')
+	b.WriteString(canonicalQuery(req.URL.Query()))
+	b.WriteByte('\n')
+	for _, h := range headers {
+		b.WriteString(h)
+		b.WriteByte(':')
+		b.WriteString(headerValue(req, h))
+		b.WriteByte('\n')
+	}
+	b.WriteString(strings.Join(headers, ";"))
+	b.WriteByte('\n')
+	sum := sha256.Sum256(body)
+	b.WriteString(hex.EncodeToString(sum[:]))
+	return b.String()
+}
+
+func canonicalPath(u *url.URL) string {
+	if p := u.EscapedPath(); p != "" {
+		return p
+	}
+	return "/"
+}
+
+// canonicalQuery is the query sorted by key and value, url.Values.Encode sorts by key.
+func canonicalQuery(query url.Values) string {
+	for _, values := range query {
+		slices.Sort(values)
+	}
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func headerValue(req *http.Request, name string) string {
+	if name == "host" {
+		if req.Host != "" {
+			return req.Host
+		}
+		return req.URL.Host
+	}
+	// Values returns the slice of the header, it is copied to leave the header unchanged
+	values := slices.Clone(req.Header.Values(name))
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+	return strings.Join(values, ",")
+}
+
+// sign returns the hex HMAC-SHA256 of the canonical request.
+func sign(secret []byte, canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(Algorithm + "\n" + hex.EncodeToString(sum[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func authorization(keyID string, headers []string, signature string) string {
+	return fmt.Sprintf("%s Credential=%s, SignedHeaders=%s, Signature=%s",
+		Algorithm, keyID, strings.Join(headers, ";"), signature)
+}
+
+// parseAuthorization parses the Authorization header of a signed request.
+func parseAuthorization(header string) (keyID string, headers []string, signature string, err error) {
+	params, ok := strings.CutPrefix(header, Algorithm+" ")
+	if !ok {
+		return "", nil, "", ErrSignatureMissing
+	}
+	for _, param := range strings.Split(params, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok {
+			return "", nil, "", ErrSignatureInvalid
+		}
+		switch k {
+		case "Credential":
+			keyID = v
+		case "SignedHeaders":
+			headers = strings.Split(v, ";")
+		case "Signature":
+			signature = v
+		}
+	}
+	if keyID == "" || len(headers) == 0 || signature == "" {
+		return "", nil, "", ErrSignatureInvalid
+	}
+	return keyID, headers, signature, nil
+}
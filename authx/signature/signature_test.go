@@ -0,0 +1,172 @@
+package signature
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/cache"
+	"github.com/apus-run/van/cache/memory"
+)
+
+var secrets = func(ctx context.Context, keyID string) ([]byte, error) {
+	if keyID != "billing" {
+		return nil, ErrUnknownKey
+	}
+	return []byte("secret"), nil
+}
+
+func TestTransport(t *testing.T) {
+	v := NewVerifier(secrets, memory.New())
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyID, err := v.Verify(r)
+		if verifyErr = err; err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = io.WriteString(w, keyID+":"+string(body))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(NewSigner("billing", []byte("secret")), nil)}
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/orders?b=2&a=1&a=0", strings.NewReader(`{"id":1}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	require.NoError(t, verifyErr)
+	assert.Equal(t, `billing:{"id":1}`, string(body))
+	assert.Empty(t, req.Header.Get("Authorization"), "the transport doesn't modify the request")
+
+	other := &http.Client{Transport: NewTransport(NewSigner("billing", []byte("other")), nil)}
+	resp, err = other.Get(server.URL + "/orders")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.ErrorIs(t, verifyErr, ErrSignatureInvalid)
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	now := time.Now()
+	signed := func(t *testing.T, keyID, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "http://example.com/orders/1", strings.NewReader(body))
+		s := NewSigner(keyID, []byte("secret"))
+		s.now = func() time.Time { return now }
+		require.NoError(t, s.Sign(req))
+		return req
+	}
+
+	testCases := []struct {
+		name    string
+		req     func(t *testing.T) *http.Request
+		skew    time.Duration
+		wantErr error
+	}{
+		{
+			name: "valid",
+			req:  func(t *testing.T) *http.Request { return signed(t, "billing", "body") },
+		},
+		{
+			name:    "missing",
+			req:     func(t *testing.T) *http.Request { return httptest.NewRequest(http.MethodGet, "/orders", nil) },
+			wantErr: ErrSignatureMissing,
+		},
+		{
+			name:    "unknown key",
+			req:     func(t *testing.T) *http.Request { return signed(t, "unknown", "body") },
+			wantErr: ErrUnknownKey,
+		},
+		{
+			name: "tampered body",
+			req: func(t *testing.T) *http.Request {
+				req := signed(t, "billing", "body")
+				req.Body = io.NopCloser(strings.NewReader("tampered"))
+				return req
+			},
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name: "tampered path",
+			req: func(t *testing.T) *http.Request {
+				req := signed(t, "billing", "body")
+				req.URL.Path = "/orders/2"
+				return req
+			},
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name:    "expired",
+			req:     func(t *testing.T) *http.Request { return signed(t, "billing", "body") },
+			skew:    10 * time.Minute,
+			wantErr: ErrRequestExpired,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := NewVerifier(secrets, memory.New())
+			v.now = func() time.Time { return now.Add(tc.skew) }
+			keyID, err := v.Verify(tc.req(t))
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "billing", keyID)
+		})
+	}
+}
+
+// plainStorage 不实现 SetNXer
+type plainStorage struct {
+	cache.Storage
+}
+
+func TestVerifier_Replay(t *testing.T) {
+	for name, nonces := range map[string]cache.Storage{
+		"SetNX": memory.New(),
+		"plain": plainStorage{Storage: memory.New()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			v := NewVerifier(secrets, nonces)
+			req := httptest.NewRequest(http.MethodPost, "http://example.com/orders", strings.NewReader("body"))
+			require.NoError(t, NewSigner("billing", []byte("secret")).Sign(req))
+			replay := req.Clone(context.Background())
+			replay.Body = io.NopCloser(strings.NewReader("body"))
+
+			_, err := v.Verify(req)
+			require.NoError(t, err)
+			_, err = v.Verify(replay)
+			assert.ErrorIs(t, err, ErrNonceReused)
+		})
+	}
+}
+
+func TestVerifier_SignedHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/orders", nil)
+	req.Header.Set("X-Tenant", "t1")
+	req.Header.Add("Content-Type", " text/plain ")
+	require.NoError(t, NewSigner("billing", []byte("secret"), WithSignedHeaders("x-tenant", "content-type")).Sign(req))
+	assert.Equal(t, " text/plain ", req.Header.Get("Content-Type"), "signing leaves the headers unchanged")
+
+	_, err := NewVerifier(secrets, memory.New()).Verify(req.Clone(context.Background()))
+	assert.ErrorIs(t, err, ErrSignatureInvalid)
+
+	v := NewVerifier(secrets, memory.New(), WithSignedHeaders("X-Tenant", "Content-Type"))
+	tampered := req.Clone(context.Background())
+	tampered.Header.Set("X-Tenant", "t2")
+	_, err = v.Verify(tampered)
+	assert.ErrorIs(t, err, ErrSignatureInvalid)
+	_, err = v.Verify(req)
+	assert.NoError(t, err)
+}
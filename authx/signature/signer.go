@@ -0,0 +1,100 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Signer signs the outgoing requests with the secret of a key id.
+type Signer struct {
+	keyID   string
+	secret  []byte
+	headers []string
+	now     func() time.Time
+}
+
+func NewSigner(keyID string, secret []byte, opts ...Option) *Signer {
+	options := Apply(opts...)
+	return &Signer{
+		keyID:   keyID,
+		secret:  secret,
+		headers: signedHeaders(options.SignedHeaders),
+		now:     time.Now,
+	}
+}
+
+// Sign sets the timestamp, the nonce and the Authorization headers of req.
+// The body is read and replaced, unless req.GetBody returns a copy of it.
+func (s *Signer) Sign(req *http.Request) error {
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(s.now().Unix(), 10))
+	req.Header.Set(HeaderNonce, hex.EncodeToString(nonce))
+	signature := sign(s.secret, canonicalRequest(req, s.headers, body))
+	req.Header.Set("Authorization", authorization(s.keyID, s.headers, signature))
+	return nil
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return data, nil
+}
+
+// Transport is a http.RoundTripper signing the requests with Signer.
+type Transport struct {
+	Signer *Signer
+	// Base sends the signed requests, http.DefaultTransport if nil.
+	Base http.RoundTripper
+}
+
+// NewTransport returns a transport signing the requests with s before sending them with base.
+func NewTransport(s *Signer, base http.RoundTripper) *Transport {
+	return &Transport{Signer: s, Base: base}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// a RoundTripper must not modify the request
+	req = req.Clone(req.Context())
+	if err := t.Signer.Sign(req); err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
@@ -0,0 +1,139 @@
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apus-run/van/cache"
+)
+
+// SecretFunc returns the secret of keyID, ErrUnknownKey if there is none.
+type SecretFunc func(ctx context.Context, keyID string) ([]byte, error)
+
+// SetNXer is implemented by the nonce storages setting a key atomically only if it
+// doesn't exist, such as the memory and redis storages of the cache package.
+type SetNXer interface {
+	SetNX(ctx context.Context, key string, val any, exp time.Duration) (bool, error)
+}
+
+// Verifier verifies the signed requests.
+type Verifier struct {
+	secrets SecretFunc
+	// nonces of the requests verified within the skew
+	nonces  cache.Storage
+	headers []string
+	options *Options
+	now     func() time.Time
+	// serializes checking and storing the nonces of a storage not implementing SetNXer
+	mu sync.Mutex
+}
+
+// NewVerifier returns a verifier checking the signatures with the secrets, and rejecting
+// the nonces seen in nonces. A nonce is stored atomically if nonces implements SetNXer,
+// so that the instances sharing a redis storage accept it once. Otherwise it is checked
+// and stored under a lock of the process only: a multi-instance deployment can then
+// accept a replayed nonce once per instance.
+func NewVerifier(secrets SecretFunc, nonces cache.Storage, opts ...Option) *Verifier {
+	options := Apply(opts...)
+	return &Verifier{
+		secrets: secrets,
+		nonces:  nonces,
+		headers: signedHeaders(options.SignedHeaders),
+		options: options,
+		now:     time.Now,
+	}
+}
+
+// Verify verifies the signature of req and returns its key id. The errors of a request
+// without a valid signature wrap ErrSignatureMissing or ErrSignatureInvalid, the other
+// errors are the ones of the secrets and the nonce storage.
+// The body of req is read and replaced.
+func (v *Verifier) Verify(req *http.Request) (string, error) {
+	header := req.Header.Get("Authorization")
+	if header == "" {
+		return "", ErrSignatureMissing
+	}
+	keyID, headers, signature, err := parseAuthorization(header)
+	if err != nil {
+		return "", err
+	}
+	// the required headers must be signed, a request can't drop some of them
+	if !slices.Equal(headers, v.headers) {
+		return "", ErrSignatureInvalid
+	}
+
+	ts, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return "", ErrSignatureInvalid
+	}
+	if skew := v.now().Sub(time.Unix(ts, 0)).Abs(); skew > v.options.MaxSkew {
+		return "", ErrRequestExpired
+	}
+	nonce := req.Header.Get(HeaderNonce)
+	if len(nonce) < 16 || len(nonce) > 128 {
+		return "", ErrSignatureInvalid
+	}
+
+	body, err := v.readBody(req)
+	if err != nil {
+		return "", err
+	}
+	secret, err := v.secrets(req.Context(), keyID)
+	if err != nil {
+		return "", err
+	}
+	expected := sign(secret, canonicalRequest(req, v.headers, body))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return "", ErrSignatureInvalid
+	}
+
+	// only the nonces of valid signatures are stored, so that they can't be burned by forged requests
+	if err := v.useNonce(req.Context(), keyID, nonce); err != nil {
+		return "", err
+	}
+	return keyID, nil
+}
+
+func (v *Verifier) useNonce(ctx context.Context, keyID, nonce string) error {
+	key := "nonce:" + keyID + ":" + nonce
+	if s, ok := v.nonces.(SetNXer); ok {
+		set, err := s.SetNX(ctx, key, "1", 2*v.options.MaxSkew)
+		if err != nil {
+			return err
+		}
+		if !set {
+			return ErrNonceReused
+		}
+		return nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.nonces.Contains(ctx, key) {
+		return ErrNonceReused
+	}
+	return v.nonces.Set(ctx, key, "1", 2*v.options.MaxSkew)
+}
+
+func (v *Verifier) readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(io.LimitReader(req.Body, v.options.MaxBodySize+1))
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > v.options.MaxBodySize {
+		return nil, ErrBodyTooLarge
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
@@ -18,16 +18,16 @@ type Token interface {
 	EncodeToJSON() ([]byte, error)
 }
 
-// Authenticator defines methods used for token processing.
-type Authenticator interface {
-	// Sign is used to generate a token.
-	Sign(ctx context.Context, userID string) (Token, error)
+// Authenticator defines methods used for token processing, C is the type of the claims.
+type Authenticator[C jwt.Claims] interface {
+	// SignClaims is used to generate a token of the claims of a user.
+	SignClaims(ctx context.Context, claims C) (Token, error)
 
 	// Destroy is used to destroy a token.
 	Destroy(ctx context.Context, accessToken string) error
 
 	// ParseClaims parse the token and return the claims.
-	ParseClaims(ctx context.Context, accessToken string) (*jwt.RegisteredClaims, error)
+	ParseClaims(ctx context.Context, accessToken string) (C, error)
 
 	// ParseToken is used to parse a token.
 	ParseToken(ctx context.Context, accessToken string) (*jwt.Token, error)
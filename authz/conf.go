@@ -0,0 +1,32 @@
+package authz
+
+import (
+	"log/slog"
+
+	"github.com/apus-run/van/conf"
+)
+
+// Bind returns an engine enforcing the policy of the loaded file, at key of the file
+// or the whole file if key is empty. The policy is updated when the file changes,
+// an invalid policy is logged and the current one kept.
+func Bind(c *conf.Config, filename, key string, opts ...Option) (*Engine, error) {
+	var bindOpts []conf.BindOption
+	if key != "" {
+		bindOpts = append(bindOpts, conf.WithKey(key))
+	}
+	v, err := conf.Bind[Policy](c, filename, bindOpts...)
+	if err != nil {
+		return nil, err
+	}
+	policy := v.Get()
+	e, err := New(&policy, opts...)
+	if err != nil {
+		return nil, err
+	}
+	v.Subscribe(func(change conf.Change[Policy]) {
+		if err := e.Update(&change.New); err != nil {
+			slog.Error("update authz policy", slog.String("file", filename), slog.Any("err", err))
+		}
+	})
+	return e, nil
+}
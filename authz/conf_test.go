@@ -0,0 +1,60 @@
+package authz_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/authz"
+	"github.com/apus-run/van/conf"
+	"github.com/apus-run/van/conf/file"
+)
+
+type claims struct {
+	Roles []string `json:"roles"`
+	jwt.RegisteredClaims
+}
+
+func TestBind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	write := func(s string) {
+		require.NoError(t, os.WriteFile(path, []byte(s), 0o644))
+	}
+	write(`
+authz:
+  roles:
+    - name: viewer
+      permissions:
+        - actions: [GET]
+          resources: [/orders/**]
+`)
+
+	c := conf.New([]conf.Source{file.NewSource(path)})
+	require.NoError(t, c.Load())
+	e, err := authz.Bind(c, "app", "authz")
+	require.NoError(t, err)
+
+	allowed := func(action string) bool {
+		req, err := e.Request(&claims{Roles: []string{"viewer"}}, action, "/orders/1")
+		require.NoError(t, err)
+		return e.Authorize(context.Background(), req).Allowed
+	}
+	assert.True(t, allowed("GET"))
+	assert.False(t, allowed("POST"))
+
+	write(`
+authz:
+  roles:
+    - name: viewer
+      permissions:
+        - actions: [GET, POST]
+          resources: [/orders/**]
+`)
+	require.Eventually(t, func() bool { return allowed("POST") }, 5*time.Second, 10*time.Millisecond)
+}
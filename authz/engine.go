@@ -0,0 +1,233 @@
+// Package authz authorizes the requests of authenticated subjects with a policy
+// of roles (RBAC), whose permissions hold under conditions on the claims and the
+// request attributes (ABAC).
+package authz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Request is an authorization request.
+type Request struct {
+	Subject  string
+	Roles    []string
+	Action   string
+	Resource string
+	// Attrs are the attributes the conditions refer to by their dot path,
+	// usually "claims" and "request".
+	Attrs map[string]any
+}
+
+// Decision is the result of an authorization request.
+type Decision struct {
+	Allowed bool
+	// DryRun is set when the decision is not enforced.
+	DryRun   bool
+	Subject  string
+	Action   string
+	Resource string
+	// Role is the role of the deciding permission, empty if no permission matched.
+	Role   string
+	Reason string
+}
+
+// Permitted reports whether the request may proceed: it is allowed, or the decision is a dry run.
+func (d Decision) Permitted() bool {
+	return d.Allowed || d.DryRun
+}
+
+// Engine evaluates the requests against a policy, the policy can be updated concurrently.
+type Engine struct {
+	options *Options
+	policy  atomic.Pointer[compiled]
+}
+
+func New(policy *Policy, opts ...Option) (*Engine, error) {
+	e := &Engine{options: Apply(opts...)}
+	if err := e.Update(policy); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Update replaces the policy, an invalid policy is rejected and the current one kept.
+func (e *Engine) Update(policy *Policy) error {
+	c, err := compile(policy)
+	if err != nil {
+		return err
+	}
+	e.policy.Store(c)
+	return nil
+}
+
+// Authorize decides req, denying it unless an allow permission of its roles matches
+// and no deny permission does.
+func (e *Engine) Authorize(ctx context.Context, req *Request) Decision {
+	d := e.decide(req)
+	d.DryRun = e.options.DryRun
+	if e.options.DecisionLog != nil {
+		e.options.DecisionLog(ctx, d)
+	}
+	return d
+}
+
+func (e *Engine) decide(req *Request) Decision {
+	d := Decision{
+		Subject:  req.Subject,
+		Action:   req.Action,
+		Resource: req.Resource,
+		Reason:   "no permission matched",
+	}
+	c := e.policy.Load()
+	roles := append(slices.Clone(req.Roles), AnyRole)
+	for _, role := range roles {
+		for _, p := range c.roles[role] {
+			if !p.matches(req.Action, req.Resource) || !conditionsHold(p.Conditions, req.Attrs) {
+				continue
+			}
+			if p.Effect == Deny {
+				d.Allowed = false
+				d.Role = role
+				d.Reason = "denied by role " + role
+				return d
+			}
+			if !d.Allowed {
+				d.Allowed = true
+				d.Role = role
+				d.Reason = "allowed by role " + role
+			}
+		}
+	}
+	return d
+}
+
+// Request returns the request of the subject of claims, its roles are the roles claim,
+// and the claims are the "claims" attribute.
+func (e *Engine) Request(claims jwt.Claims, action, resource string) (*Request, error) {
+	attrs, err := claimsAttrs(claims)
+	if err != nil {
+		return nil, err
+	}
+	subject, _ := claims.GetSubject()
+	return &Request{
+		Subject:  subject,
+		Roles:    stringsOf(attrs[e.options.RolesClaim]),
+		Action:   action,
+		Resource: resource,
+		Attrs:    map[string]any{"claims": attrs},
+	}, nil
+}
+
+// claimsAttrs returns the JSON fields of claims.
+func claimsAttrs(claims jwt.Claims) (map[string]any, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// keep the precision of large integers
+	dec.UseNumber()
+	attrs := make(map[string]any)
+	if err := dec.Decode(&attrs); err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+// stringsOf returns the roles of a claim, a string or a list of strings.
+func stringsOf(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		s := make([]string, 0, len(v))
+		for _, e := range v {
+			s = append(s, fmt.Sprint(e))
+		}
+		return s
+	}
+	return nil
+}
+
+func conditionsHold(conds []Condition, attrs map[string]any) bool {
+	for _, c := range conds {
+		if !c.holds(attrs) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Condition) holds(attrs map[string]any) bool {
+	v, ok := lookup(attrs, c.Attr)
+	if c.Op == OpExists {
+		return ok
+	}
+	if !ok {
+		// a missing attribute is not equal to any value
+		return c.Op == OpNe || c.Op == OpNotIn
+	}
+	values := make([]string, 0, len(c.Values))
+	for _, val := range c.Values {
+		if ref, isRef := strings.CutPrefix(val, "${"); isRef && strings.HasSuffix(ref, "}") {
+			rv, ok := lookup(attrs, strings.TrimSuffix(ref, "}"))
+			if !ok {
+				// a missing referenced attribute equals no attribute
+				continue
+			}
+			val = fmt.Sprint(rv)
+		}
+		values = append(values, val)
+	}
+
+	s := fmt.Sprint(v)
+	switch c.Op {
+	case OpEq:
+		return len(values) > 0 && s == values[0]
+	case OpNe:
+		return len(values) == 0 || s != values[0]
+	case OpIn:
+		return slices.Contains(values, s)
+	case OpNotIn:
+		return !slices.Contains(values, s)
+	case OpPrefix:
+		return len(values) > 0 && strings.HasPrefix(s, values[0])
+	case OpContains:
+		return len(values) > 0 && slices.Contains(stringsOf(v), values[0])
+	}
+	return false
+}
+
+// lookup returns the attribute of the dot path.
+func lookup(attrs map[string]any, path string) (any, bool) {
+	var cur any = attrs
+	for _, key := range strings.Split(path, ".") {
+		switch m := cur.(type) {
+		case map[string]any:
+			v, ok := m[key]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case map[string]string:
+			v, ok := m[key]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
@@ -0,0 +1,134 @@
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type claims struct {
+	Roles  []string `json:"roles"`
+	Tenant string   `json:"tenant"`
+	UserID uint64   `json:"uid"`
+	jwt.RegisteredClaims
+}
+
+func newPolicy() *Policy {
+	return &Policy{Roles: []Role{
+		{
+			Name: "viewer",
+			Permissions: []Permission{{
+				Effect:    Allow,
+				Actions:   []string{"GET"},
+				Resources: []string{"/tenants/*/orders/**"},
+				Conditions: []Condition{
+					{Attr: "request.param.tenant", Op: OpEq, Values: []string{"${claims.tenant}"}},
+				},
+			}},
+		},
+		{
+			Name:     "admin",
+			Inherits: []string{"viewer"},
+			Permissions: []Permission{
+				{Effect: Allow, Actions: []string{"*"}, Resources: []string{"/admin/**"}},
+				{Effect: Deny, Actions: []string{"DELETE"}, Resources: []string{"/admin/audit"}},
+			},
+		},
+		{
+			Name: AnyRole,
+			Permissions: []Permission{
+				{Effect: Allow, Actions: []string{"GET"}, Resources: []string{"/me"}},
+				{
+					Effect: Deny, Actions: []string{"*"}, Resources: []string{"*"},
+					Conditions: []Condition{{Attr: "claims.uid", Op: OpIn, Values: []string{"18446744073709551615"}}},
+				},
+			},
+		},
+	}}
+}
+
+func request(t *testing.T, e *Engine, c *claims, action, resource, tenant string) *Request {
+	req, err := e.Request(c, action, resource)
+	require.NoError(t, err)
+	req.Attrs["request"] = map[string]any{"param": map[string]string{"tenant": tenant}}
+	return req
+}
+
+func TestEngine_Authorize(t *testing.T) {
+	e, err := New(newPolicy())
+	require.NoError(t, err)
+
+	viewer := &claims{Roles: []string{"viewer"}, Tenant: "t1", RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}}
+	admin := &claims{Roles: []string{"admin"}, Tenant: "t2"}
+	banned := &claims{UserID: 18446744073709551615}
+
+	testCases := []struct {
+		name     string
+		claims   *claims
+		action   string
+		resource string
+		tenant   string
+		allowed  bool
+		role     string
+	}{
+		{name: "viewer reads its tenant", claims: viewer, action: "GET", resource: "/tenants/t1/orders/1", tenant: "t1", allowed: true, role: "viewer"},
+		{name: "viewer reads another tenant", claims: viewer, action: "GET", resource: "/tenants/t2/orders/1", tenant: "t2"},
+		{name: "viewer writes", claims: viewer, action: "POST", resource: "/tenants/t1/orders", tenant: "t1"},
+		{name: "admin inherits viewer", claims: admin, action: "get", resource: "/tenants/t2/orders", tenant: "t2", allowed: true, role: "admin"},
+		{name: "admin", claims: admin, action: "POST", resource: "/admin/users", allowed: true, role: "admin"},
+		{name: "deny overrides allow", claims: admin, action: "DELETE", resource: "/admin/audit", role: "admin"},
+		{name: "any role", claims: &claims{}, action: "GET", resource: "/me", allowed: true, role: AnyRole},
+		{name: "condition on claims", claims: banned, action: "GET", resource: "/me", role: AnyRole},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := e.Authorize(context.Background(), request(t, e, tc.claims, tc.action, tc.resource, tc.tenant))
+			assert.Equal(t, tc.allowed, d.Allowed, d.Reason)
+			assert.Equal(t, tc.allowed, d.Permitted())
+			assert.Equal(t, tc.role, d.Role)
+		})
+	}
+}
+
+func TestEngine_DryRun(t *testing.T) {
+	var logged []Decision
+	e, err := New(newPolicy(), WithDryRun(true), WithDecisionLog(func(ctx context.Context, d Decision) {
+		logged = append(logged, d)
+	}))
+	require.NoError(t, err)
+
+	d := e.Authorize(context.Background(), request(t, e, &claims{Roles: []string{"viewer"}}, "POST", "/admin/users", ""))
+	assert.False(t, d.Allowed)
+	assert.True(t, d.DryRun)
+	assert.True(t, d.Permitted())
+	require.Len(t, logged, 1)
+	assert.Equal(t, d, logged[0])
+}
+
+func TestEngine_Update(t *testing.T) {
+	e, err := New(newPolicy())
+	require.NoError(t, err)
+
+	invalid := []*Policy{
+		{Roles: []Role{{Name: "a", Inherits: []string{"b"}}, {Name: "b", Inherits: []string{"a"}}}},
+		{Roles: []Role{{Name: "a", Inherits: []string{"missing"}}}},
+		{Roles: []Role{{Name: "a"}, {Name: "a"}}},
+		{Roles: []Role{{Name: "a", Permissions: []Permission{{Effect: "maybe"}}}}},
+		{Roles: []Role{{Name: "a", Permissions: []Permission{{Effect: Allow, Resources: []string{"["}}}}}},
+		{Roles: []Role{{Name: "a", Permissions: []Permission{{Conditions: []Condition{{Attr: "x"}}}}}}},
+	}
+	for _, p := range invalid {
+		assert.ErrorIs(t, e.Update(p), ErrInvalidPolicy)
+	}
+
+	// an invalid policy doesn't replace the current one
+	d := e.Authorize(context.Background(), request(t, e, &claims{}, "GET", "/me", ""))
+	assert.True(t, d.Allowed)
+
+	require.NoError(t, e.Update(&Policy{}))
+	d = e.Authorize(context.Background(), request(t, e, &claims{}, "GET", "/me", ""))
+	assert.False(t, d.Allowed)
+}
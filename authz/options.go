@@ -0,0 +1,77 @@
+package authz
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Option is engine option.
+type Option func(*Options)
+
+type Options struct {
+	// DryRun evaluates and logs the decisions without enforcing them.
+	DryRun bool
+	// DecisionLog is called with every decision.
+	DecisionLog func(ctx context.Context, d Decision)
+	// RolesClaim is the claim of the roles of the subject.
+	RolesClaim string
+}
+
+// DefaultOptions .
+func DefaultOptions() *Options {
+	return &Options{
+		RolesClaim: "roles",
+	}
+}
+
+func Apply(opts ...Option) *Options {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithDryRun evaluates and logs the decisions without enforcing them, to roll out a policy safely.
+func WithDryRun(dryRun bool) Option {
+	return func(o *Options) {
+		o.DryRun = dryRun
+	}
+}
+
+// WithDecisionLog set the function called with every decision.
+func WithDecisionLog(fn func(ctx context.Context, d Decision)) Option {
+	return func(o *Options) {
+		o.DecisionLog = fn
+	}
+}
+
+// WithRolesClaim set the claim of the roles of the subject, "roles" by default.
+func WithRolesClaim(claim string) Option {
+	return func(o *Options) {
+		o.RolesClaim = claim
+	}
+}
+
+// LogDecisions returns a decision log writing to l: the denials at info level,
+// the denials of a dry run at warn level and the allows at debug level.
+func LogDecisions(l *slog.Logger) func(ctx context.Context, d Decision) {
+	return func(ctx context.Context, d Decision) {
+		level := slog.LevelDebug
+		switch {
+		case !d.Allowed && d.DryRun:
+			level = slog.LevelWarn
+		case !d.Allowed:
+			level = slog.LevelInfo
+		}
+		l.Log(ctx, level, "authz decision",
+			slog.Bool("allowed", d.Allowed),
+			slog.Bool("dry_run", d.DryRun),
+			slog.String("subject", d.Subject),
+			slog.String("action", d.Action),
+			slog.String("resource", d.Resource),
+			slog.String("role", d.Role),
+			slog.String("reason", d.Reason),
+		)
+	}
+}
@@ -0,0 +1,202 @@
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Effects of a permission.
+const (
+	Allow = "allow"
+	Deny  = "deny"
+)
+
+// AnyRole is the name of the role every subject has.
+const AnyRole = "*"
+
+// Operators of a condition.
+const (
+	OpEq       = "eq"
+	OpNe       = "ne"
+	OpIn       = "in"
+	OpNotIn    = "not_in"
+	OpPrefix   = "prefix"
+	OpContains = "contains"
+	OpExists   = "exists"
+)
+
+var ErrInvalidPolicy = errors.New("authz: invalid policy")
+
+// Policy is the authorization policy, it is usually loaded from the conf system:
+//
+//	roles:
+//	  - name: viewer
+//	    permissions:
+//	      - actions: [GET]
+//	        resources: [/orders/**]
+//	        conditions:
+//	          - attr: request.param.tenant
+//	            values: ["${claims.tenant}"]
+//	  - name: admin
+//	    inherits: [viewer]
+//	    permissions:
+//	      - actions: ["*"]
+//	        resources: ["/admin/**"]
+type Policy struct {
+	Roles []Role `mapstructure:"roles" validate:"dive"`
+}
+
+// Role is a named set of permissions, it has the permissions of the roles it inherits.
+type Role struct {
+	Name        string       `mapstructure:"name" validate:"required"`
+	Inherits    []string     `mapstructure:"inherits"`
+	Permissions []Permission `mapstructure:"permissions" validate:"dive"`
+}
+
+// Permission allows or denies the actions on the resources when all its conditions hold.
+// A deny overrides the allows.
+type Permission struct {
+	// Effect is allow, the default, or deny.
+	Effect string `mapstructure:"effect" validate:"omitempty,oneof=allow deny"`
+	// Actions such as the HTTP methods, "*" is any action.
+	Actions []string `mapstructure:"actions" validate:"required"`
+	// Resources are path.Match patterns of the resources, such as HTTP paths or gRPC
+	// methods. A pattern ending with "/**" matches the path and the paths below it, "*" any resource.
+	Resources  []string    `mapstructure:"resources" validate:"required"`
+	Conditions []Condition `mapstructure:"conditions" validate:"dive"`
+}
+
+// Condition compares an attribute of the request with values.
+type Condition struct {
+	// Attr is the dot path of the attribute, such as "claims.tenant" or "request.header.x-tenant".
+	Attr string `mapstructure:"attr" validate:"required"`
+	// Op is eq, the default, ne, in, not_in, prefix, contains or exists.
+	Op string `mapstructure:"op" validate:"omitempty,oneof=eq ne in not_in prefix contains exists"`
+	// Values compared with the attribute, "${path}" is the value of the attribute of path.
+	// eq, ne, prefix and contains use the first value.
+	Values []string `mapstructure:"values"`
+}
+
+// compiled is a policy with the inherited permissions resolved.
+type compiled struct {
+	roles map[string][]Permission
+}
+
+func compile(p *Policy) (*compiled, error) {
+	roles := make(map[string]*Role, len(p.Roles))
+	for i := range p.Roles {
+		r := p.Roles[i]
+		if _, ok := roles[r.Name]; ok {
+			return nil, fmt.Errorf("%w: duplicate role %q", ErrInvalidPolicy, r.Name)
+		}
+		perms := make([]Permission, len(r.Permissions))
+		for j, perm := range r.Permissions {
+			perm = withDefaults(perm)
+			if err := checkPermission(perm); err != nil {
+				return nil, fmt.Errorf("%w: role %q: %v", ErrInvalidPolicy, r.Name, err)
+			}
+			perms[j] = perm
+		}
+		roles[r.Name] = &Role{Name: r.Name, Inherits: r.Inherits, Permissions: perms}
+	}
+
+	c := &compiled{roles: make(map[string][]Permission, len(roles))}
+	var resolve func(name string, visiting []string) ([]Permission, error)
+	resolve = func(name string, visiting []string) ([]Permission, error) {
+		if perms, ok := c.roles[name]; ok {
+			return perms, nil
+		}
+		if slices.Contains(visiting, name) {
+			return nil, fmt.Errorf("%w: roles inherit in a cycle %s", ErrInvalidPolicy,
+				strings.Join(append(visiting, name), " -> "))
+		}
+		r, ok := roles[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown role %q inherited by %q", ErrInvalidPolicy, name, visiting[len(visiting)-1])
+		}
+		perms := slices.Clone(r.Permissions)
+		for _, parent := range r.Inherits {
+			inherited, err := resolve(parent, append(visiting, name))
+			if err != nil {
+				return nil, err
+			}
+			perms = append(perms, inherited...)
+		}
+		c.roles[name] = perms
+		return perms, nil
+	}
+	for name := range roles {
+		if _, err := resolve(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// withDefaults returns p with the default effect and operators set.
+func withDefaults(p Permission) Permission {
+	if p.Effect == "" {
+		p.Effect = Allow
+	}
+	conds := make([]Condition, len(p.Conditions))
+	for i, c := range p.Conditions {
+		if c.Op == "" {
+			c.Op = OpEq
+		}
+		conds[i] = c
+	}
+	p.Conditions = conds
+	return p
+}
+
+func checkPermission(p Permission) error {
+	if p.Effect != Allow && p.Effect != Deny {
+		return fmt.Errorf("unknown effect %q", p.Effect)
+	}
+	for _, pattern := range p.Resources {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+			return fmt.Errorf("resource %q: %v", pattern, err)
+		}
+	}
+	for _, cond := range p.Conditions {
+		switch cond.Op {
+		case OpExists:
+		case OpEq, OpNe, OpIn, OpNotIn, OpPrefix, OpContains:
+			if len(cond.Values) == 0 {
+				return fmt.Errorf("condition on %q: no values", cond.Attr)
+			}
+		default:
+			return fmt.Errorf("condition on %q: unknown op %q", cond.Attr, cond.Op)
+		}
+	}
+	return nil
+}
+
+func (p Permission) matches(action, resource string) bool {
+	return slices.ContainsFunc(p.Actions, func(a string) bool {
+		return a == "*" || strings.EqualFold(a, action)
+	}) && slices.ContainsFunc(p.Resources, func(pattern string) bool {
+		return matchResource(pattern, resource)
+	})
+}
+
+func matchResource(pattern, resource string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		// match the leading segments of resource with the ones of prefix
+		n := strings.Count(prefix, "/") + 1
+		parts := strings.Split(resource, "/")
+		if len(parts) < n {
+			return false
+		}
+		ok, _ := path.Match(prefix, strings.Join(parts[:n], "/"))
+		return ok
+	}
+	ok, _ := path.Match(pattern, resource)
+	return ok
+}
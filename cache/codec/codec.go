@@ -0,0 +1,150 @@
+// Package codec encodes cache values, so a value read from any storage is the
+// same Go value that was written.
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	ugorji "github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+
+	gz "github.com/apus-run/van/pkg/gzip"
+)
+
+// ErrNotProto is returned by the Proto codec for a value which is not a proto.Message.
+var ErrNotProto = errors.New("codec: value is not a proto.Message")
+
+// Codec marshals and unmarshals cache values.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	Name() string
+}
+
+var (
+	// JSON encodes values with encoding/json.
+	JSON Codec = jsonCodec{}
+	// Gob encodes values with encoding/gob, interface values must be registered.
+	Gob Codec = gobCodec{}
+	// Msgpack encodes values with MessagePack.
+	Msgpack Codec = newMsgpack()
+	// Proto encodes proto.Message values, a pointer to a proto.Message pointer is
+	// allocated on unmarshal.
+	Proto Codec = protoCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return "json" }
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (gobCodec) Name() string { return "gob" }
+
+type msgpackCodec struct {
+	handle *ugorji.MsgpackHandle
+}
+
+func newMsgpack() msgpackCodec {
+	h := &ugorji.MsgpackHandle{}
+	h.WriteExt = true
+	h.RawToString = true
+	return msgpackCodec{handle: h}
+}
+
+func (c msgpackCodec) Marshal(v any) ([]byte, error) {
+	var b []byte
+	if err := ugorji.NewEncoderBytes(&b, c.handle).Encode(v); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c msgpackCodec) Unmarshal(data []byte, v any) error {
+	return ugorji.NewDecoderBytes(data, c.handle).Decode(v)
+}
+
+func (msgpackCodec) Name() string { return "msgpack" }
+
+type protoCodec struct{}
+
+func (protoCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrNotProto
+	}
+	return proto.Marshal(m)
+}
+
+func (protoCodec) Unmarshal(data []byte, v any) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+	// a **Message, e.g. the value of a TypedCache[*pb.Message]
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Ptr {
+		return ErrNotProto
+	}
+	m, ok := reflect.New(rv.Elem().Type().Elem()).Interface().(proto.Message)
+	if !ok {
+		return ErrNotProto
+	}
+	if err := proto.Unmarshal(data, m); err != nil {
+		return err
+	}
+	rv.Elem().Set(reflect.ValueOf(m))
+	return nil
+}
+
+func (protoCodec) Name() string { return "proto" }
+
+type gzipCodec struct {
+	codec Codec
+	level int
+}
+
+// Gzip compresses the values encoded by c, level is a compress/gzip level and
+// 0 means gzip.DefaultCompression.
+func Gzip(c Codec, level int) Codec {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzipCodec{codec: c, level: level}
+}
+
+func (c gzipCodec) Marshal(v any) ([]byte, error) {
+	b, err := c.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return gz.Compress(b, c.level)
+}
+
+func (c gzipCodec) Unmarshal(data []byte, v any) error {
+	b, err := gz.Decompress(data)
+	if err != nil {
+		return err
+	}
+	return c.codec.Unmarshal(b, v)
+}
+
+func (c gzipCodec) Name() string { return c.codec.Name() + "+gzip" }
@@ -0,0 +1,54 @@
+package codec_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/apus-run/van/cache/codec"
+)
+
+type user struct {
+	Name  string
+	Age   int
+	Tags  []string
+	Attrs map[string]string
+}
+
+func TestCodec(t *testing.T) {
+	want := user{Name: "john", Age: 42, Tags: []string{"a", "b"}, Attrs: map[string]string{"k": "v"}}
+	for _, c := range []codec.Codec{
+		codec.JSON, codec.Gob, codec.Msgpack,
+		codec.Gzip(codec.JSON, 0), codec.Gzip(codec.Msgpack, 9),
+	} {
+		t.Run(c.Name(), func(t *testing.T) {
+			b, err := c.Marshal(want)
+			require.NoError(t, err)
+			var got user
+			require.NoError(t, c.Unmarshal(b, &got))
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestProto(t *testing.T) {
+	want := timestamppb.New(time.Unix(1700000000, 42))
+	b, err := codec.Proto.Marshal(want)
+	require.NoError(t, err)
+
+	got := &timestamppb.Timestamp{}
+	require.NoError(t, codec.Proto.Unmarshal(b, got))
+	assert.True(t, proto.Equal(want, got))
+
+	var ptr *timestamppb.Timestamp
+	require.NoError(t, codec.Proto.Unmarshal(b, &ptr))
+	assert.True(t, proto.Equal(want, ptr))
+
+	_, err = codec.Proto.Marshal(user{})
+	assert.ErrorIs(t, err, codec.ErrNotProto)
+	assert.ErrorIs(t, codec.Proto.Unmarshal(b, &user{}), codec.ErrNotProto)
+}
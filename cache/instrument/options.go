@@ -0,0 +1,83 @@
+package instrument
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Option is config option.
+type Option func(*Options)
+
+type Options struct {
+	// Name is the cache label of the metrics and the cache.name attribute of the spans,
+	// the String() of the storage by default.
+	Name string
+
+	// Namespace is the namespace of the metrics, "cache" by default.
+	Namespace string
+
+	// Registerer registers the metrics, prometheus.DefaultRegisterer by default.
+	Registerer prometheus.Registerer
+
+	// TracerProvider creates the tracer, the global one by default.
+	TracerProvider oteltrace.TracerProvider
+
+	// SizeGauge reports the number of keys of the storage when scraped,
+	// which lists every key and is disabled by default.
+	SizeGauge bool
+}
+
+// DefaultOptions .
+func DefaultOptions() *Options {
+	return &Options{
+		Namespace:  "cache",
+		Registerer: prometheus.DefaultRegisterer,
+	}
+}
+
+func Apply(opts ...Option) *Options {
+	options := DefaultOptions()
+	for _, o := range opts {
+		o(options)
+	}
+	if options.TracerProvider == nil {
+		options.TracerProvider = otel.GetTracerProvider()
+	}
+	return options
+}
+
+// WithName sets the cache name.
+func WithName(name string) Option {
+	return func(o *Options) {
+		o.Name = name
+	}
+}
+
+// WithNamespace sets the namespace of the metrics.
+func WithNamespace(namespace string) Option {
+	return func(o *Options) {
+		o.Namespace = namespace
+	}
+}
+
+// WithRegisterer sets the prometheus registerer.
+func WithRegisterer(r prometheus.Registerer) Option {
+	return func(o *Options) {
+		o.Registerer = r
+	}
+}
+
+// WithTracerProvider sets the tracer provider.
+func WithTracerProvider(tp oteltrace.TracerProvider) Option {
+	return func(o *Options) {
+		o.TracerProvider = tp
+	}
+}
+
+// WithSizeGauge enables the size gauge.
+func WithSizeGauge() Option {
+	return func(o *Options) {
+		o.SizeGauge = true
+	}
+}
@@ -0,0 +1,252 @@
+// Package instrument decorates a cache.Storage with Prometheus metrics and
+// OpenTelemetry spans: "cache_requests_total", "cache_request_duration_seconds",
+// "cache_evictions_total" and the optional "cache_size".
+package instrument
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	storage "github.com/apus-run/van/cache"
+)
+
+const tracerName = "github.com/apus-run/van/cache"
+
+// results of the cache_requests_total metric.
+const (
+	resultHit   = "hit"
+	resultMiss  = "miss"
+	resultOK    = "ok"
+	resultError = "error"
+)
+
+var (
+	_ storage.Storage = (*Storage)(nil)
+)
+
+type collectors struct {
+	requests  *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+	evictions *prometheus.CounterVec
+}
+
+func newCollectors(o *Options) (*collectors, error) {
+	c := &collectors{
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: o.Namespace,
+				Name:      "requests_total",
+				Help:      "Total number of cache operations by result.",
+			}, []string{"cache", "op", "result"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: o.Namespace,
+				Name:      "request_duration_seconds",
+				Help:      "Cache operation latencies in seconds.",
+				Buckets:   []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1},
+			}, []string{"cache", "op"},
+		),
+		evictions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: o.Namespace,
+				Name:      "evictions_total",
+				Help:      "Total number of evicted cache keys by reason.",
+			}, []string{"cache", "reason"},
+		),
+	}
+
+	var err error
+	if c.requests, err = register(o.Registerer, c.requests); err != nil {
+		return nil, err
+	}
+	if c.duration, err = register(o.Registerer, c.duration); err != nil {
+		return nil, err
+	}
+	if c.evictions, err = register(o.Registerer, c.evictions); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// register registers c, or returns the collector already registered, so storages
+// share the metrics of a registerer.
+func register[T prometheus.Collector](r prometheus.Registerer, c T) (T, error) {
+	if err := r.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return c, err
+	}
+	return c, nil
+}
+
+// OnEvict returns an eviction callback counting the evictions of the cache name,
+// for memory.WithOnEvict and lru.WithOnEvict.
+func OnEvict(name string, opts ...Option) (storage.EvictFunc, error) {
+	options := Apply(opts...)
+	c, err := newCollectors(options)
+	if err != nil {
+		return nil, err
+	}
+	return func(key string, val any, reason storage.EvictReason) {
+		c.evictions.WithLabelValues(name, string(reason)).Inc()
+	}, nil
+}
+
+// Storage reports the operations of a storage.
+type Storage struct {
+	storage.Storage
+	name    string
+	metrics *collectors
+	tracer  oteltrace.Tracer
+}
+
+// New returns the instrumented s.
+func New(s storage.Storage, opts ...Option) (*Storage, error) {
+	options := Apply(opts...)
+	if options.Name == "" {
+		options.Name = s.String()
+	}
+
+	c, err := newCollectors(options)
+	if err != nil {
+		return nil, err
+	}
+	if options.SizeGauge {
+		size := prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace:   options.Namespace,
+				Name:        "size",
+				Help:        "Number of cache keys.",
+				ConstLabels: prometheus.Labels{"cache": options.Name},
+			}, func() float64 {
+				return float64(len(s.Keys(context.Background())))
+			},
+		)
+		if _, err := register(options.Registerer, size); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Storage{
+		Storage: s,
+		name:    options.Name,
+		metrics: c,
+		tracer:  options.TracerProvider.Tracer(tracerName),
+	}, nil
+}
+
+func (s *Storage) Set(ctx context.Context, key string, val any, exp time.Duration) error {
+	ctx, done := s.observe(ctx, "set", attribute.String("cache.key", key))
+	err := s.Storage.Set(ctx, key, val, exp)
+	done(result(err), err)
+	return err
+}
+
+func (s *Storage) Get(ctx context.Context, key string) (any, error) {
+	ctx, done := s.observe(ctx, "get", attribute.String("cache.key", key))
+	val, err := s.Storage.Get(ctx, key)
+	done(lookup(err))
+	return val, err
+}
+
+func (s *Storage) GetAny(ctx context.Context, key string) storage.Value {
+	ctx, done := s.observe(ctx, "get", attribute.String("cache.key", key))
+	val := s.Storage.GetAny(ctx, key)
+	done(lookup(val.Error))
+	return val
+}
+
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	ctx, done := s.observe(ctx, "delete", attribute.String("cache.key", key))
+	err := s.Storage.Delete(ctx, key)
+	done(result(err), err)
+	return err
+}
+
+func (s *Storage) Deletes(ctx context.Context, keys ...string) (int64, error) {
+	ctx, done := s.observe(ctx, "deletes", attribute.StringSlice("cache.keys", keys))
+	n, err := s.Storage.Deletes(ctx, keys...)
+	done(result(err), err)
+	return n, err
+}
+
+func (s *Storage) Flush(ctx context.Context) error {
+	ctx, done := s.observe(ctx, "flush")
+	err := s.Storage.Flush(ctx)
+	done(result(err), err)
+	return err
+}
+
+func (s *Storage) Keys(ctx context.Context) []string {
+	ctx, done := s.observe(ctx, "keys")
+	keys := s.Storage.Keys(ctx)
+	done(resultOK, nil)
+	return keys
+}
+
+func (s *Storage) Contains(ctx context.Context, key string) bool {
+	ctx, done := s.observe(ctx, "contains", attribute.String("cache.key", key))
+	ok := s.Storage.Contains(ctx, key)
+	if ok {
+		done(resultHit, nil)
+	} else {
+		done(resultMiss, nil)
+	}
+	return ok
+}
+
+// Unwrap returns the instrumented storage.
+func (s *Storage) Unwrap() storage.Storage {
+	return s.Storage
+}
+
+// observe starts the span of an operation, done ends it and records the metrics.
+func (s *Storage) observe(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, func(string, error)) {
+	start := time.Now()
+	attrs = append(attrs, attribute.String("cache.name", s.name), attribute.String("cache.operation", op))
+	ctx, span := s.tracer.Start(ctx, "cache."+op,
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(attrs...),
+	)
+	return ctx, func(result string, err error) {
+		s.metrics.requests.WithLabelValues(s.name, op, result).Inc()
+		s.metrics.duration.WithLabelValues(s.name, op).Observe(time.Since(start).Seconds())
+
+		span.SetAttributes(attribute.String("cache.result", result))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func result(err error) string {
+	if err != nil {
+		return resultError
+	}
+	return resultOK
+}
+
+// lookup returns the result of a read, a missing or expired key is a miss and not an error.
+func lookup(err error) (string, error) {
+	switch {
+	case err == nil:
+		return resultHit, nil
+	case errors.Is(err, storage.ErrKeyNotExist), errors.Is(err, storage.ErrItemExpired):
+		return resultMiss, nil
+	default:
+		return resultError, err
+	}
+}
@@ -0,0 +1,76 @@
+package instrument_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	storage "github.com/apus-run/van/cache"
+	"github.com/apus-run/van/cache/instrument"
+	"github.com/apus-run/van/cache/lru"
+	"github.com/apus-run/van/cache/memory"
+)
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	reg := prometheus.NewRegistry()
+
+	s, err := instrument.New(memory.New(), instrument.WithName("users"),
+		instrument.WithRegisterer(reg), instrument.WithSizeGauge())
+	require.NoError(t, err)
+
+	require.NoError(t, s.Set(ctx, "john", "doe", time.Minute))
+	val, err := s.Get(ctx, "john")
+	require.NoError(t, err)
+	assert.Equal(t, "doe", val)
+	_, err = s.Get(ctx, "jane")
+	assert.ErrorIs(t, err, storage.ErrKeyNotExist)
+	assert.True(t, s.Contains(ctx, "john"))
+
+	expected := `
+# HELP cache_requests_total Total number of cache operations by result.
+# TYPE cache_requests_total counter
+cache_requests_total{cache="users",op="contains",result="hit"} 1
+cache_requests_total{cache="users",op="get",result="hit"} 1
+cache_requests_total{cache="users",op="get",result="miss"} 1
+cache_requests_total{cache="users",op="set",result="ok"} 1
+# HELP cache_size Number of cache keys.
+# TYPE cache_size gauge
+cache_size{cache="users"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"cache_requests_total", "cache_size"))
+	n, err := testutil.GatherAndCount(reg, "cache_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	// a second storage shares the metrics of the registerer
+	_, err = instrument.New(lru.New(), instrument.WithRegisterer(reg))
+	require.NoError(t, err)
+}
+
+func TestOnEvict(t *testing.T) {
+	ctx := context.Background()
+	reg := prometheus.NewRegistry()
+
+	onEvict, err := instrument.OnEvict("sessions", instrument.WithRegisterer(reg))
+	require.NoError(t, err)
+
+	s := memory.New(memory.WithGCInterval(100*time.Millisecond), memory.WithOnEvict(onEvict))
+	require.NoError(t, s.Set(ctx, "a", 1, time.Second))
+
+	expected := `
+# HELP cache_evictions_total Total number of evicted cache keys by reason.
+# TYPE cache_evictions_total counter
+cache_evictions_total{cache="sessions",reason="expired"} 1
+`
+	assert.Eventually(t, func() bool {
+		return testutil.GatherAndCompare(reg, strings.NewReader(expected), "cache_evictions_total") == nil
+	}, 5*time.Second, 100*time.Millisecond)
+}
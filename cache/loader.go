@@ -0,0 +1,243 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// LoadFunc loads the value of a key from its source, it returns ErrKeyNotExist
+// when the key is not found.
+type LoadFunc[T any] func(ctx context.Context, key string) (T, error)
+
+// LoaderOption is Loader option.
+type LoaderOption func(*loaderOptions)
+
+type loaderOptions struct {
+	ttl         time.Duration
+	staleTTL    time.Duration
+	negativeTTL time.Duration
+	beta        float64
+	loadTimeout time.Duration
+}
+
+// WithTTL sets how long a loaded value is fresh, 5 minutes by default.
+func WithTTL(d time.Duration) LoaderOption {
+	return func(o *loaderOptions) {
+		o.ttl = d
+	}
+}
+
+// WithStaleTTL sets how long an expired value is still served while it is
+// reloaded in background, 0 by default.
+func WithStaleTTL(d time.Duration) LoaderOption {
+	return func(o *loaderOptions) {
+		o.staleTTL = d
+	}
+}
+
+// WithNegativeTTL sets how long a not found key is cached, 0 by default.
+func WithNegativeTTL(d time.Duration) LoaderOption {
+	return func(o *loaderOptions) {
+		o.negativeTTL = d
+	}
+}
+
+// WithBeta sets the beta of the probabilistic early refresh, 1 by default, a larger
+// beta refreshes earlier and 0 disables it.
+// See: Optimal Probabilistic Cache Stampede Prevention, Vattani et al.
+func WithBeta(beta float64) LoaderOption {
+	return func(o *loaderOptions) {
+		o.beta = beta
+	}
+}
+
+// WithLoadTimeout bounds the loads, 0 by default for no bound. The loads shared by
+// concurrent callers don't stop when one of the callers is canceled.
+func WithLoadTimeout(d time.Duration) LoaderOption {
+	return func(o *loaderOptions) {
+		o.loadTimeout = d
+	}
+}
+
+// entry is a cached value of a Loader, it is stored as JSON by remote storages.
+type entry[T any] struct {
+	Value T    `json:"v"`
+	Found bool `json:"f"`
+	// Expire is the unix nano time the value turns stale.
+	Expire int64 `json:"e"`
+	// Delta is the duration of the load in nanoseconds.
+	Delta int64 `json:"d"`
+}
+
+func (e *entry[T]) MarshalBinary() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// Loader is a read-through cache of T over a Storage. Concurrent misses of a key
+// are coalesced into one load, a value is refreshed early with a probability
+// growing towards its expiration, and a stale value is served while it is reloaded.
+type Loader[T any] struct {
+	storage Storage
+	load    LoadFunc[T]
+	options *loaderOptions
+	group   singleflight.Group
+}
+
+// NewLoader returns a loader caching the values of load in s.
+func NewLoader[T any](s Storage, load LoadFunc[T], opts ...LoaderOption) *Loader[T] {
+	o := &loaderOptions{ttl: 5 * time.Minute, beta: 1}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Loader[T]{storage: s, load: load, options: o}
+}
+
+// Get returns the cached value of key, or loads it on a miss.
+func (l *Loader[T]) Get(ctx context.Context, key string) (T, error) {
+	if e, ok := l.cached(ctx, key); ok {
+		now := time.Now().UnixNano()
+		switch {
+		case now < e.Expire:
+			if l.early(e, now) {
+				l.refresh(ctx, key)
+			}
+			return l.result(e)
+		case now < e.Expire+int64(l.options.staleTTL):
+			l.refresh(ctx, key)
+			return l.result(e)
+		}
+	}
+
+	ch := l.group.DoChan(key, func() (any, error) {
+		return l.fetchShared(ctx, key)
+	})
+	var zero T
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return l.result(res.Val.(*entry[T]))
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// Set caches val for key.
+func (l *Loader[T]) Set(ctx context.Context, key string, val T) error {
+	return l.store(ctx, key, &entry[T]{Value: val, Found: true}, 0)
+}
+
+// Delete removes key from the cache.
+func (l *Loader[T]) Delete(ctx context.Context, key string) error {
+	return l.storage.Delete(ctx, key)
+}
+
+func (l *Loader[T]) result(e *entry[T]) (T, error) {
+	if !e.Found {
+		return e.Value, ErrKeyNotExist
+	}
+	return e.Value, nil
+}
+
+// early reports whether the fresh value is refreshed before its expiration,
+// that is now - delta * beta * ln(rand) >= expire.
+func (l *Loader[T]) early(e *entry[T], now int64) bool {
+	if l.options.beta <= 0 || e.Delta <= 0 || !e.Found {
+		return false
+	}
+	gap := -float64(e.Delta) * l.options.beta * math.Log(1-rand.Float64())
+	return float64(now)+gap >= float64(e.Expire)
+}
+
+// refresh reloads key in background, once for concurrent callers.
+func (l *Loader[T]) refresh(ctx context.Context, key string) {
+	l.group.DoChan(key, func() (any, error) {
+		return l.fetchShared(ctx, key)
+	})
+}
+
+// fetchShared fetches key for the coalesced callers, it isn't canceled with ctx
+// of the first caller, so that the other callers don't fail.
+func (l *Loader[T]) fetchShared(ctx context.Context, key string) (*entry[T], error) {
+	ctx = context.WithoutCancel(ctx)
+	if l.options.loadTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.options.loadTimeout)
+		defer cancel()
+	}
+	return l.fetch(ctx, key)
+}
+
+// fetch loads key and caches the result, a not found key is cached negatively.
+func (l *Loader[T]) fetch(ctx context.Context, key string) (*entry[T], error) {
+	start := time.Now()
+	val, err := l.load(ctx, key)
+	delta := time.Since(start)
+
+	e := &entry[T]{Value: val, Found: true, Delta: int64(delta)}
+	if err != nil {
+		if !errors.Is(err, ErrKeyNotExist) || l.options.negativeTTL <= 0 {
+			return nil, err
+		}
+		e.Found = false
+	}
+	if err := l.store(ctx, key, e, delta); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (l *Loader[T]) store(ctx context.Context, key string, e *entry[T], delta time.Duration) error {
+	ttl, exp := l.options.ttl, l.options.ttl+l.options.staleTTL
+	if !e.Found {
+		ttl, exp = l.options.negativeTTL, l.options.negativeTTL
+	}
+	e.Expire = time.Now().Add(ttl).UnixNano()
+	e.Delta = int64(delta)
+	// the entry expires by itself, the storage only has to keep it long enough,
+	// some storages keep whole seconds
+	if r := exp % time.Second; r != 0 {
+		exp += time.Second - r
+	}
+	return l.storage.Set(ctx, key, e, exp)
+}
+
+// cached returns the entry of key in the storage.
+func (l *Loader[T]) cached(ctx context.Context, key string) (*entry[T], bool) {
+	raw, err := l.storage.Get(ctx, key)
+	if err != nil {
+		return nil, false
+	}
+	e, err := decodeEntry[T](raw)
+	if err != nil {
+		return nil, false
+	}
+	return e, true
+}
+
+func decodeEntry[T any](raw any) (*entry[T], error) {
+	var b []byte
+	switch v := raw.(type) {
+	case *entry[T]:
+		return v, nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return nil, fmt.Errorf("cache: unexpected entry %T", raw)
+	}
+	e := &entry[T]{}
+	if err := json.Unmarshal(b, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,188 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/cache"
+	"github.com/apus-run/van/cache/memory"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func TestLoader(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Coalesce", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		l := cache.NewLoader(memory.New(), func(ctx context.Context, key string) (user, error) {
+			calls.Add(1)
+			<-release
+			return user{Name: key}, nil
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				u, err := l.Get(ctx, "john")
+				assert.NoError(t, err)
+				assert.Equal(t, "john", u.Name)
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		assert.Equal(t, int32(1), calls.Load())
+
+		u, err := l.Get(ctx, "john")
+		require.NoError(t, err)
+		assert.Equal(t, "john", u.Name)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("CallerCanceled", func(t *testing.T) {
+		release := make(chan struct{})
+		l := cache.NewLoader(memory.New(), func(ctx context.Context, key string) (user, error) {
+			select {
+			case <-release:
+				return user{Name: key}, nil
+			case <-ctx.Done():
+				return user{}, ctx.Err()
+			}
+		})
+
+		canceled, cancel := context.WithCancel(ctx)
+		first := make(chan error, 1)
+		go func() {
+			_, err := l.Get(canceled, "john")
+			first <- err
+		}()
+		time.Sleep(20 * time.Millisecond)
+		second := make(chan error, 1)
+		go func() {
+			u, err := l.Get(ctx, "john")
+			assert.Equal(t, "john", u.Name)
+			second <- err
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		// the canceled caller returns, the shared load goes on for the other one
+		cancel()
+		assert.ErrorIs(t, <-first, context.Canceled)
+		close(release)
+		assert.NoError(t, <-second)
+	})
+
+	t.Run("LoadTimeout", func(t *testing.T) {
+		l := cache.NewLoader(memory.New(), func(ctx context.Context, key string) (user, error) {
+			<-ctx.Done()
+			return user{}, ctx.Err()
+		}, cache.WithLoadTimeout(20*time.Millisecond))
+
+		_, err := l.Get(ctx, "john")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Negative", func(t *testing.T) {
+		var calls atomic.Int32
+		l := cache.NewLoader(memory.New(), func(ctx context.Context, key string) (user, error) {
+			calls.Add(1)
+			return user{}, cache.ErrKeyNotExist
+		}, cache.WithNegativeTTL(50*time.Millisecond))
+
+		for i := 0; i < 3; i++ {
+			_, err := l.Get(ctx, "nobody")
+			assert.ErrorIs(t, err, cache.ErrKeyNotExist)
+		}
+		assert.Equal(t, int32(1), calls.Load())
+
+		time.Sleep(100 * time.Millisecond)
+		_, err := l.Get(ctx, "nobody")
+		assert.ErrorIs(t, err, cache.ErrKeyNotExist)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var calls atomic.Int32
+		boom := errors.New("boom")
+		l := cache.NewLoader(memory.New(), func(ctx context.Context, key string) (user, error) {
+			calls.Add(1)
+			return user{}, boom
+		}, cache.WithNegativeTTL(time.Minute))
+
+		_, err := l.Get(ctx, "john")
+		assert.ErrorIs(t, err, boom)
+		_, err = l.Get(ctx, "john")
+		assert.ErrorIs(t, err, boom)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("StaleWhileRevalidate", func(t *testing.T) {
+		var version atomic.Int32
+		l := cache.NewLoader(memory.New(), func(ctx context.Context, key string) (int32, error) {
+			return version.Add(1), nil
+		}, cache.WithTTL(50*time.Millisecond), cache.WithStaleTTL(time.Minute), cache.WithBeta(0))
+
+		v, err := l.Get(ctx, "k")
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), v)
+
+		time.Sleep(100 * time.Millisecond)
+		v, err = l.Get(ctx, "k")
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), v, "stale value served")
+
+		assert.Eventually(t, func() bool {
+			v, _ := l.Get(ctx, "k")
+			return v == 2
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("EarlyRefresh", func(t *testing.T) {
+		var version atomic.Int32
+		l := cache.NewLoader(memory.New(), func(ctx context.Context, key string) (int32, error) {
+			time.Sleep(10 * time.Millisecond)
+			return version.Add(1), nil
+		}, cache.WithTTL(time.Minute), cache.WithBeta(1e6))
+
+		v, err := l.Get(ctx, "k")
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), v)
+
+		assert.Eventually(t, func() bool {
+			v, _ := l.Get(ctx, "k")
+			return v > 1
+		}, time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("SetDelete", func(t *testing.T) {
+		var calls atomic.Int32
+		l := cache.NewLoader(memory.New(), func(ctx context.Context, key string) (string, error) {
+			calls.Add(1)
+			return "loaded", nil
+		})
+
+		require.NoError(t, l.Set(ctx, "k", "set"))
+		v, err := l.Get(ctx, "k")
+		require.NoError(t, err)
+		assert.Equal(t, "set", v)
+
+		require.NoError(t, l.Delete(ctx, "k"))
+		v, err = l.Get(ctx, "k")
+		require.NoError(t, err)
+		assert.Equal(t, "loaded", v)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	storage "github.com/apus-run/van/cache"
 	"github.com/apus-run/van/cache/internal/timer"
 	lru "github.com/hashicorp/golang-lru/v2"
 )
@@ -21,6 +22,9 @@ type Options struct {
 	GCInterval time.Duration
 
 	Size int
+
+	// OnEvict 过期键被清理或容量已满淘汰键后的回调
+	OnEvict storage.EvictFunc
 }
 
 // DefaultOptions .
@@ -53,6 +57,13 @@ func WithSize(size int) Option {
 	}
 }
 
+// WithOnEvict 设置过期键被清理或容量已满淘汰键后的回调
+func WithOnEvict(fn storage.EvictFunc) Option {
+	return func(o *Options) {
+		o.OnEvict = fn
+	}
+}
+
 // Data initializes the cache with preconfigured items.
 func Data(items map[string]Item) Option {
 	return func(o *Options) {
@@ -21,6 +21,7 @@ type Storage struct {
 	data       *lru.Cache[string, *Item]
 	mux        sync.RWMutex
 	gcInterval time.Duration // 后台清理间隔
+	onEvict    storage.EvictFunc
 	done       chan struct{} // 停止信号
 }
 
@@ -30,6 +31,7 @@ func New(opts ...Option) *Storage {
 
 	s := &Storage{
 		gcInterval: options.GCInterval,
+		onEvict:    options.OnEvict,
 		data:       options.Data,
 		done:       make(chan struct{}),
 	}
@@ -47,9 +49,6 @@ func (s *Storage) Set(ctx context.Context, key string, val any, exp time.Duratio
 		return nil
 	}
 
-	s.mux.Lock()
-	defer s.mux.Unlock()
-
 	var e int64
 	if exp > 0 {
 		e = timer.Timestamp() + int64(exp.Seconds())
@@ -60,7 +59,23 @@ func (s *Storage) Set(ctx context.Context, key string, val any, exp time.Duratio
 		Exp: e,
 	}
 
-	s.data.Add(key, item)
+	var (
+		oldestKey string
+		oldest    *Item
+		hasOldest bool
+	)
+
+	s.mux.Lock()
+	// 新键写入时淘汰的是最久未使用的键
+	if _, ok := s.data.Peek(key); !ok && s.onEvict != nil {
+		oldestKey, oldest, hasOldest = s.data.GetOldest()
+	}
+	evicted := s.data.Add(key, item)
+	s.mux.Unlock()
+
+	if evicted && hasOldest {
+		s.onEvict(oldestKey, oldest.Value(), storage.EvictCapacity)
+	}
 	return nil
 }
 
@@ -200,13 +215,22 @@ func (s *Storage) gc() {
 
 				// 锁定以删除过期项
 				if len(expired) > 0 {
+					evicted := make(map[string]*Item, len(expired))
 					s.mux.Lock()
 					for _, key := range expired {
 						if item, ok := s.data.Peek(key); ok && item.IsExpired(ts) {
 							s.data.Remove(key)
+							evicted[key] = item
 						}
 					}
 					s.mux.Unlock()
+
+					// 在锁外回调
+					if s.onEvict != nil {
+						for key, item := range evicted {
+							s.onEvict(key, item.Value(), storage.EvictExpired)
+						}
+					}
 				}
 			}
 		}
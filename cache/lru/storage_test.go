@@ -2,12 +2,16 @@ package lru_test
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
+	storage "github.com/apus-run/van/cache"
 	"github.com/apus-run/van/cache/internal/errs"
 	"github.com/apus-run/van/cache/lru"
+	lrulib "github.com/hashicorp/golang-lru/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStorage_New(t *testing.T) {
@@ -248,3 +252,36 @@ func TestStorage_Contains(t *testing.T) {
 	exists = store.Contains(ctx, key)
 	assert.False(t, exists)
 }
+
+func TestStorage_OnEvict(t *testing.T) {
+	ctx := context.Background()
+	data, _ := lrulib.New[string, *lru.Item](2)
+
+	var mu sync.Mutex
+	evicted := make(map[string]storage.EvictReason)
+	s := lru.New(
+		lru.WithOptions(func(o *lru.Options) { o.Data = data }),
+		lru.WithGCInterval(100*time.Millisecond),
+		lru.WithOnEvict(func(key string, val any, reason storage.EvictReason) {
+			mu.Lock()
+			defer mu.Unlock()
+			evicted[key] = reason
+		}),
+	)
+
+	require.NoError(t, s.Set(ctx, "a", 1, time.Second))
+	require.NoError(t, s.Set(ctx, "b", 2, 0))
+	require.NoError(t, s.Set(ctx, "b", 3, 0))
+	require.NoError(t, s.Set(ctx, "c", 4, 0))
+
+	mu.Lock()
+	assert.Equal(t, map[string]storage.EvictReason{"a": storage.EvictCapacity}, evicted)
+	mu.Unlock()
+
+	require.NoError(t, s.Set(ctx, "b", 5, time.Second))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return evicted["b"] == storage.EvictExpired
+	}, 5*time.Second, 100*time.Millisecond)
+}
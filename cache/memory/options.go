@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	storage "github.com/apus-run/van/cache"
 	"github.com/apus-run/van/cache/internal/timer"
 )
 
@@ -18,6 +19,9 @@ type Options struct {
 
 	// gcInterval 清理过期数据的时间间隔
 	GCInterval time.Duration
+
+	// OnEvict 过期键被清理后的回调
+	OnEvict storage.EvictFunc
 }
 
 // DefaultOptions .
@@ -42,6 +46,13 @@ func WithGCInterval(d time.Duration) Option {
 	}
 }
 
+// WithOnEvict 设置过期键被清理后的回调
+func WithOnEvict(fn storage.EvictFunc) Option {
+	return func(o *Options) {
+		o.OnEvict = fn
+	}
+}
+
 // Data initializes the cache with preconfigured items.
 func Data(items map[string]Item) Option {
 	return func(o *Options) {
@@ -19,6 +19,7 @@ type Storage struct {
 	data       map[string]Item
 	done       chan struct{}
 	gcInterval time.Duration
+	onEvict    storage.EvictFunc
 	mux        sync.RWMutex
 }
 
@@ -27,6 +28,7 @@ func New(opts ...Option) *Storage {
 
 	s := &Storage{
 		gcInterval: options.GCInterval,
+		onEvict:    options.OnEvict,
 		data:       options.Data,
 		done:       make(chan struct{}),
 	}
@@ -96,6 +98,30 @@ func (s *Storage) Set(ctx context.Context, key string, val any, exp time.Duratio
 	return nil
 }
 
+// SetNX sets the value of key only if the key doesn't exist or is expired,
+// and reports whether it was set.
+func (s *Storage) SetNX(ctx context.Context, key string, val any, exp time.Duration) (bool, error) {
+	if len(key) == 0 || val == nil {
+		return false, nil
+	}
+
+	var e int64
+
+	if exp > 0 {
+		e = timer.Timestamp() + int64(exp.Seconds())
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if item, ok := s.data[key]; ok && !item.Expired() {
+		return false, nil
+	}
+	s.data[key] = *NewItem(val, e)
+
+	return true, nil
+}
+
 func (s *Storage) Delete(ctx context.Context, key string) error {
 	if len(key) == 0 {
 		return errs.ErrKeyNotExist
@@ -226,13 +252,22 @@ func (s *Storage) gc() {
 				s.mux.RUnlock()
 
 				// 锁定以删除过期项
+				evicted := make(map[string]Item)
 				s.mux.Lock()
 				for _, id := range expired {
 					if v, ok := s.data[id]; ok && v.Exp <= ts {
 						delete(s.data, id)
+						evicted[id] = v
 					}
 				}
 				s.mux.Unlock()
+
+				// 在锁外回调
+				if s.onEvict != nil {
+					for id, v := range evicted {
+						s.onEvict(id, v.Value(), storage.EvictExpired)
+					}
+				}
 			}
 		}
 	}()
@@ -731,3 +731,23 @@ func Benchmark_Memory_SetAndDelete_Asserted_Parallel(b *testing.B) {
 		}
 	})
 }
+
+func Test_Storage_Memory_SetNX(t *testing.T) {
+	t.Parallel()
+	var (
+		testStore = memory.New()
+		ctx       = context.Background()
+	)
+
+	ok, err := testStore.SetNX(ctx, "john", "hello", 0)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = testStore.SetNX(ctx, "john", "world", 0)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	val, err := testStore.Get(ctx, "john")
+	require.NoError(t, err)
+	require.Equal(t, "hello", val)
+}
@@ -45,6 +45,11 @@ func (s *Storage) GetAny(ctx context.Context, key string) (val storage.Value) {
 	return
 }
 
+// SetNX sets the value of key only if the key doesn't exist, and reports whether it was set.
+func (s *Storage) SetNX(ctx context.Context, key string, val any, exp time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, key, val, exp).Result()
+}
+
 func (s *Storage) Delete(ctx context.Context, key string) error {
 	return s.client.Del(ctx, key).Err()
 }
@@ -295,3 +295,78 @@ func TestCache_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestCache_SetNX(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(*gomock.Controller) redis.Cmdable
+
+		key        string
+		value      string
+		expiration time.Duration
+
+		wantOK  bool
+		wantErr error
+	}{
+		{
+			name: "set value",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := mocks.NewMockCmdable(ctrl)
+				status := redis.NewBoolCmd(context.Background())
+				status.SetVal(true)
+				cmd.EXPECT().
+					SetNX(context.Background(), "name", "foo", time.Minute).
+					Return(status)
+				return cmd
+			},
+			key:        "name",
+			value:      "foo",
+			expiration: time.Minute,
+			wantOK:     true,
+		},
+		{
+			name: "key exists",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := mocks.NewMockCmdable(ctrl)
+				status := redis.NewBoolCmd(context.Background())
+				status.SetVal(false)
+				cmd.EXPECT().
+					SetNX(context.Background(), "name", "foo", time.Minute).
+					Return(status)
+				return cmd
+			},
+			key:        "name",
+			value:      "foo",
+			expiration: time.Minute,
+		},
+		{
+			name: "timeout",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := mocks.NewMockCmdable(ctrl)
+				status := redis.NewBoolCmd(context.Background())
+				status.SetErr(context.DeadlineExceeded)
+				cmd.EXPECT().
+					SetNX(context.Background(), "name", "foo", time.Minute).
+					Return(status)
+				return cmd
+			},
+			key:        "name",
+			value:      "foo",
+			expiration: time.Minute,
+
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c := cache.New(tc.mock(ctrl))
+			ok, err := c.SetNX(context.Background(), tc.key, tc.value, tc.expiration)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantOK, ok)
+		})
+	}
+}
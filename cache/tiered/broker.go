@@ -0,0 +1,53 @@
+package tiered
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultChannel is the default Redis channel of the invalidation messages.
+const DefaultChannel = "van:cache:invalidate"
+
+// Broker publishes the invalidation messages to every instance.
+type Broker interface {
+	Publish(ctx context.Context, msg []byte) error
+	// Subscribe calls fn with every message published, until stop is called.
+	Subscribe(ctx context.Context, fn func(msg []byte)) (stop func(), err error)
+}
+
+var _ Broker = (*RedisBroker)(nil)
+
+// RedisBroker is a Broker on Redis pub/sub.
+type RedisBroker struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+// NewRedisBroker returns a broker publishing on the channel, DefaultChannel if empty.
+func NewRedisBroker(client redis.UniversalClient, channel string) *RedisBroker {
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	return &RedisBroker{client: client, channel: channel}
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, msg []byte) error {
+	return b.client.Publish(ctx, b.channel, msg).Err()
+}
+
+func (b *RedisBroker) Subscribe(ctx context.Context, fn func(msg []byte)) (func(), error) {
+	ps := b.client.Subscribe(ctx, b.channel)
+	// wait for the subscription, so no message published afterwards is missed
+	if _, err := ps.Receive(ctx); err != nil {
+		_ = ps.Close()
+		return nil, err
+	}
+	ch := ps.Channel()
+	go func() {
+		for m := range ch {
+			fn([]byte(m.Payload))
+		}
+	}()
+	return func() { _ = ps.Close() }, nil
+}
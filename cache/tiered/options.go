@@ -0,0 +1,45 @@
+package tiered
+
+import (
+	"time"
+)
+
+// Option is config option.
+type Option func(*Options)
+
+type Options struct {
+	// L1TTL 回填 L1 的过期时间，写入 L1 的过期时间不超过该值
+	L1TTL time.Duration
+
+	// Broker 跨实例失效 L1 的消息通道，为空时只失效本实例
+	Broker Broker
+}
+
+// DefaultOptions .
+func DefaultOptions() *Options {
+	return &Options{
+		L1TTL: time.Minute,
+	}
+}
+
+func Apply(opts ...Option) *Options {
+	options := DefaultOptions()
+	for _, o := range opts {
+		o(options)
+	}
+	return options
+}
+
+// WithL1TTL sets the max expiration of the L1 items.
+func WithL1TTL(d time.Duration) Option {
+	return func(o *Options) {
+		o.L1TTL = d
+	}
+}
+
+// WithBroker sets the broker invalidating L1 across instances.
+func WithBroker(b Broker) Option {
+	return func(o *Options) {
+		o.Broker = b
+	}
+}
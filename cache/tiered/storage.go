@@ -0,0 +1,228 @@
+package tiered
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	storage "github.com/apus-run/van/cache"
+	"github.com/apus-run/van/cache/internal/errs"
+)
+
+var (
+	_ storage.Storage = (*Storage)(nil)
+)
+
+// Storage is a two tiers storage, it reads L1 first, falls back to L2 and back-fills
+// L1 with a shorter expiration. Writes go to L2 then L1, and invalidate the L1 of
+// the other instances through the broker.
+type Storage struct {
+	l1, l2 storage.Storage
+	l1TTL  time.Duration
+	broker Broker
+	id     string
+	stop   func()
+
+	// gen is bumped by every L1 invalidation, under the write lock of mu, a
+	// back-fill is skipped if it changed since its L2 read started
+	mu  sync.RWMutex
+	gen atomic.Uint64
+
+	l1Hits, l1Misses atomic.Int64
+	l2Hits, l2Misses atomic.Int64
+}
+
+// TierStats is the hit/miss stats of a tier.
+type TierStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// Stats is the hit/miss stats of the tiers.
+type Stats struct {
+	L1 TierStats
+	L2 TierStats
+}
+
+// message is an invalidation message.
+type message struct {
+	// Source is the id of the publishing instance, which ignores its own messages.
+	Source string   `json:"source"`
+	Keys   []string `json:"keys,omitempty"`
+	Flush  bool     `json:"flush,omitempty"`
+}
+
+// New returns a tiered storage, it subscribes to the broker if any.
+func New(l1, l2 storage.Storage, opts ...Option) (*Storage, error) {
+	options := Apply(opts...)
+
+	s := &Storage{
+		l1:     l1,
+		l2:     l2,
+		l1TTL:  options.L1TTL,
+		broker: options.Broker,
+		id:     uuid.NewString(),
+	}
+	if s.broker != nil {
+		stop, err := s.broker.Subscribe(context.Background(), s.invalidate)
+		if err != nil {
+			return nil, err
+		}
+		s.stop = stop
+	}
+	return s, nil
+}
+
+func (s *Storage) Get(ctx context.Context, key string) (any, error) {
+	if val, err := s.l1.Get(ctx, key); err == nil {
+		s.l1Hits.Add(1)
+		return val, nil
+	}
+	s.l1Misses.Add(1)
+
+	gen := s.gen.Load()
+	val, err := s.l2.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, errs.ErrKeyNotExist) || errors.Is(err, errs.ErrItemExpired) {
+			s.l2Misses.Add(1)
+		}
+		return nil, err
+	}
+	s.l2Hits.Add(1)
+	s.mu.RLock()
+	if s.gen.Load() == gen {
+		_ = s.l1.Set(ctx, key, val, s.l1TTL)
+	}
+	s.mu.RUnlock()
+	return val, nil
+}
+
+func (s *Storage) GetAny(ctx context.Context, key string) (val storage.Value) {
+	val.Value, val.Error = s.Get(ctx, key)
+	return
+}
+
+func (s *Storage) Set(ctx context.Context, key string, val any, exp time.Duration) error {
+	if err := s.l2.Set(ctx, key, val, exp); err != nil {
+		return err
+	}
+	if err := s.updateL1(func() error {
+		return s.l1.Set(ctx, key, val, s.ttl(exp))
+	}); err != nil {
+		return err
+	}
+	return s.publish(ctx, message{Keys: []string{key}})
+}
+
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	if err := s.l2.Delete(ctx, key); err != nil {
+		return err
+	}
+	_ = s.updateL1(func() error {
+		return s.l1.Delete(ctx, key)
+	})
+	return s.publish(ctx, message{Keys: []string{key}})
+}
+
+func (s *Storage) Deletes(ctx context.Context, keys ...string) (int64, error) {
+	n, err := s.l2.Deletes(ctx, keys...)
+	if err != nil {
+		return n, err
+	}
+	_ = s.updateL1(func() error {
+		_, err := s.l1.Deletes(ctx, keys...)
+		return err
+	})
+	return n, s.publish(ctx, message{Keys: keys})
+}
+
+func (s *Storage) Flush(ctx context.Context) error {
+	if err := s.l2.Flush(ctx); err != nil {
+		return err
+	}
+	if err := s.updateL1(func() error {
+		return s.l1.Flush(ctx)
+	}); err != nil {
+		return err
+	}
+	return s.publish(ctx, message{Flush: true})
+}
+
+// Keys returns the keys of L2, which holds every key.
+func (s *Storage) Keys(ctx context.Context) []string {
+	return s.l2.Keys(ctx)
+}
+
+func (s *Storage) Contains(ctx context.Context, key string) bool {
+	return s.l1.Contains(ctx, key) || s.l2.Contains(ctx, key)
+}
+
+// Stats returns the hit/miss stats of the tiers.
+func (s *Storage) Stats() Stats {
+	return Stats{
+		L1: TierStats{Hits: s.l1Hits.Load(), Misses: s.l1Misses.Load()},
+		L2: TierStats{Hits: s.l2Hits.Load(), Misses: s.l2Misses.Load()},
+	}
+}
+
+// Close unsubscribes from the broker.
+func (s *Storage) Close() error {
+	if s.stop != nil {
+		s.stop()
+	}
+	return nil
+}
+
+func (s *Storage) String() string {
+	return "tiered(" + s.l1.String() + "," + s.l2.String() + ")"
+}
+
+// ttl returns the L1 expiration of an item expiring after exp.
+func (s *Storage) ttl(exp time.Duration) time.Duration {
+	if exp <= 0 || exp > s.l1TTL {
+		return s.l1TTL
+	}
+	return exp
+}
+
+// updateL1 runs fn, which changes L1, and skips the back-fills in flight, whose
+// L2 values may be older.
+func (s *Storage) updateL1(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gen.Add(1)
+	return fn()
+}
+
+func (s *Storage) publish(ctx context.Context, m message) error {
+	if s.broker == nil {
+		return nil
+	}
+	m.Source = s.id
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return s.broker.Publish(ctx, b)
+}
+
+// invalidate removes the keys of a message of another instance from L1.
+func (s *Storage) invalidate(b []byte) {
+	var m message
+	if err := json.Unmarshal(b, &m); err != nil || m.Source == s.id {
+		return
+	}
+	ctx := context.Background()
+	_ = s.updateL1(func() error {
+		if m.Flush {
+			return s.l1.Flush(ctx)
+		}
+		_, err := s.l1.Deletes(ctx, m.Keys...)
+		return err
+	})
+}
@@ -0,0 +1,149 @@
+package tiered_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/cache"
+	"github.com/apus-run/van/cache/internal/errs"
+	"github.com/apus-run/van/cache/memory"
+	"github.com/apus-run/van/cache/tiered"
+)
+
+// broker delivers the messages synchronously to every subscriber.
+type broker struct {
+	mu   sync.Mutex
+	subs map[int]func([]byte)
+	next int
+}
+
+func (b *broker) Publish(_ context.Context, msg []byte) error {
+	b.mu.Lock()
+	subs := make([]func([]byte), 0, len(b.subs))
+	for _, fn := range b.subs {
+		subs = append(subs, fn)
+	}
+	b.mu.Unlock()
+	for _, fn := range subs {
+		fn(msg)
+	}
+	return nil
+}
+
+func (b *broker) Subscribe(_ context.Context, fn func([]byte)) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs == nil {
+		b.subs = make(map[int]func([]byte))
+	}
+	id := b.next
+	b.next++
+	b.subs[id] = fn
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs, id)
+	}, nil
+}
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	l2 := memory.New()
+	b := &broker{}
+
+	l1a, l1b := memory.New(), memory.New()
+	a, err := tiered.New(l1a, l2, tiered.WithBroker(b), tiered.WithL1TTL(time.Minute))
+	require.NoError(t, err)
+	defer a.Close()
+	c, err := tiered.New(l1b, l2, tiered.WithBroker(b))
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, a.Set(ctx, "name", "foo", time.Hour))
+	assert.True(t, l1a.Contains(ctx, "name"))
+	assert.False(t, l1b.Contains(ctx, "name"))
+
+	// c misses L1, hits L2 and back-fills L1
+	val, err := c.Get(ctx, "name")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", val)
+	assert.True(t, l1b.Contains(ctx, "name"))
+	val, err = c.Get(ctx, "name")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", val)
+	assert.Equal(t, tiered.Stats{
+		L1: tiered.TierStats{Hits: 1, Misses: 1},
+		L2: tiered.TierStats{Hits: 1},
+	}, c.Stats())
+
+	// a set invalidates the L1 of c, but not its own
+	require.NoError(t, a.Set(ctx, "name", "bar", time.Hour))
+	assert.False(t, l1b.Contains(ctx, "name"))
+	assert.True(t, l1a.Contains(ctx, "name"))
+	val, err = c.Get(ctx, "name")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", val)
+
+	require.NoError(t, c.Delete(ctx, "name"))
+	assert.False(t, l1a.Contains(ctx, "name"))
+	_, err = a.Get(ctx, "name")
+	assert.ErrorIs(t, err, errs.ErrKeyNotExist)
+	assert.Equal(t, int64(1), a.Stats().L2.Misses)
+	assert.True(t, a.GetAny(ctx, "name").KeyNotFound())
+
+	require.NoError(t, a.Set(ctx, "k1", "v1", 0))
+	require.NoError(t, a.Set(ctx, "k2", "v2", 0))
+	_, _ = c.Get(ctx, "k1")
+	assert.ElementsMatch(t, []string{"k1", "k2"}, c.Keys(ctx))
+	require.NoError(t, a.Flush(ctx))
+	assert.False(t, l1b.Contains(ctx, "k1"))
+	assert.False(t, c.Contains(ctx, "k2"))
+}
+
+// slowStorage blocks a Get after reading its value, until release is closed.
+type slowStorage struct {
+	cache.Storage
+	read    chan struct{}
+	release chan struct{}
+}
+
+func (s *slowStorage) Get(ctx context.Context, key string) (any, error) {
+	val, err := s.Storage.Get(ctx, key)
+	close(s.read)
+	<-s.release
+	return val, err
+}
+
+func TestStorage_BackFillRace(t *testing.T) {
+	ctx := context.Background()
+	l2 := memory.New()
+	b := &broker{}
+
+	a, err := tiered.New(memory.New(), l2, tiered.WithBroker(b))
+	require.NoError(t, err)
+	defer a.Close()
+	slow := &slowStorage{Storage: l2, read: make(chan struct{}), release: make(chan struct{})}
+	l1 := memory.New()
+	c, err := tiered.New(l1, slow, tiered.WithBroker(b))
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, l2.Set(ctx, "name", "foo", 0))
+	done := make(chan any)
+	go func() {
+		val, _ := c.Get(ctx, "name")
+		done <- val
+	}()
+
+	// the invalidation arrives after the L2 read of c started, the stale value isn't back-filled
+	<-slow.read
+	require.NoError(t, a.Set(ctx, "name", "bar", 0))
+	close(slow.release)
+	assert.Equal(t, "foo", <-done)
+	assert.False(t, l1.Contains(ctx, "name"))
+}
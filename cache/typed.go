@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/apus-run/van/cache/codec"
+)
+
+// TypedCache stores values of T encoded by a codec, so a value reads back the same
+// from the memory, lru and redis storages.
+type TypedCache[T any] struct {
+	storage Storage
+	codec   codec.Codec
+}
+
+// NewTyped returns a typed cache over s, c is codec.JSON if nil.
+func NewTyped[T any](s Storage, c codec.Codec) *TypedCache[T] {
+	if c == nil {
+		c = codec.JSON
+	}
+	return &TypedCache[T]{storage: s, codec: c}
+}
+
+// Set encodes val and stores it for key.
+func (c *TypedCache[T]) Set(ctx context.Context, key string, val T, exp time.Duration) error {
+	b, err := c.codec.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("cache: marshal %s: %w", key, err)
+	}
+	return c.storage.Set(ctx, key, b, exp)
+}
+
+// Get returns the decoded value of key.
+func (c *TypedCache[T]) Get(ctx context.Context, key string) (T, error) {
+	var val T
+	raw, err := c.storage.Get(ctx, key)
+	if err != nil {
+		return val, err
+	}
+	var b []byte
+	switch v := raw.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return val, fmt.Errorf("cache: %s holds %T, not an encoded value", key, raw)
+	}
+	if err := c.codec.Unmarshal(b, &val); err != nil {
+		return val, fmt.Errorf("cache: unmarshal %s: %w", key, err)
+	}
+	return val, nil
+}
+
+// Delete removes key.
+func (c *TypedCache[T]) Delete(ctx context.Context, key string) error {
+	return c.storage.Delete(ctx, key)
+}
+
+// Contains reports whether key is cached.
+func (c *TypedCache[T]) Contains(ctx context.Context, key string) bool {
+	return c.storage.Contains(ctx, key)
+}
+
+// Storage returns the underlying storage.
+func (c *TypedCache[T]) Storage() Storage {
+	return c.storage
+}
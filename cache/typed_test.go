@@ -0,0 +1,71 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/cache"
+	"github.com/apus-run/van/cache/codec"
+	"github.com/apus-run/van/cache/lru"
+	"github.com/apus-run/van/cache/memory"
+)
+
+// stringStorage returns strings like the redis storage.
+type stringStorage struct {
+	cache.Storage
+}
+
+func (s stringStorage) Get(ctx context.Context, key string) (any, error) {
+	v, err := s.Storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return string(v.([]byte)), nil
+}
+
+type profile struct {
+	Name    string
+	Age     int
+	Created time.Time
+	Tags    []string
+}
+
+func TestTypedCache(t *testing.T) {
+	ctx := context.Background()
+	want := profile{Name: "john", Age: 42, Created: time.Unix(1700000000, 0).UTC(), Tags: []string{"a"}}
+
+	storages := map[string]func() cache.Storage{
+		"memory": func() cache.Storage { return memory.New() },
+		"lru":    func() cache.Storage { return lru.New() },
+		"redis":  func() cache.Storage { return stringStorage{memory.New()} },
+	}
+	codecs := []codec.Codec{codec.JSON, codec.Gob, codec.Msgpack, codec.Gzip(codec.JSON, 0)}
+
+	for name, newStorage := range storages {
+		for _, c := range codecs {
+			t.Run(name+"/"+c.Name(), func(t *testing.T) {
+				tc := cache.NewTyped[profile](newStorage(), c)
+				require.NoError(t, tc.Set(ctx, "john", want, time.Minute))
+				got, err := tc.Get(ctx, "john")
+				require.NoError(t, err)
+				assert.Equal(t, want, got)
+
+				require.NoError(t, tc.Delete(ctx, "john"))
+				_, err = tc.Get(ctx, "john")
+				assert.ErrorIs(t, err, cache.ErrKeyNotExist)
+			})
+		}
+	}
+}
+
+func TestTypedCache_NotEncoded(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	require.NoError(t, s.Set(ctx, "k", 42, 0))
+	_, err := cache.NewTyped[int](s, nil).Get(ctx, "k")
+	assert.Error(t, err)
+}
@@ -9,6 +9,14 @@ import (
 	"github.com/apus-run/van/pkg/value"
 )
 
+var (
+	// ErrKeyNotExist is returned when the key is not found in cache,
+	// a LoadFunc returns it for a key not found in its source.
+	ErrKeyNotExist = errs.ErrKeyNotExist
+	// ErrItemExpired is returned when the item found in cache has expired.
+	ErrItemExpired = errs.ErrItemExpired
+)
+
 type Storage interface {
 	Set(ctx context.Context, key string, val any, exp time.Duration) error
 	Get(ctx context.Context, key string) (any, error)
@@ -21,6 +29,19 @@ type Storage interface {
 	String() string
 }
 
+// EvictReason 是键被淘汰的原因
+type EvictReason string
+
+const (
+	// EvictExpired 键过期后被清理
+	EvictExpired EvictReason = "expired"
+	// EvictCapacity 容量已满时淘汰最久未使用的键
+	EvictCapacity EvictReason = "capacity"
+)
+
+// EvictFunc 在键被淘汰后调用，不能在回调中访问触发淘汰的存储
+type EvictFunc func(key string, val any, reason EvictReason)
+
 // Value 代表一个从缓存中读取出来的值
 type Value struct {
 	value.AnyValue
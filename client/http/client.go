@@ -0,0 +1,287 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/apus-run/van/ginx/middlewares/requstid"
+	"github.com/apus-run/van/pkg/breaker"
+	"github.com/apus-run/van/pkg/retry"
+	"github.com/apus-run/van/selector"
+)
+
+// ErrMissingRegistry is returned when a discovery:// endpoint is used without registry.
+var ErrMissingRegistry = errors.New("http client: discovery endpoint requires a registry")
+
+// Client is a HTTP client resolving targets through the registry, applying
+// timeouts and retries, and decoding responses into typed errors.
+type Client struct {
+	*http.Client
+	options *options
+	base    *url.URL
+}
+
+// NewClient create a HTTP client.
+func NewClient(opts ...Option) (*Client, error) {
+	o := &options{
+		timeout: 2 * time.Second,
+		decoder: DefaultDecodeResponse,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.propagators == nil {
+		o.propagators = otel.GetTextMapPropagator()
+	}
+
+	var base *url.URL
+	if o.endpoint != "" {
+		u, err := url.Parse(o.endpoint)
+		if err != nil {
+			return nil, err
+		}
+		base = u
+	}
+
+	tr := o.transport
+	if tr == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		if o.tlsConfig != nil {
+			t.TLSClientConfig = o.tlsConfig
+		}
+		tr = t
+	}
+	if base != nil && base.Scheme == selector.DiscoveryScheme {
+		if o.registry == nil {
+			return nil, ErrMissingRegistry
+		}
+		st := selector.NewTransport(o.registry, o.selectorOpts...)
+		st.Base = tr
+		tr = st
+	}
+	if o.breaker != nil {
+		tr = &breakerTransport{base: tr, breaker: o.breaker}
+	}
+
+	return &Client{
+		Client:  &http.Client{Transport: tr},
+		options: o,
+		base:    base,
+	}, nil
+}
+
+// Invoke sends a JSON request of in to the endpoint path, and decodes the
+// response into out. A nil in sends no body.
+func (c *Client) Invoke(ctx context.Context, method, path string, in, out any, opts ...CallOption) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	u, err := c.url(path)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.Do(req, opts...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return c.options.decoder(resp, out)
+}
+
+// Do sends req with the client timeout and retries, its body must be
+// replayable through req.GetBody to be retried. The response body must be closed.
+func (c *Client) Do(req *http.Request, opts ...CallOption) (*http.Response, error) {
+	co := callOptions{
+		timeout: c.options.timeout,
+		retry:   c.options.retry,
+	}
+	for _, opt := range opts {
+		opt(&co)
+	}
+
+	ctx := req.Context()
+	cancel := context.CancelFunc(func() {})
+	if co.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, co.timeout)
+	}
+	req = req.Clone(ctx)
+	c.header(ctx, req, co.header)
+
+	var strategy retry.Strategy
+	if co.retry != nil {
+		strategy = co.retry()
+	}
+	for {
+		resp, err := c.Client.Do(req)
+		if strategy == nil || !retryable(ctx, resp, err) {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		interval, ok := strategy.Next()
+		if !ok {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			cancel()
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				cancel()
+				return nil, fmt.Errorf("http client: cannot retry %s %s, body is not replayable", req.Method, req.URL)
+			}
+			b, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = b
+		}
+	}
+}
+
+// Close stops watching the registry of a discovery:// endpoint.
+func (c *Client) Close() error {
+	tr := c.Client.Transport
+	if bt, ok := tr.(*breakerTransport); ok {
+		tr = bt.base
+	}
+	if st, ok := tr.(*selector.Transport); ok {
+		return st.Close()
+	}
+	return nil
+}
+
+func (c *Client) url(path string) (string, error) {
+	if c.base == nil {
+		return path, nil
+	}
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	if ref.IsAbs() {
+		return path, nil
+	}
+	u := *c.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + strings.TrimPrefix(ref.Path, "/")
+	u.RawQuery = ref.RawQuery
+	return u.String(), nil
+}
+
+// header sets the client and call headers, and propagates the request id and trace context.
+func (c *Client) header(ctx context.Context, req *http.Request, h http.Header) {
+	for k, v := range c.options.header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	for k, v := range h {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	if req.Header.Get(requstid.HeaderXRequestIDKey) == "" {
+		if id := requestID(ctx); id != "" {
+			req.Header.Set(requstid.HeaderXRequestIDKey, id)
+		}
+	}
+	c.options.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+}
+
+func requestID(ctx context.Context) string {
+	if id := requstid.CtxRequestID(ctx); id != "" {
+		return id
+	}
+	if id, ok := ctx.Value(requstid.RequestIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// retryable reports whether a failed call is worth retrying, that is on
+// transport errors and on 502, 503 and 504 responses.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, selector.ErrNoAvailable) && !errors.Is(err, breaker.ErrNotAllowed)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// cancelBody releases the call context once the response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// breakerTransport rejects requests while the breaker is open, and marks
+// transport errors and 5xx responses as failures.
+type breakerTransport struct {
+	base    http.RoundTripper
+	breaker breaker.Breaker
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+		t.breaker.MarkFailed()
+	} else {
+		t.breaker.MarkSuccess()
+	}
+	return resp, err
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/errorsx"
+	"github.com/apus-run/van/ginx"
+	"github.com/apus-run/van/ginx/middlewares/requstid"
+	"github.com/apus-run/van/pkg/breaker"
+	"github.com/apus-run/van/pkg/retry"
+	"github.com/apus-run/van/registry"
+	"github.com/apus-run/van/registry/memory"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.GET("/users/1", func(c *gin.Context) {
+		c.JSON(http.StatusOK, ginx.Result{Code: ginx.CodeOK, Msg: "ok", Data: user{ID: 1, Name: "van"}})
+	})
+	g.POST("/users", func(c *gin.Context) {
+		var u user
+		_ = c.ShouldBindJSON(&u)
+		c.JSON(http.StatusOK, u)
+	})
+	g.GET("/users/2", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, errorsx.NotFound("UserNotFound").WithMessage("user 2 not found"))
+	})
+	g.GET("/users/3", func(c *gin.Context) {
+		c.JSON(http.StatusOK, ginx.Result{Code: ginx.CodeErr, Msg: "invalid user", Data: gin.H{}})
+	})
+	g.GET("/request-id", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetHeader(requstid.HeaderXRequestIDKey))
+	})
+	srv := httptest.NewServer(g)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_Invoke(t *testing.T) {
+	srv := newServer(t)
+	c, err := NewClient(WithEndpoint(srv.URL))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var u user
+	require.NoError(t, c.Invoke(ctx, http.MethodGet, "/users/1", nil, &u))
+	assert.Equal(t, user{ID: 1, Name: "van"}, u)
+
+	var created user
+	require.NoError(t, c.Invoke(ctx, http.MethodPost, "/users", user{ID: 2, Name: "run"}, &created))
+	assert.Equal(t, user{ID: 2, Name: "run"}, created)
+
+	err = c.Invoke(ctx, http.MethodGet, "/users/2", nil, &u)
+	e := new(errorsx.Error)
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, http.StatusNotFound, e.Code)
+	assert.Equal(t, "UserNotFound", e.Reason)
+	assert.Equal(t, "user 2 not found", e.Message)
+
+	err = c.Invoke(ctx, http.MethodGet, "/users/3", nil, &u)
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, http.StatusInternalServerError, e.Code)
+	assert.Equal(t, "Internal", e.Reason)
+	assert.Equal(t, "invalid user", e.Message)
+
+	err = c.Invoke(ctx, http.MethodGet, "/missing", nil, &u)
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "NotFound", e.Reason)
+
+	var id string
+	rctx := context.WithValue(ctx, requstid.RequestIDKey, "req-1")
+	require.NoError(t, c.Invoke(rctx, http.MethodGet, "/request-id", nil, &id))
+	assert.Equal(t, "req-1", id)
+}
+
+func TestClient_Retry(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":1,"name":"van"}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithRetry(func() retry.Strategy {
+			s, _ := retry.NewFixedIntervalRetryStrategy(time.Millisecond, 3)
+			return s
+		}),
+	)
+	require.NoError(t, err)
+
+	var u user
+	require.NoError(t, c.Invoke(context.Background(), http.MethodPost, "/", user{ID: 1}, &u))
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, "van", u.Name)
+
+	calls.Store(0)
+	err = c.Invoke(context.Background(), http.MethodGet, "/", nil, &u, Retry(nil))
+	assert.Equal(t, http.StatusServiceUnavailable, errorsx.Code(err))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_Breaker(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithBreaker(breaker.NewSRE(breaker.WithRequest(10))),
+	)
+	require.NoError(t, err)
+
+	rejected := 0
+	for i := 0; i < 100; i++ {
+		err := c.Invoke(context.Background(), http.MethodGet, "/", nil, nil)
+		if errors.Is(err, breaker.ErrNotAllowed) {
+			rejected++
+		}
+	}
+	assert.Greater(t, rejected, 0)
+	assert.Equal(t, int32(100-rejected), calls.Load())
+}
+
+func TestClient_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(WithEndpoint(srv.URL))
+	require.NoError(t, err)
+	err = c.Invoke(context.Background(), http.MethodGet, "/", nil, nil, Timeout(20*time.Millisecond))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_Discovery(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+
+	_, err := NewClient(WithEndpoint("discovery://users"))
+	assert.ErrorIs(t, err, ErrMissingRegistry)
+
+	r := memory.New()
+	require.NoError(t, r.Register(ctx, &registry.ServiceInstance{ID: "1", Name: "users", Endpoints: []string{srv.URL}}))
+	c, err := NewClient(WithEndpoint("discovery://users"), WithRegistry(r))
+	require.NoError(t, err)
+	defer c.Close()
+
+	var u user
+	require.NoError(t, c.Invoke(ctx, http.MethodGet, "/users/1", nil, &u))
+	assert.Equal(t, "van", u.Name)
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apus-run/van/errorsx"
+	"github.com/apus-run/van/ginx"
+	httpstatus "github.com/apus-run/van/server/http/status"
+)
+
+// DecodeResponseFunc decodes the response body into out, or into an error.
+type DecodeResponseFunc func(resp *http.Response, out any) error
+
+// envelope is the ginx.Result wire format with a lazily decoded data.
+type envelope struct {
+	Code    *int            `json:"code"`
+	Msg     string          `json:"msg"`
+	Data    json.RawMessage `json:"data"`
+	Details []string        `json:"details,omitempty"`
+}
+
+// errorBody is the errorsx.Error wire format.
+type errorBody struct {
+	Code     int               `json:"code"`
+	Reason   string            `json:"reason"`
+	Message  string            `json:"message"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// DefaultDecodeResponse decodes a ginx.Result envelope or a plain JSON body
+// into out. Non 2xx responses and envelopes with a code other than
+// ginx.CodeOK are decoded into an *errorsx.Error.
+func DefaultDecodeResponse(resp *http.Response, out any) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return DecodeError(resp.StatusCode, body)
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 || out == nil {
+		return nil
+	}
+	if !isJSON(resp) {
+		if s, ok := out.(*string); ok {
+			*s = string(body)
+			return nil
+		}
+		if b, ok := out.(*[]byte); ok {
+			*b = body
+			return nil
+		}
+	}
+
+	var env envelope
+	if err = json.Unmarshal(body, &env); err == nil && env.Code != nil && env.Data != nil {
+		if *env.Code != ginx.CodeOK {
+			// a failed envelope sent with 200, there is no better status to report.
+			return DecodeError(http.StatusInternalServerError, body)
+		}
+		if len(env.Data) == 0 || string(env.Data) == "null" {
+			return nil
+		}
+		return json.Unmarshal(env.Data, out)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// DecodeError decodes an error response body into an *errorsx.Error, the body
+// may be an errorsx.Error, a ginx.Result envelope or anything else.
+func DecodeError(code int, body []byte) *errorsx.Error {
+	var eb errorBody
+	if err := json.Unmarshal(body, &eb); err == nil && eb.Reason != "" {
+		if eb.Code == 0 {
+			eb.Code = code
+		}
+		e := errorsx.New(eb.Code, eb.Reason).WithMessage(eb.Message)
+		if len(eb.Metadata) > 0 {
+			e = e.WithMetadata(eb.Metadata)
+		}
+		return e
+	}
+
+	var env envelope
+	if err := json.Unmarshal(body, &env); err == nil && env.Code != nil {
+		return errorsx.New(code, reason(code)).
+			WithMessage(env.Msg).
+			KV("code", strconv.Itoa(*env.Code))
+	}
+
+	msg := strings.TrimSpace(string(body))
+	if msg == "" {
+		msg = http.StatusText(code)
+	}
+	return errorsx.New(code, reason(code)).WithMessage(msg)
+}
+
+// reason returns the gRPC code name of an HTTP status, e.g. "NotFound".
+func reason(code int) string {
+	return httpstatus.ToGRPCCode(code).String()
+}
+
+func isJSON(resp *http.Response) bool {
+	ct := resp.Header.Get("Content-Type")
+	return ct == "" || strings.Contains(ct, "json")
+}
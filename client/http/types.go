@@ -0,0 +1,143 @@
+package http
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/apus-run/van/pkg/breaker"
+	"github.com/apus-run/van/pkg/retry"
+	"github.com/apus-run/van/registry"
+	"github.com/apus-run/van/selector"
+)
+
+// Option is client option.
+type Option func(o *options)
+
+// options is client options.
+type options struct {
+	// target base url, e.g. http://127.0.0.1:8000 or discovery://user-service
+	endpoint string
+	// registry resolving discovery:// targets
+	registry registry.Registry
+	// selector options of discovery:// targets
+	selectorOpts []selector.Option
+
+	// per-call timeout, including retries
+	timeout time.Duration
+	// retry strategy factory, a strategy is created per call
+	retry func() retry.Strategy
+
+	// circuit breaker of the target
+	breaker breaker.Breaker
+
+	// underlying transport
+	transport http.RoundTripper
+	tlsConfig *tls.Config
+
+	// trace context propagators
+	propagators propagation.TextMapPropagator
+	// headers set on every request
+	header http.Header
+	// response decoder
+	decoder DecodeResponseFunc
+}
+
+// WithEndpoint with the client target base url, discovery://<name> targets
+// are resolved through the registry set with WithRegistry.
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) { o.endpoint = endpoint }
+}
+
+// WithRegistry with the registry resolving discovery:// targets.
+func WithRegistry(r registry.Registry) Option {
+	return func(o *options) { o.registry = r }
+}
+
+// WithSelector with the selector options of discovery:// targets, such as a picker or filters.
+func WithSelector(opts ...selector.Option) Option {
+	return func(o *options) { o.selectorOpts = append(o.selectorOpts, opts...) }
+}
+
+// WithTimeout with the default per-call timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithRetry with the default retry strategy factory, e.g.
+//
+//	WithRetry(func() retry.Strategy {
+//		s, _ := retry.NewExponentialBackoffRetryStrategy(100*time.Millisecond, time.Second, 3)
+//		return s
+//	})
+func WithRetry(fn func() retry.Strategy) Option {
+	return func(o *options) { o.retry = fn }
+}
+
+// WithBreaker with the circuit breaker of the target, a request rejected by it fails
+// with breaker.ErrNotAllowed and is not retried. Transport errors and 5xx responses
+// are failures.
+func WithBreaker(b breaker.Breaker) Option {
+	return func(o *options) { o.breaker = b }
+}
+
+// WithTransport with the underlying transport, http.DefaultTransport by default.
+func WithTransport(tr http.RoundTripper) Option {
+	return func(o *options) { o.transport = tr }
+}
+
+// WithTLSConfig with the TLS config of the default transport.
+func WithTLSConfig(c *tls.Config) Option {
+	return func(o *options) { o.tlsConfig = c }
+}
+
+// WithPropagators with the trace context propagators, the global ones by default.
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(o *options) { o.propagators = p }
+}
+
+// WithHeader with a header set on every request.
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// WithDecoder with the response decoder, DefaultDecodeResponse by default.
+func WithDecoder(d DecodeResponseFunc) Option {
+	return func(o *options) { o.decoder = d }
+}
+
+// CallOption is a per-call option.
+type CallOption func(o *callOptions)
+
+type callOptions struct {
+	timeout time.Duration
+	retry   func() retry.Strategy
+	header  http.Header
+}
+
+// Timeout overrides the call timeout.
+func Timeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) { o.timeout = timeout }
+}
+
+// Retry overrides the call retry strategy, nil disables retries.
+func Retry(fn func() retry.Strategy) CallOption {
+	return func(o *callOptions) { o.retry = fn }
+}
+
+// Header sets a header on the call request.
+func Header(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Add(key, value)
+	}
+}
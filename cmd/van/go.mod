@@ -1,8 +1,9 @@
 module github.com/apus-run/van/cmd/van
 
-go 1.22
+go 1.23.0
 
 require (
+	github.com/apus-run/van v0.0.0-00010101000000-000000000000
 	github.com/charmbracelet/huh v0.5.1
 	github.com/spf13/cobra v1.8.1
 )
@@ -33,7 +34,9 @@ require (
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
-	golang.org/x/sync v0.7.0 // indirect
-	golang.org/x/sys v0.21.0 // indirect
-	golang.org/x/text v0.16.0 // indirect
+	golang.org/x/sync v0.12.0 // indirect
+	golang.org/x/sys v0.23.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
 )
+
+replace github.com/apus-run/van => ../..
@@ -0,0 +1,112 @@
+package conf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/apus-run/van/conf/secret"
+)
+
+// Cmd represents the conf command.
+var Cmd = &cobra.Command{
+	Use:     "conf",
+	Example: "van conf encrypt <value>",
+	Short:   "Manage the encrypted conf values.",
+	Long:    "manage the enc: values of conf files, the key is read from --key, VAN_CONF_KEY or VAN_CONF_KEY_FILE.",
+}
+
+var keyFlag string
+
+var keygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate a key.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		key, err := secret.GenerateKey()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), secret.EncodeKey(key))
+		return nil
+	},
+}
+
+var encryptCmd = &cobra.Command{
+	Use:     "encrypt [value]",
+	Example: "van conf encrypt 'root:123456@tcp(127.0.0.1:3306)/db'",
+	Short:   "Encrypt a value, read from stdin if omitted.",
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := loadKey()
+		if err != nil {
+			return err
+		}
+		value, err := readValue(cmd, args)
+		if err != nil {
+			return err
+		}
+		enc, err := secret.Encrypt(key, []byte(value))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), enc)
+		return nil
+	},
+}
+
+var decryptCmd = &cobra.Command{
+	Use:     "decrypt [value]",
+	Example: "van conf decrypt 'enc:...'",
+	Short:   "Decrypt a value, read from stdin if omitted.",
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := loadKey()
+		if err != nil {
+			return err
+		}
+		value, err := readValue(cmd, args)
+		if err != nil {
+			return err
+		}
+		plain, err := secret.Decrypt(key, value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(plain))
+		return nil
+	},
+}
+
+func init() {
+	Cmd.PersistentFlags().StringVarP(&keyFlag, "key", "k", "", "base64 or hex encoded key")
+	Cmd.AddCommand(keygenCmd, encryptCmd, decryptCmd)
+}
+
+func loadKey() ([]byte, error) {
+	if keyFlag != "" {
+		return secret.ParseKey(keyFlag)
+	}
+	key, err := secret.KeyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.New("no key, set --key, " + secret.KeyEnv + " or " + secret.KeyFileEnv)
+	}
+	return key, nil
+}
+
+func readValue(cmd *cobra.Command, args []string) (string, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	s, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && s == "" {
+		return "", err
+	}
+	return strings.TrimRight(s, "\r\n"), nil
+}
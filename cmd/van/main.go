@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/apus-run/van/cmd/van/config"
+	"github.com/apus-run/van/cmd/van/internal/conf"
 	"github.com/apus-run/van/cmd/van/internal/new"
 )
 
@@ -28,6 +29,7 @@ var RootCmd = &cobra.Command{
 func init() {
 	// RootCmd.AddCommand(rpc.Cmd)
 	RootCmd.AddCommand(new.Cmd)
+	RootCmd.AddCommand(conf.Cmd)
 	// RootCmd.AddCommand(run.Cmd)
 	// RootCmd.AddCommand(upgrade.Cmd)
 }
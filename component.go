@@ -0,0 +1,155 @@
+package van
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Component is an application dependency with a lifecycle, such as a db pool,
+// a cache, a broker or a background worker.
+//
+// Start must return once the component is ready, long running work should be
+// spawned in its own goroutine and terminated by Stop.
+type Component interface {
+	Name() string
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// ComponentOption is a component registration option.
+type ComponentOption func(c *component)
+
+// DependsOn declares the names of the components c depends on,
+// they are started before and stopped after c.
+func DependsOn(names ...string) ComponentOption {
+	return func(c *component) { c.deps = append(c.deps, names...) }
+}
+
+// StopTimeout with the component stop timeout, it overrides WithStopTimeout.
+func StopTimeout(timeout time.Duration) ComponentOption {
+	return func(c *component) { c.stopTimeout = timeout }
+}
+
+// NewComponent create a Component from start and stop funcs, both may be nil.
+func NewComponent(name string, start, stop func(context.Context) error) Component {
+	return &funcComponent{name: name, start: start, stop: stop}
+}
+
+type funcComponent struct {
+	name  string
+	start func(context.Context) error
+	stop  func(context.Context) error
+}
+
+func (c *funcComponent) Name() string { return c.name }
+
+func (c *funcComponent) Start(ctx context.Context) error {
+	if c.start == nil {
+		return nil
+	}
+	return c.start(ctx)
+}
+
+func (c *funcComponent) Stop(ctx context.Context) error {
+	if c.stop == nil {
+		return nil
+	}
+	return c.stop(ctx)
+}
+
+// component is a registered Component with its dependencies.
+type component struct {
+	Component
+	deps        []string
+	stopTimeout time.Duration
+}
+
+var (
+	ErrComponentDuplicate  = errors.New("van: duplicate component")
+	ErrComponentDependency = errors.New("van: unknown component dependency")
+	ErrComponentCycle      = errors.New("van: component dependency cycle")
+)
+
+// sortComponents returns components in topological order, a component always
+// comes after its dependencies, otherwise registration order is preserved.
+func sortComponents(components []*component) ([]*component, error) {
+	index := make(map[string]int, len(components))
+	for i, c := range components {
+		if _, ok := index[c.Name()]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrComponentDuplicate, c.Name())
+		}
+		index[c.Name()] = i
+	}
+
+	indegree := make([]int, len(components))
+	dependents := make([][]int, len(components))
+	for i, c := range components {
+		for _, dep := range c.deps {
+			j, ok := index[dep]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s depends on %s", ErrComponentDependency, c.Name(), dep)
+			}
+			indegree[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	sorted := make([]*component, 0, len(components))
+	done := make([]bool, len(components))
+	for len(sorted) < len(components) {
+		next := -1
+		for i := range components {
+			if !done[i] && indegree[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var names []string
+			for i, c := range components {
+				if !done[i] {
+					names = append(names, c.Name())
+				}
+			}
+			return nil, fmt.Errorf("%w: %v", ErrComponentCycle, names)
+		}
+		done[next] = true
+		sorted = append(sorted, components[next])
+		for _, i := range dependents[next] {
+			indegree[i]--
+		}
+	}
+	return sorted, nil
+}
+
+// startComponents starts components in order, if one fails the already
+// started ones are stopped in reverse order.
+func (s *Service) startComponents(ctx context.Context, components []*component) error {
+	for i, c := range components {
+		if err := c.Start(ctx); err != nil {
+			err = fmt.Errorf("van: start component %s: %w", c.Name(), err)
+			return errors.Join(err, s.stopComponents(components[:i]))
+		}
+	}
+	return nil
+}
+
+// stopComponents stops components in reverse order, each within its stop timeout.
+func (s *Service) stopComponents(components []*component) error {
+	var errs []error
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		timeout := c.stopTimeout
+		if timeout <= 0 {
+			timeout = s.options.stopTimeout
+		}
+		ctx, cancel := context.WithTimeout(NewContext(s.options.context, s), timeout)
+		if err := c.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("van: stop component %s: %w", c.Name(), err))
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
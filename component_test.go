@@ -0,0 +1,114 @@
+package van
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) component(name string, startErr error) Component {
+	return NewComponent(name, func(context.Context) error {
+		r.add("start " + name)
+		return startErr
+	}, func(context.Context) error {
+		r.add("stop " + name)
+		return nil
+	})
+}
+
+func (r *recorder) add(e string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func TestSortComponents(t *testing.T) {
+	r := &recorder{}
+	comps := []*component{
+		{Component: r.component("worker", nil), deps: []string{"cache", "db"}},
+		{Component: r.component("cache", nil), deps: []string{"db"}},
+		{Component: r.component("db", nil)},
+		{Component: r.component("broker", nil)},
+	}
+	sorted, err := sortComponents(comps)
+	assert.NoError(t, err)
+	var names []string
+	for _, c := range sorted {
+		names = append(names, c.Name())
+	}
+	assert.Equal(t, []string{"db", "cache", "worker", "broker"}, names)
+
+	_, err = sortComponents([]*component{
+		{Component: r.component("a", nil), deps: []string{"b"}},
+		{Component: r.component("b", nil), deps: []string{"a"}},
+	})
+	assert.ErrorIs(t, err, ErrComponentCycle)
+
+	_, err = sortComponents([]*component{
+		{Component: r.component("a", nil), deps: []string{"missing"}},
+	})
+	assert.ErrorIs(t, err, ErrComponentDependency)
+
+	_, err = sortComponents([]*component{
+		{Component: r.component("a", nil)},
+		{Component: r.component("a", nil)},
+	})
+	assert.ErrorIs(t, err, ErrComponentDuplicate)
+}
+
+func TestApp_Components(t *testing.T) {
+	r := &recorder{}
+	app := New(
+		WithComponent(r.component("worker", nil), DependsOn("cache")),
+		WithComponent(r.component("cache", nil), DependsOn("db")),
+		WithComponent(r.component("db", nil), StopTimeout(time.Second)),
+	)
+	time.AfterFunc(100*time.Millisecond, func() {
+		_ = app.Stop()
+	})
+	assert.NoError(t, app.Run())
+	assert.Equal(t, []string{
+		"start db", "start cache", "start worker",
+		"stop worker", "stop cache", "stop db",
+	}, r.events)
+}
+
+func TestApp_ComponentsRollback(t *testing.T) {
+	r := &recorder{}
+	errStart := errors.New("start failed")
+	app := New(
+		WithComponent(r.component("db", nil)),
+		WithComponent(r.component("cache", nil), DependsOn("db")),
+		WithComponent(r.component("worker", errStart), DependsOn("cache")),
+		WithComponent(r.component("never", nil), DependsOn("worker")),
+	)
+	err := app.Run()
+	assert.ErrorIs(t, err, errStart)
+	assert.Equal(t, []string{
+		"start db", "start cache", "start worker",
+		"stop cache", "stop db",
+	}, r.events)
+}
+
+func TestApp_ComponentStopTimeout(t *testing.T) {
+	var deadline time.Duration
+	app := New(
+		WithStopTimeout(time.Second),
+		WithComponent(NewComponent("db", nil, func(ctx context.Context) error {
+			d, _ := ctx.Deadline()
+			deadline = time.Until(d)
+			return nil
+		}), StopTimeout(50*time.Millisecond)),
+	)
+	assert.NoError(t, app.stopComponents(app.options.components))
+	assert.LessOrEqual(t, deadline, 50*time.Millisecond)
+}
@@ -0,0 +1,83 @@
+package env
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/apus-run/van/conf"
+)
+
+var _ conf.Source = (*env)(nil)
+
+// Option is env source option.
+type Option func(o *env)
+
+// WithSeparator set the separator of nested keys, "__" by default,
+// e.g. APP_DB__DSN is the key db.dsn for the prefix APP_.
+func WithSeparator(sep string) Option {
+	return func(o *env) {
+		o.separator = sep
+	}
+}
+
+// WithName set the layer name of the source, "env" by default.
+func WithName(name string) Option {
+	return func(o *env) {
+		o.name = name
+	}
+}
+
+type env struct {
+	prefix    string
+	separator string
+	name      string
+}
+
+// NewSource new an environment variables source, only the variables starting with
+// prefix are loaded, their keys are lower cased with the prefix removed.
+func NewSource(prefix string, opts ...Option) conf.Source {
+	e := &env{prefix: prefix, separator: "__", name: "env"}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+func (e *env) Load() ([]*conf.KV, error) {
+	settings := make(map[string]any)
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(k, e.prefix) {
+			continue
+		}
+		k = strings.TrimLeft(strings.TrimPrefix(k, e.prefix), "_")
+		if k == "" {
+			continue
+		}
+		set(settings, strings.Split(strings.ToLower(k), e.separator), v)
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	return []*conf.KV{{
+		Key:    e.name,
+		Value:  data,
+		Format: "json",
+	}}, nil
+}
+
+// set sets the value at the nested keys of settings.
+func set(settings map[string]any, keys []string, value string) {
+	for _, k := range keys[:len(keys)-1] {
+		sub, ok := settings[k].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			settings[k] = sub
+		}
+		settings = sub
+	}
+	settings[keys[len(keys)-1]] = value
+}
@@ -0,0 +1,87 @@
+package flag
+
+import (
+	"encoding/json"
+	stdflag "flag"
+	"strings"
+
+	"github.com/apus-run/van/conf"
+)
+
+var _ conf.Source = (*flags)(nil)
+
+// Option is flag source option.
+type Option func(o *flags)
+
+// WithDefaults load the flags which are not set with their default value,
+// so they override the earlier layers too.
+func WithDefaults() Option {
+	return func(o *flags) {
+		o.defaults = true
+	}
+}
+
+// WithName set the layer name of the source, "flags" by default.
+func WithName(name string) Option {
+	return func(o *flags) {
+		o.name = name
+	}
+}
+
+type flags struct {
+	fs       *stdflag.FlagSet
+	defaults bool
+	name     string
+}
+
+// NewSource new a command-line flags source, the flags must be parsed before
+// the source is loaded. A flag name such as db.dsn is the nested key db.dsn,
+// only the flags set are loaded by default.
+func NewSource(fs *stdflag.FlagSet, opts ...Option) conf.Source {
+	if fs == nil {
+		fs = stdflag.CommandLine
+	}
+	f := &flags{fs: fs, name: "flags"}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+func (f *flags) Load() ([]*conf.KV, error) {
+	settings := make(map[string]any)
+	visit := f.fs.Visit
+	if f.defaults {
+		visit = f.fs.VisitAll
+	}
+	visit(func(fl *stdflag.Flag) {
+		var v any = fl.Value.String()
+		if g, ok := fl.Value.(stdflag.Getter); ok {
+			v = g.Get()
+		}
+		set(settings, strings.Split(fl.Name, "."), v)
+	})
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	return []*conf.KV{{
+		Key:    f.name,
+		Value:  data,
+		Format: "json",
+	}}, nil
+}
+
+// set sets the value at the nested keys of settings.
+func set(settings map[string]any, keys []string, value any) {
+	for _, k := range keys[:len(keys)-1] {
+		sub, ok := settings[k].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			settings[k] = sub
+		}
+		settings = sub
+	}
+	settings[keys[len(keys)-1]] = value
+}
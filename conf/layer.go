@@ -0,0 +1,150 @@
+package conf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/apus-run/van/conf/secret"
+)
+
+// Redacted replaces the secret values of dumped configurations.
+const Redacted = "******"
+
+// layer is a loaded KV of a source.
+type layer struct {
+	name    string
+	v       *viper.Viper
+	secrets map[string]struct{}
+}
+
+// resolve resolves the secrets of the layer and returns their keys. The resolved values
+// are merged into the config of the viper, so a re-read file replaces them.
+func (l *layer) resolve(r *secret.Resolver) (map[string]struct{}, error) {
+	secrets := make(map[string]struct{})
+	resolved := make(map[string]any)
+	for _, key := range l.v.AllKeys() {
+		s, ok := l.v.Get(key).(string)
+		if !ok {
+			continue
+		}
+		v, isSecret, err := r.Resolve(s)
+		if err != nil {
+			return nil, fmt.Errorf("conf %s: resolve %s: %w", l.name, key, err)
+		}
+		if !isSecret {
+			continue
+		}
+		secrets[key] = struct{}{}
+		setNested(resolved, strings.Split(key, "."), v)
+	}
+	if len(resolved) > 0 {
+		if err := l.v.MergeConfigMap(resolved); err != nil {
+			return nil, err
+		}
+	}
+	return secrets, nil
+}
+
+func setNested(m map[string]any, keys []string, value any) {
+	for _, k := range keys[:len(keys)-1] {
+		sub, ok := m[k].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			m[k] = sub
+		}
+		m = sub
+	}
+	m[keys[len(keys)-1]] = value
+}
+
+// merged is the layered view of all sources.
+type merged struct {
+	v      *viper.Viper
+	origin map[string]string
+	secret map[string]bool
+}
+
+// Entry is a key of the merged configuration with the layer it comes from.
+type Entry struct {
+	Key string
+	// Value is the value of the key, Redacted for a secret.
+	Value any
+	// Source is the name of the layer setting the key, e.g. "dev" for dev.yaml or "env".
+	Source string
+	// Secret reports whether the value is a resolved secret.
+	Secret bool
+}
+
+func (e Entry) String() string {
+	return fmt.Sprintf("%s=%v (%s)", e.Key, e.Value, e.Source)
+}
+
+// merge rebuilds the merged view, layers are merged in the order of the sources and
+// of their KVs, so a later layer overrides the keys of the earlier ones.
+func (c *Config) merge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := &merged{v: viper.New(), origin: make(map[string]string), secret: make(map[string]bool)}
+	for _, layers := range c.layers {
+		for _, l := range layers {
+			if err := m.v.MergeConfigMap(l.v.AllSettings()); err != nil {
+				continue
+			}
+			for _, key := range l.v.AllKeys() {
+				_, isSecret := l.secrets[key]
+				m.origin[key], m.secret[key] = l.name, isSecret
+			}
+		}
+	}
+	c.merged.Store(m)
+}
+
+// Merged returns the merged configuration of all sources, it must not be modified.
+// Its secret values are decrypted.
+func (c *Config) Merged() *viper.Viper {
+	if m := c.merged.Load(); m != nil {
+		return m.v
+	}
+	return viper.New()
+}
+
+// Unmarshal unmarshals the merged configuration into obj, with the secret values decrypted.
+func (c *Config) Unmarshal(obj any) error {
+	return c.Merged().Unmarshal(obj)
+}
+
+// Source returns the name of the layer setting the key in the merged configuration.
+func (c *Config) Source(key string) (string, bool) {
+	m := c.merged.Load()
+	if m == nil {
+		return "", false
+	}
+	name, ok := m.origin[key]
+	return name, ok
+}
+
+// Dump returns the effective merged configuration with the provenance of each key,
+// sorted by key. The secret values are redacted, so that the dump can be logged or
+// displayed, it is the only accessor not returning them decrypted.
+func (c *Config) Dump() []Entry {
+	m := c.merged.Load()
+	if m == nil {
+		return nil
+	}
+	entries := make([]Entry, 0, len(m.origin))
+	for key, name := range m.origin {
+		e := Entry{Key: key, Value: m.v.Get(key), Source: name}
+		if m.secret[key] {
+			e.Value, e.Secret = Redacted, true
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
@@ -0,0 +1,102 @@
+package conf_test
+
+import (
+	stdflag "flag"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/conf"
+	"github.com/apus-run/van/conf/env"
+	"github.com/apus-run/van/conf/file"
+	"github.com/apus-run/van/conf/flag"
+	"github.com/apus-run/van/conf/remote"
+)
+
+func TestLayers(t *testing.T) {
+	var body atomic.Value
+	body.Store("redis:\n  addr: remote:6379\n  password: secret\n")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write([]byte(body.Load().(string)))
+	}))
+	defer ts.Close()
+
+	t.Setenv("VAN_TEST_REDIS__DB", "2")
+	t.Setenv("VAN_TEST_LOGGER__LOG_LEVEL", "info")
+
+	fs := stdflag.NewFlagSet("test", stdflag.ContinueOnError)
+	fs.String("redis.addr", "flag-default:6379", "")
+	fs.String("logger.mode", "flag-default", "")
+	require.NoError(t, fs.Parse([]string{"-logger.mode=prod"}))
+
+	c := conf.New([]conf.Source{
+		file.NewSource("testdata/dev.yaml"),
+		remote.NewSource(ts.URL+"/config", remote.WithName("remote"), remote.WithInterval(20*time.Millisecond)),
+		env.NewSource("VAN_TEST_"),
+		flag.NewSource(fs),
+	})
+	require.NoError(t, c.Load())
+	defer c.Close()
+
+	m := c.Merged()
+	assert.Equal(t, "remote:6379", m.GetString("redis.addr"))
+	assert.Equal(t, "secret", m.GetString("redis.password"))
+	assert.Equal(t, 2, m.GetInt("redis.db"))
+	assert.Equal(t, "info", m.GetString("logger.log_level"))
+	assert.Equal(t, "prod", m.GetString("logger.mode"))
+	assert.Equal(t, "console", m.GetString("logger.encoding"))
+
+	for key, want := range map[string]string{
+		"redis.addr":       "remote",
+		"redis.db":         "env",
+		"logger.mode":      "flags",
+		"logger.encoding":  "dev",
+		"logger.log_level": "env",
+	} {
+		got, ok := c.Source(key)
+		assert.True(t, ok, key)
+		assert.Equal(t, want, got, key)
+	}
+
+	var found bool
+	for _, e := range c.Dump() {
+		if e.Key == "redis.password" {
+			found = true
+			assert.Equal(t, "secret", e.Value)
+			assert.Equal(t, "remote", e.Source)
+		}
+	}
+	assert.True(t, found)
+
+	var cfg struct {
+		Redis struct {
+			Addr string
+			DB   int
+		}
+	}
+	require.NoError(t, c.Unmarshal(&cfg))
+	assert.Equal(t, "remote:6379", cfg.Redis.Addr)
+	assert.Equal(t, 2, cfg.Redis.DB)
+
+	changed := make(chan struct{}, 1)
+	c.WatchFile("remote", func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	body.Store("redis:\n  addr: remote:6380\n")
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("remote change not notified")
+	}
+	assert.Equal(t, "remote:6380", c.Merged().GetString("redis.addr"))
+	assert.Equal(t, "123456", c.Merged().GetString("redis.password"))
+}
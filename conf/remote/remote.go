@@ -0,0 +1,200 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apus-run/van/conf"
+)
+
+var (
+	_ conf.Source  = (*remote)(nil)
+	_ conf.Watcher = (*remote)(nil)
+)
+
+// Option is remote source option.
+type Option func(o *remote)
+
+// WithClient set the HTTP client, http.DefaultClient by default.
+func WithClient(c *http.Client) Option {
+	return func(o *remote) {
+		o.client = c
+	}
+}
+
+// WithFormat set the format of the content, e.g. yaml, json, toml or properties,
+// it is taken from the URL extension or the content type by default.
+func WithFormat(format string) Option {
+	return func(o *remote) {
+		o.format = format
+	}
+}
+
+// WithName set the layer name of the source, the URL base name by default.
+func WithName(name string) Option {
+	return func(o *remote) {
+		o.name = name
+	}
+}
+
+// WithHeader set a header of the requests, e.g. an authorization token.
+func WithHeader(key, value string) Option {
+	return func(o *remote) {
+		o.header.Add(key, value)
+	}
+}
+
+// WithInterval set the polling interval of Watch, 30s by default.
+func WithInterval(d time.Duration) Option {
+	return func(o *remote) {
+		o.interval = d
+	}
+}
+
+// WithTimeout set the timeout of a request, 5s by default.
+func WithTimeout(d time.Duration) Option {
+	return func(o *remote) {
+		o.timeout = d
+	}
+}
+
+type remote struct {
+	url      string
+	client   *http.Client
+	format   string
+	name     string
+	header   http.Header
+	interval time.Duration
+	timeout  time.Duration
+
+	mu   sync.Mutex
+	last []byte
+}
+
+// NewSource new a remote source polling the configuration over HTTP.
+func NewSource(rawURL string, opts ...Option) conf.Source {
+	r := &remote{
+		url:      rawURL,
+		client:   http.DefaultClient,
+		header:   make(http.Header),
+		interval: 30 * time.Second,
+		timeout:  5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		base := path.Base(u.Path)
+		if r.name == "" && base != "/" && base != "." {
+			r.name = base
+		}
+		if r.format == "" {
+			r.format = strings.TrimPrefix(path.Ext(u.Path), ".")
+		}
+	}
+	if r.name == "" {
+		r.name = "remote"
+	}
+	return r
+}
+
+func (r *remote) fetch() ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	for k, vs := range r.header {
+		req.Header[k] = vs
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("remote conf %s: unexpected status %s", r.url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+func (r *remote) Load() ([]*conf.KV, error) {
+	data, contentType, err := r.fetch()
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.last = data
+	r.mu.Unlock()
+
+	format := r.format
+	if format == "" {
+		format = formatOf(contentType)
+	}
+	return []*conf.KV{{
+		Key:    r.name,
+		Value:  data,
+		Format: format,
+	}}, nil
+}
+
+// Watch polls the URL every interval and calls fn when the content changes.
+func (r *remote) Watch(fn func()) (stop func()) {
+	ticker := time.NewTicker(r.interval)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			data, _, err := r.fetch()
+			if err != nil {
+				log.Printf("poll remote conf %s error: %v\n", r.url, err)
+				continue
+			}
+			r.mu.Lock()
+			changed := !bytes.Equal(r.last, data)
+			r.mu.Unlock()
+			if changed {
+				fn()
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// formatOf returns the format of a content type, e.g. yaml for application/yaml.
+func formatOf(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case strings.HasSuffix(mt, "json"):
+		return "json"
+	case strings.HasSuffix(mt, "yaml"), strings.HasSuffix(mt, "yml"):
+		return "yaml"
+	case strings.HasSuffix(mt, "toml"):
+		return "toml"
+	}
+	return ""
+}
@@ -0,0 +1,402 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+
+	"github.com/apus-run/van/pkg/field"
+	"github.com/apus-run/van/validator"
+)
+
+// ScanOption is Scan option.
+type ScanOption func(o *scanOptions)
+
+type scanOptions struct {
+	key       string
+	strict    bool
+	defaults  bool
+	validator *validator.Validator
+	// keys of the file resolved from secrets
+	secrets map[string]struct{}
+}
+
+// WithStrict fail on the keys of the file which are not fields of the struct.
+func WithStrict() ScanOption {
+	return func(o *scanOptions) {
+		o.strict = true
+	}
+}
+
+// WithDefaults apply the default:"..." struct tags to the fields the file doesn't set.
+func WithDefaults() ScanOption {
+	return func(o *scanOptions) {
+		o.defaults = true
+	}
+}
+
+// WithScanKey scan a key of the file instead of the whole file.
+func WithScanKey(key string) ScanOption {
+	return func(o *scanOptions) {
+		o.key = key
+	}
+}
+
+// WithScanValidator validate the scanned struct with v, e.g. validator.V(), it isn't validated by default.
+func WithScanValidator(v *validator.Validator) ScanOption {
+	return func(o *scanOptions) {
+		o.validator = v
+	}
+}
+
+// Problem is a problem of a configuration key.
+type Problem struct {
+	// Path is the key path, e.g. db.dsn or hosts[0], nil if unknown.
+	Path    *field.Path
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Path == nil {
+		return p.Message
+	}
+	return p.Path.String() + ": " + p.Message
+}
+
+// SchemaError reports every problem found scanning a file into a struct.
+type SchemaError struct {
+	File     string
+	Problems []Problem
+}
+
+func (e *SchemaError) Error() string {
+	msgs := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		msgs = append(msgs, p.String())
+	}
+	return fmt.Sprintf("conf %s: %s", e.File, strings.Join(msgs, "; "))
+}
+
+// scan applies the defaults of obj, unmarshals v into obj and validates it,
+// it reports every problem found as a *SchemaError.
+func scan(file string, v *viper.Viper, obj any, o *scanOptions) error {
+	var root *field.Path
+	if o.key != "" {
+		root = field.NewPath(o.key)
+	}
+
+	var problems []Problem
+	if o.defaults {
+		problems = append(problems, applyDefaults(root, reflect.ValueOf(obj))...)
+	}
+
+	var err error
+	if o.key != "" {
+		err = v.UnmarshalKey(o.key, obj)
+	} else {
+		err = v.Unmarshal(obj)
+	}
+	if err != nil {
+		var me *mapstructure.Error
+		if !errors.As(err, &me) {
+			return err
+		}
+		for _, msg := range me.Errors {
+			problems = append(problems, decodeProblem(root, msg, o.isSecret))
+		}
+	}
+
+	if o.strict {
+		var keys []string
+		for _, key := range v.AllKeys() {
+			if o.key == "" {
+				keys = append(keys, key)
+			} else if k, ok := strings.CutPrefix(key, o.key+"."); ok {
+				keys = append(keys, k)
+			}
+		}
+		for _, key := range unknownKeys(reflect.TypeOf(obj), keys) {
+			problems = append(problems, Problem{Path: child(root, key), Message: "unknown key"})
+		}
+	}
+
+	if o.validator != nil {
+		fields, err := o.validator.ValidateFields(obj)
+		if err != nil {
+			return err
+		}
+		t := reflect.TypeOf(obj)
+		for _, f := range fields {
+			problems = append(problems, Problem{Path: keyPath(root, t, f.Namespace), Message: f.Message})
+		}
+	}
+
+	if len(problems) > 0 {
+		return &SchemaError{File: file, Problems: problems}
+	}
+	return nil
+}
+
+// child returns the path of the dotted key under root.
+func child(root *field.Path, key string) *field.Path {
+	names := strings.Split(key, ".")
+	if root == nil {
+		return field.NewPath(names[0], names[1:]...)
+	}
+	return root.Child(names[0], names[1:]...)
+}
+
+var decodeName = regexp.MustCompile(`'([^']+)'`)
+
+// isSecret reports whether the key, relative to the scanned key, was resolved from a secret.
+func (o *scanOptions) isSecret(key string) bool {
+	if o.key != "" {
+		key = o.key + "." + key
+	}
+	_, ok := o.secrets[strings.ToLower(key)]
+	return ok
+}
+
+// decodeProblem parses a mapstructure error such as "cannot parse 'db.port' as int: ...",
+// the first quoted name is the key. The error quotes the value, so it is replaced for a
+// secret key.
+func decodeProblem(root *field.Path, msg string, isSecret func(key string) bool) Problem {
+	m := decodeName.FindStringSubmatch(msg)
+	if m == nil {
+		return Problem{Path: root, Message: msg}
+	}
+	if isSecret(m[1]) {
+		msg = "cannot decode the secret value"
+	}
+	return Problem{Path: child(root, m[1]), Message: msg}
+}
+
+// unknownKeys returns the sorted keys, or their first unknown parent, which are not
+// fields of t. Maps and interfaces accept any key.
+func unknownKeys(t reflect.Type, keys []string) []string {
+	seen := make(map[string]bool)
+	var unknown []string
+	for _, key := range keys {
+		names := strings.Split(key, ".")
+		ft := t
+		for i, name := range names {
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() != reflect.Struct {
+				break
+			}
+			f, ok := findField(ft, name)
+			if !ok {
+				k := strings.Join(names[:i+1], ".")
+				if !seen[k] {
+					seen[k] = true
+					unknown = append(unknown, k)
+				}
+				break
+			}
+			ft = f.Type
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// findField finds the field of the struct t with the key name, including the squashed ones.
+func findField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		n, squash := keyName(f)
+		if squash {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if sf, ok := findField(ft, name); ok {
+					return sf, true
+				}
+			}
+			continue
+		}
+		if n == strings.ToLower(name) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// keyName returns the configuration key of a struct field, and whether it is squashed.
+func keyName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("mapstructure")
+	name, opts, _ := strings.Cut(tag, ",")
+	if strings.Contains(opts, "squash") {
+		return "", true
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return strings.ToLower(name), false
+}
+
+var indexPattern = regexp.MustCompile(`^(\w+)((?:\[[^\]]*\])*)$`)
+
+// keyPath converts the Go namespace of a field, e.g. DB.Hosts[0], into its key path.
+func keyPath(root *field.Path, t reflect.Type, namespace string) *field.Path {
+	p := root
+	for _, part := range strings.Split(namespace, ".") {
+		m := indexPattern.FindStringSubmatch(part)
+		if m == nil {
+			return child(p, strings.ToLower(namespace))
+		}
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		name := strings.ToLower(m[1])
+		if t.Kind() == reflect.Struct {
+			if f, ok := t.FieldByName(m[1]); ok {
+				t = f.Type
+				var squash bool
+				if name, squash = keyName(f); squash {
+					name = ""
+				}
+			}
+		}
+		if name != "" {
+			if p == nil {
+				p = field.NewPath(name)
+			} else {
+				p = p.Child(name)
+			}
+		}
+		for _, idx := range strings.Split(strings.Trim(m[2], "[]"), "][") {
+			if idx == "" {
+				continue
+			}
+			if i, err := strconv.Atoi(idx); err == nil {
+				p = p.Index(i)
+			} else {
+				p = p.Key(idx)
+			}
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+				t = t.Elem()
+			}
+		}
+	}
+	return p
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyDefaults sets the zero fields of the struct v to their default:"..." tag,
+// slices take comma separated values.
+func applyDefaults(path *field.Path, v reflect.Value) []Problem {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var problems []Problem
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		p := path
+		if name, squash := keyName(f); !squash {
+			if p == nil {
+				p = field.NewPath(name)
+			} else {
+				p = p.Child(name)
+			}
+		}
+
+		if def, ok := f.Tag.Lookup("default"); ok && fv.IsZero() {
+			if err := setDefault(fv, def); err != nil {
+				problems = append(problems, Problem{Path: p, Message: fmt.Sprintf("invalid default %q: %v", def, err)})
+			}
+			continue
+		}
+		problems = append(problems, applyDefaults(p, fv)...)
+	}
+	return problems
+}
+
+func setDefault(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		parts := strings.Split(s, ",")
+		sl := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setDefault(sl.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		v.Set(sl)
+	case reflect.Ptr:
+		e := reflect.New(v.Type().Elem())
+		if err := setDefault(e.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(e)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package conf_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/conf"
+	"github.com/apus-run/van/conf/file"
+	"github.com/apus-run/van/validator"
+)
+
+func TestScan(t *testing.T) {
+	type Redis struct {
+		Addr    string        `mapstructure:"addr" validate:"required"`
+		DB      int           `mapstructure:"db" default:"1"`
+		Timeout time.Duration `mapstructure:"timeout" default:"3s"`
+	}
+	type Config struct {
+		Redis  Redis    `mapstructure:"redis"`
+		Hosts  []string `mapstructure:"hosts" default:"example.com, api.example.com" validate:"dive,hostname"`
+		Logger struct {
+			Level string `mapstructure:"log_level" validate:"oneof=debug info"`
+		} `mapstructure:"logger"`
+	}
+
+	dir := t.TempDir()
+	write := func(name, s string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(s), 0o644))
+	}
+	write("good.yaml", "redis:\n  addr: localhost:6379\nlogger:\n  log_level: info\n")
+	write("bad.yaml", "redis:\n  adr: localhost:6379\n  db: x\nhosts: [example.com, \"bad host\"]\nlogger:\n  log_level: trace\n")
+
+	c := conf.New([]conf.Source{file.NewSource(dir)})
+	require.NoError(t, c.Load())
+
+	// 默认不应用 default 标签, 也不校验
+	var plain Config
+	require.NoError(t, c.Scan("good", &plain))
+	assert.Zero(t, plain.Redis.DB)
+	assert.Empty(t, plain.Hosts)
+
+	var cfg Config
+	require.NoError(t, c.Scan("good", &cfg, conf.WithStrict(), conf.WithDefaults(), conf.WithScanValidator(validator.V())))
+	assert.Equal(t, "localhost:6379", cfg.Redis.Addr)
+	assert.Equal(t, 1, cfg.Redis.DB)
+	assert.Equal(t, 3*time.Second, cfg.Redis.Timeout)
+	assert.Equal(t, []string{"example.com", "api.example.com"}, cfg.Hosts)
+
+	var redis Redis
+	require.NoError(t, c.Scan("good", &redis, conf.WithScanKey("redis"), conf.WithDefaults()))
+	assert.Equal(t, 1, redis.DB)
+
+	err := c.Scan("bad", &Config{}, conf.WithStrict(), conf.WithScanValidator(validator.V()))
+	var se *conf.SchemaError
+	require.True(t, errors.As(err, &se), err)
+	assert.Equal(t, "bad", se.File)
+
+	paths := make(map[string]bool)
+	for _, p := range se.Problems {
+		require.NotNil(t, p.Path, p.Message)
+		paths[p.Path.String()] = true
+	}
+	for _, want := range []string{"redis.db", "redis.adr", "redis.addr", "hosts[1]", "logger.log_level"} {
+		assert.True(t, paths[want], "missing problem for %s in %v", want, se)
+	}
+
+	assert.NoError(t, c.Scan("bad", &struct{}{}))
+	assert.ErrorIs(t, c.Scan("missing", &cfg), conf.ErrFileNotLoaded)
+}
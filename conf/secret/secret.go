@@ -0,0 +1,215 @@
+// Package secret resolves the secrets of configuration values: ${env:NAME} and
+// ${file:/path} placeholders, and enc: values encrypted with AES-GCM.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Prefix is the prefix of encrypted values.
+	Prefix = "enc:"
+	// KeyEnv is the environment variable holding the key, base64 or hex encoded.
+	KeyEnv = "VAN_CONF_KEY"
+	// KeyFileEnv is the environment variable holding the path of the key file.
+	KeyFileEnv = "VAN_CONF_KEY_FILE"
+	// KeySize is the size of an AES-256 key.
+	KeySize = 32
+)
+
+var (
+	// ErrNoKey is returned when decrypting a value without a key.
+	ErrNoKey = errors.New("secret: no key to decrypt value")
+	// ErrInvalidKey is returned for a key which is not 16, 24 or 32 bytes.
+	ErrInvalidKey = errors.New("secret: invalid key size")
+	// ErrInvalidValue is returned for a malformed encrypted value.
+	ErrInvalidValue = errors.New("secret: invalid encrypted value")
+
+	placeholder = regexp.MustCompile(`\$\{(env|file):([^}]+)\}`)
+)
+
+// GenerateKey returns a random AES-256 key.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// EncodeKey encodes a key as base64.
+func EncodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// ParseKey parses a base64 or hex encoded key.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		if key, err = hex.DecodeString(s); err != nil {
+			return nil, ErrInvalidKey
+		}
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	}
+	return nil, ErrInvalidKey
+}
+
+// KeyFromEnv loads the key from VAN_CONF_KEY, or from the file of VAN_CONF_KEY_FILE,
+// it returns nil if neither is set.
+func KeyFromEnv() ([]byte, error) {
+	if s := os.Getenv(KeyEnv); s != "" {
+		return ParseKey(s)
+	}
+	if path := os.Getenv(KeyFileEnv); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return ParseKey(string(b))
+	}
+	return nil, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt encrypts plaintext with AES-GCM, it returns "enc:" followed by the
+// base64 encoded nonce and ciphertext.
+func Encrypt(key, plaintext []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return Prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt.
+func Decrypt(key []byte, value string) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, ErrNoKey
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, Prefix))
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return nil, ErrInvalidValue
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrInvalidValue
+	}
+	return plaintext, nil
+}
+
+// IsEncrypted reports whether the value is encrypted.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Option is resolver option.
+type Option func(r *Resolver)
+
+// WithKey set the key decrypting the enc: values.
+func WithKey(key []byte) Option {
+	return func(r *Resolver) {
+		r.key = key
+	}
+}
+
+// WithLookupEnv set the lookup of ${env:NAME} placeholders, os.LookupEnv by default.
+func WithLookupEnv(fn func(string) (string, bool)) Option {
+	return func(r *Resolver) {
+		r.lookupEnv = fn
+	}
+}
+
+// WithReadFile set the reader of ${file:/path} placeholders, os.ReadFile by default.
+func WithReadFile(fn func(string) ([]byte, error)) Option {
+	return func(r *Resolver) {
+		r.readFile = fn
+	}
+}
+
+// Resolver resolves the secrets of configuration values.
+type Resolver struct {
+	key       []byte
+	lookupEnv func(string) (string, bool)
+	readFile  func(string) ([]byte, error)
+}
+
+// NewResolver new a resolver.
+func NewResolver(opts ...Option) *Resolver {
+	r := &Resolver{
+		lookupEnv: os.LookupEnv,
+		readFile:  os.ReadFile,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve decrypts an enc: value, or replaces the ${env:NAME} and ${file:/path}
+// placeholders of value, the trailing newline of a file is trimmed.
+// It reports whether value holds a secret.
+func (r *Resolver) Resolve(value string) (string, bool, error) {
+	if IsEncrypted(value) {
+		b, err := Decrypt(r.key, value)
+		if err != nil {
+			return "", true, err
+		}
+		return string(b), true, nil
+	}
+	if !strings.Contains(value, "${") {
+		return value, false, nil
+	}
+
+	var errs []error
+	resolved := placeholder.ReplaceAllStringFunc(value, func(m string) string {
+		sub := placeholder.FindStringSubmatch(m)
+		switch sub[1] {
+		case "env":
+			v, ok := r.lookupEnv(sub[2])
+			if !ok {
+				errs = append(errs, fmt.Errorf("secret: env %s not set", sub[2]))
+			}
+			return v
+		default:
+			b, err := r.readFile(sub[2])
+			if err != nil {
+				errs = append(errs, fmt.Errorf("secret: %w", err))
+			}
+			return strings.TrimRight(string(b), "\r\n")
+		}
+	})
+	if len(errs) > 0 {
+		return "", true, errors.Join(errs...)
+	}
+	return resolved, resolved != value, nil
+}
@@ -0,0 +1,74 @@
+package secret
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncrypt(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	enc, err := Encrypt(key, []byte("root:123456@tcp(localhost:3306)/db"))
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(enc))
+
+	plain, err := Decrypt(key, enc)
+	require.NoError(t, err)
+	assert.Equal(t, "root:123456@tcp(localhost:3306)/db", string(plain))
+
+	other, err := GenerateKey()
+	require.NoError(t, err)
+	_, err = Decrypt(other, enc)
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	_, err = Decrypt(nil, enc)
+	assert.ErrorIs(t, err, ErrNoKey)
+
+	parsed, err := ParseKey(EncodeKey(key))
+	require.NoError(t, err)
+	assert.Equal(t, key, parsed)
+	_, err = ParseKey("short")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestResolve(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	enc, err := Encrypt(key, []byte("secret"))
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(path, []byte("p@ss\n"), 0o600))
+
+	r := NewResolver(WithKey(key), WithLookupEnv(func(name string) (string, bool) {
+		if name == "DB_USER" {
+			return "root", true
+		}
+		return "", false
+	}))
+
+	tests := []struct {
+		value    string
+		want     string
+		isSecret bool
+		err      bool
+	}{
+		{"plain", "plain", false, false},
+		{enc, "secret", true, false},
+		{"${env:DB_USER}:${file:" + path + "}@tcp(db)", "root:p@ss@tcp(db)", true, false},
+		{"${env:MISSING}", "", true, true},
+		{"${file:/not/exist}", "", true, true},
+	}
+	for _, tt := range tests {
+		got, isSecret, err := r.Resolve(tt.value)
+		assert.Equal(t, tt.err, err != nil, tt.value)
+		assert.Equal(t, tt.isSecret, isSecret, tt.value)
+		if !tt.err {
+			assert.Equal(t, tt.want, got, tt.value)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package conf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/conf"
+	"github.com/apus-run/van/conf/file"
+	"github.com/apus-run/van/conf/secret"
+)
+
+func TestSecrets(t *testing.T) {
+	key, err := secret.GenerateKey()
+	require.NoError(t, err)
+	enc, err := secret.Encrypt(key, []byte("root:123456@tcp(localhost:3306)/test_db"))
+	require.NoError(t, err)
+
+	t.Setenv("VAN_TEST_REDIS_PASSWORD", "r3dis")
+	path := filepath.Join(t.TempDir(), "secret.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(
+		"db:\n  dsn: \""+enc+"\"\nredis:\n  addr: localhost:6379\n  password: ${env:VAN_TEST_REDIS_PASSWORD}\n",
+	), 0o644))
+
+	c := conf.New([]conf.Source{file.NewSource(path)}, conf.WithResolver(secret.NewResolver(secret.WithKey(key))))
+	require.NoError(t, c.Load())
+
+	assert.Equal(t, "root:123456@tcp(localhost:3306)/test_db", c.File("secret").GetString("db.dsn"))
+	assert.Equal(t, "r3dis", c.Merged().GetString("redis.password"))
+
+	for _, e := range c.Dump() {
+		switch e.Key {
+		case "db.dsn", "redis.password":
+			assert.True(t, e.Secret, e.Key)
+			assert.Equal(t, conf.Redacted, e.Value, e.Key)
+			assert.NotContains(t, e.String(), "r3dis")
+		default:
+			assert.False(t, e.Secret, e.Key)
+		}
+	}
+
+	c = conf.New([]conf.Source{file.NewSource(path)}, conf.WithResolver(secret.NewResolver()))
+	assert.ErrorIs(t, c.Load(), secret.ErrNoKey)
+}
+
+func TestSecrets_DecodeError(t *testing.T) {
+	t.Setenv("VAN_TEST_REDIS_DB", "s3cret")
+	path := filepath.Join(t.TempDir(), "secret.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("redis:\n  db: ${env:VAN_TEST_REDIS_DB}\n  pool: x\n"), 0o644))
+
+	c := conf.New([]conf.Source{file.NewSource(path)}, conf.WithResolver(secret.NewResolver()))
+	require.NoError(t, c.Load())
+
+	type Redis struct {
+		DB   int `mapstructure:"db"`
+		Pool int `mapstructure:"pool"`
+	}
+	var cfg struct {
+		Redis Redis `mapstructure:"redis"`
+	}
+	// 错误信息不包含 secret 的值, 其他 key 的错误不变
+	err := c.Scan("secret", &cfg)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "s3cret")
+	assert.Contains(t, err.Error(), "redis.db: cannot decode the secret value")
+	assert.Contains(t, err.Error(), `"x"`)
+
+	_, err = conf.Bind[Redis](c, "secret", conf.WithKey("redis"))
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "s3cret")
+}
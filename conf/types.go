@@ -21,10 +21,17 @@ type Source interface {
 	Load() ([]*KV, error)
 }
 
+// Watcher is implemented by sources notifying their changes, e.g. polled remote sources.
+type Watcher interface {
+	// Watch calls fn whenever the source changes, until stop is called.
+	Watch(fn func()) (stop func())
+}
+
 type Conf interface {
 	File(filename string) *viper.Viper
-	Scan(filename string, obj any) error
+	Scan(filename string, obj any, opts ...ScanOption) error
 	Get(filename string, key string) any
 	Load() error
 	Watch(fn func())
+	WatchFile(filename string, fn func())
 }
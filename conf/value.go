@@ -0,0 +1,166 @@
+package conf
+
+import (
+	"errors"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/apus-run/van/pkg/diff"
+	"github.com/apus-run/van/validator"
+)
+
+// ErrFileNotLoaded is returned when binding a file which is not loaded.
+var ErrFileNotLoaded = errors.New("conf file not loaded")
+
+// Change is a configuration change notified to the subscribers of a Value.
+type Change[T any] struct {
+	Old T
+	New T
+	// Keys are the paths of the changed fields, e.g. "DB.DSN".
+	Keys []string
+}
+
+// BindOption is Value option.
+type BindOption func(o *bindOptions)
+
+type bindOptions struct {
+	key       string
+	validator *validator.Validator
+	onError   func(error)
+}
+
+// WithKey bind the value to a key of the file instead of the whole file.
+func WithKey(key string) BindOption {
+	return func(o *bindOptions) {
+		o.key = key
+	}
+}
+
+// WithValidator set the validator of the reloaded values, validator.V() by default.
+func WithValidator(v *validator.Validator) BindOption {
+	return func(o *bindOptions) {
+		o.validator = v
+	}
+}
+
+// WithErrorHandler set the handler of reload errors, they are logged by default.
+func WithErrorHandler(fn func(error)) BindOption {
+	return func(o *bindOptions) {
+		o.onError = fn
+	}
+}
+
+// Value is a typed binding of a configuration file, it is re-unmarshaled and
+// validated when the file changes. An invalid change is rejected and the previous
+// value kept, a valid one is swapped atomically and notified to the subscribers.
+type Value[T any] struct {
+	c        *Config
+	filename string
+	options  *bindOptions
+
+	v atomic.Pointer[T]
+
+	mu   sync.Mutex
+	subs map[int]func(Change[T])
+	next int
+}
+
+// Bind binds T to the loaded file and watches it for changes.
+func Bind[T any](c *Config, filename string, opts ...BindOption) (*Value[T], error) {
+	o := &bindOptions{
+		validator: validator.V(),
+		onError: func(err error) {
+			log.Printf("reload conf file %s error: %v\n", filename, err)
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if c.File(filename) == nil {
+		return nil, ErrFileNotLoaded
+	}
+
+	v := &Value[T]{
+		c:        c,
+		filename: filename,
+		options:  o,
+		subs:     make(map[int]func(Change[T])),
+	}
+	t, err := v.load()
+	if err != nil {
+		return nil, err
+	}
+	v.v.Store(t)
+
+	c.WatchFile(filename, func() {
+		if err := v.Reload(); err != nil {
+			o.onError(err)
+		}
+	})
+	return v, nil
+}
+
+// Get returns the current value.
+func (v *Value[T]) Get() T {
+	return *v.v.Load()
+}
+
+// Subscribe calls fn on every accepted change, until cancel is called.
+func (v *Value[T]) Subscribe(fn func(Change[T])) (cancel func()) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	id := v.next
+	v.next++
+	v.subs[id] = fn
+	return func() {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		delete(v.subs, id)
+	}
+}
+
+// Reload re-unmarshals and validates the file, the current value is kept on error.
+func (v *Value[T]) Reload() error {
+	t, err := v.load()
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	old := v.v.Load()
+	keys := diff.ObjectReflectDiffPaths(*old, *t)
+	if len(keys) == 0 {
+		v.mu.Unlock()
+		return nil
+	}
+	v.v.Store(t)
+	subs := make([]func(Change[T]), 0, len(v.subs))
+	for _, id := range slices.Sorted(maps.Keys(v.subs)) {
+		subs = append(subs, v.subs[id])
+	}
+	v.mu.Unlock()
+
+	// the subscribers are notified without the lock, so that they can cancel,
+	// subscribe or reload, and a slow one doesn't block the reloads
+	change := Change[T]{Old: *old, New: *t, Keys: keys}
+	for _, fn := range subs {
+		fn(change)
+	}
+	return nil
+}
+
+func (v *Value[T]) load() (*T, error) {
+	f := v.c.File(v.filename)
+	if f == nil {
+		return nil, ErrFileNotLoaded
+	}
+	t := new(T)
+	o := &scanOptions{key: v.options.key, defaults: true, validator: v.options.validator, secrets: v.c.secretKeys(v.filename)}
+	if err := scan(v.filename, f, t, o); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
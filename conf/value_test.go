@@ -0,0 +1,110 @@
+package conf_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/conf"
+	"github.com/apus-run/van/conf/file"
+)
+
+func TestBind(t *testing.T) {
+	type Redis struct {
+		Addr string `mapstructure:"addr" validate:"required"`
+		DB   int    `mapstructure:"db"`
+	}
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	write := func(s string) {
+		require.NoError(t, os.WriteFile(path, []byte(s), 0o644))
+	}
+	write("redis:\n  addr: localhost:6379\n  db: 0\n")
+
+	c := conf.New([]conf.Source{file.NewSource(path)})
+	require.NoError(t, c.Load())
+
+	var (
+		mu      sync.Mutex
+		changes []conf.Change[Redis]
+		errs    []error
+	)
+	v, err := conf.Bind[Redis](c, "app", conf.WithKey("redis"), conf.WithErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, Redis{Addr: "localhost:6379"}, v.Get())
+
+	cancel := v.Subscribe(func(c conf.Change[Redis]) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, c)
+	})
+	defer cancel()
+
+	write("redis:\n  addr: localhost:6380\n  db: 0\n")
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(changes) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, "localhost:6379", changes[0].Old.Addr)
+	assert.Equal(t, "localhost:6380", changes[0].New.Addr)
+	assert.Equal(t, []string{"Addr"}, changes[0].Keys)
+	mu.Unlock()
+	assert.Equal(t, "localhost:6380", v.Get().Addr)
+
+	write("redis:\n  addr: \"\"\n  db: 1\n")
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, Redis{Addr: "localhost:6380"}, v.Get())
+
+	_, err = conf.Bind[Redis](c, "missing")
+	assert.ErrorIs(t, err, conf.ErrFileNotLoaded)
+}
+
+func TestValue_SubscriberReenters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("addr: localhost:6379\n"), 0o644))
+	c := conf.New([]conf.Source{file.NewSource(path)})
+	require.NoError(t, c.Load())
+	defer c.Close()
+
+	type App struct {
+		Addr string `mapstructure:"addr"`
+	}
+	v, err := conf.Bind[App](c, "app")
+	require.NoError(t, err)
+
+	var (
+		once   sync.Once
+		done   = make(chan struct{})
+		cancel func()
+	)
+	cancel = v.Subscribe(func(conf.Change[App]) {
+		// a subscriber may cancel itself, subscribe and reload
+		cancel()
+		v.Subscribe(func(conf.Change[App]) {})
+		assert.NoError(t, v.Reload())
+		once.Do(func() { close(done) })
+	})
+
+	require.NoError(t, os.WriteFile(path, []byte("addr: localhost:6380\n"), 0o644))
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscriber not notified")
+	}
+	assert.Equal(t, "localhost:6380", v.Get().Addr)
+}
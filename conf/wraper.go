@@ -1,48 +1,173 @@
 package conf
 
 import (
+	"bytes"
 	"errors"
 	"log"
 	"path"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
+
+	"github.com/apus-run/van/conf/secret"
 )
 
+// Config loads its sources in order, each loaded KV is kept as a named viper.Viper
+// and merged into one layered view, where later layers override earlier ones.
 type Config struct {
 	files  []Source
 	cached *sync.Map
+
+	mu         sync.Mutex
+	watchers   map[string][]func()
+	layers     [][]*layer
+	sourceOnce sync.Once
+	stops      []func()
+
+	resolver *secret.Resolver
+	merged   atomic.Pointer[merged]
+}
+
+// Option is config option.
+type Option func(c *Config)
+
+// WithResolver set the secret resolver, by default it decrypts with the key of
+// VAN_CONF_KEY or VAN_CONF_KEY_FILE.
+func WithResolver(r *secret.Resolver) Option {
+	return func(c *Config) {
+		c.resolver = r
+	}
 }
 
-func New(files []Source) *Config {
-	return &Config{
-		files:  files,
-		cached: &sync.Map{},
+func New(files []Source, opts ...Option) *Config {
+	c := &Config{
+		files:    files,
+		cached:   &sync.Map{},
+		watchers: make(map[string][]func()),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
+// Watch calls fn whenever any loaded file or watchable source changes.
 func (c *Config) Watch(fn func()) {
 	c.cached.Range(func(key, value any) bool {
-		v := value.(*viper.Viper)
-		v.OnConfigChange(func(e fsnotify.Event) {
-			fn()
-		})
-		v.WatchConfig()
+		c.WatchFile(key.(string), fn)
 		return true
 	})
 }
 
-func (c *Config) Scan(filename string, obj any) error {
-	err := c.File(filename).Unmarshal(obj)
-	if err != nil {
-		return err
+// WatchFile calls fn whenever the file changes, the file is re-read before fn is called.
+func (c *Config) WatchFile(filename string, fn func()) {
+	v := c.File(filename)
+	if v == nil {
+		return
+	}
+	c.watchSources()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fns, watching := c.watchers[filename]
+	c.watchers[filename] = append(fns, fn)
+	if watching || v.ConfigFileUsed() == "" {
+		return
+	}
+	v.OnConfigChange(func(e fsnotify.Event) {
+		if err := c.resolveLayer(filename); err != nil {
+			log.Printf("reload conf file %s error: %v\n", filename, err)
+		}
+		c.merge()
+		c.notify(filename)
+	})
+	v.WatchConfig()
+}
+
+func (c *Config) notify(filename string) {
+	c.mu.Lock()
+	fns := c.watchers[filename]
+	c.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// watchSources reloads the watchable sources on change, once.
+func (c *Config) watchSources() {
+	c.sourceOnce.Do(func() {
+		for i, src := range c.files {
+			w, ok := src.(Watcher)
+			if !ok {
+				continue
+			}
+			stop := w.Watch(func() {
+				layers, err := c.loadSource(src)
+				if err != nil {
+					log.Printf("reload conf source error: %v\n", err)
+					return
+				}
+				c.mu.Lock()
+				c.layers[i] = layers
+				c.mu.Unlock()
+				c.merge()
+				for _, l := range layers {
+					c.notify(l.name)
+				}
+			})
+			c.mu.Lock()
+			c.stops = append(c.stops, stop)
+			c.mu.Unlock()
+		}
+	})
+}
+
+// Close stops watching the watchable sources.
+func (c *Config) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, stop := range c.stops {
+		stop()
+	}
+	c.stops = nil
+}
+
+// Scan unmarshals the file into obj, every problem found is reported with its key path
+// in a *SchemaError. The default:"..." tags are applied with WithDefaults, and obj is
+// validated with WithScanValidator. The secret values are decrypted.
+func (c *Config) Scan(filename string, obj any, opts ...ScanOption) error {
+	o := &scanOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	v := c.File(filename)
+	if v == nil {
+		return ErrFileNotLoaded
+	}
+	o.secrets = c.secretKeys(filename)
+	return scan(filename, v, obj, o)
+}
+
+// secretKeys returns the keys of the loaded file resolved from secrets.
+func (c *Config) secretKeys(filename string) map[string]struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, layers := range c.layers {
+		for _, l := range layers {
+			if l.name == filename {
+				return l.secrets
+			}
+		}
 	}
 	return nil
 }
 
+// File returns the viper.Viper of the loaded file, nil if it isn't loaded.
+// Its secret values are decrypted, only Dump redacts them.
 func (c *Config) File(filename string) *viper.Viper {
 	if v, ok := c.cached.Load(filename); ok {
 		return v.(*viper.Viper)
@@ -50,6 +175,7 @@ func (c *Config) File(filename string) *viper.Viper {
 	return nil
 }
 
+// Get returns the value of the key of the loaded file, a secret value is decrypted.
 func (c *Config) Get(filename string, key string) any {
 	return c.File(filename).Get(key)
 }
@@ -58,29 +184,82 @@ func (c *Config) Load() error {
 	if len(c.files) == 0 {
 		return nil
 	}
+	if c.resolver == nil {
+		key, err := secret.KeyFromEnv()
+		if err != nil {
+			return err
+		}
+		c.resolver = secret.NewResolver(secret.WithKey(key))
+	}
+	layers := make([][]*layer, 0, len(c.files))
 	for _, file := range c.files {
-		kvs, err := file.Load()
+		ls, err := c.loadSource(file)
 		if err != nil {
 			return err
 		}
+		layers = append(layers, ls)
+	}
 
-		for _, kv := range kvs {
-			v := viper.New()
-			v.SetConfigType(kv.Format)
+	c.mu.Lock()
+	c.layers = layers
+	c.mu.Unlock()
+	c.merge()
+	return nil
+}
+
+// loadSource loads the KVs of a source into named vipers.
+func (c *Config) loadSource(file Source) ([]*layer, error) {
+	kvs, err := file.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	layers := make([]*layer, 0, len(kvs))
+	for _, kv := range kvs {
+		v := viper.New()
+		v.SetConfigType(kv.Format)
+
+		if kv.Path != "" {
 			v.SetConfigFile(kv.Path)
+			err = v.ReadInConfig()
+		} else {
+			err = v.ReadConfig(bytes.NewReader(kv.Value))
+		}
+		if err != nil {
+			var configFileNotFoundError viper.ConfigFileNotFoundError
+			if errors.As(err, &configFileNotFoundError) {
+				log.Printf("Using conf file: %s [%s]\n", viper.ConfigFileUsed(), err)
+				return nil, errors.New("conf file not found")
+			}
+			return nil, err
+		}
+		v.AutomaticEnv()
 
-			if err := v.ReadInConfig(); err != nil {
-				var configFileNotFoundError viper.ConfigFileNotFoundError
-				if errors.As(err, &configFileNotFoundError) {
-					log.Printf("Using conf file: %s [%s]\n", viper.ConfigFileUsed(), err)
-					return errors.New("conf file not found")
-				}
+		name := strings.TrimSuffix(path.Base(kv.Key), filepath.Ext(kv.Key))
+		l := &layer{name: name, v: v}
+		if l.secrets, err = l.resolve(c.resolver); err != nil {
+			return nil, err
+		}
+		c.cached.Store(name, v)
+		layers = append(layers, l)
+	}
+	return layers, nil
+}
+
+// resolveLayer resolves the secrets of the re-read file.
+func (c *Config) resolveLayer(filename string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, layers := range c.layers {
+		for _, l := range layers {
+			if l.name != filename {
+				continue
+			}
+			secrets, err := l.resolve(c.resolver)
+			if err != nil {
 				return err
 			}
-			v.AutomaticEnv()
-
-			name := strings.TrimSuffix(path.Base(kv.Key), filepath.Ext(kv.Key))
-			c.cached.Store(name, v)
+			l.secrets = secrets
 		}
 	}
 	return nil
@@ -0,0 +1,31 @@
+package ginx
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	jwtx "github.com/apus-run/van/authx/jwt"
+)
+
+// SetupJWKS 在 /.well-known/jwks.json 发布 ks 的公钥，其他服务可以用 jwtx.RemoteKeySet 验证 token
+func SetupJWKS(rg gin.IRoutes, ks *jwtx.KeySet) {
+	rg.GET(jwtx.JWKSPath, JWKSHandler(ks))
+	rg.HEAD(jwtx.JWKSPath, JWKSHandler(ks))
+}
+
+// JWKSHandler 返回 ks 的 JSON Web Key Set
+func JWKSHandler(ks *jwtx.KeySet) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		jwks, err := ks.JWKS()
+		if err != nil {
+			slog.Error("生成 JWKS 失败", slog.Any("err", err))
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		// 轮换时新 key 的 token 会触发验证方重新获取，缓存时间不需要太长
+		ctx.Header("Cache-Control", "public, max-age=300")
+		ctx.JSON(http.StatusOK, jwks)
+	}
+}
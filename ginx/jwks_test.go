@@ -0,0 +1,38 @@
+package ginx
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jwtx "github.com/apus-run/van/authx/jwt"
+)
+
+func TestSetupJWKS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key, err := jwtx.GenerateKey(jwt.SigningMethodES256)
+	require.NoError(t, err)
+	ks := jwtx.NewKeySet(key, time.Hour)
+
+	server := gin.New()
+	SetupJWKS(server, ks)
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	issuer := jwtx.NewJwtAuth[*jwt.RegisteredClaims](nil, jwtx.WithKeySet(ks), jwtx.WithClaims(func() jwt.Claims {
+		return &jwt.RegisteredClaims{Subject: "1"}
+	}))
+	token, err := issuer.GenerateToken(context.Background())
+	require.NoError(t, err)
+
+	verifier := jwtx.NewJwtAuth[*jwt.RegisteredClaims](nil, jwtx.WithRemoteKeySet(jwtx.NewRemoteKeySet(srv.URL+jwtx.JWKSPath)))
+	claims, err := verifier.ParseClaims(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "1", claims.Subject)
+}
@@ -0,0 +1,38 @@
+package adaptivelimit
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/apus-run/van/pkg/bbr"
+)
+
+// AdaptiveActiveLimit 按 CPU 使用率和请求耗时自适应地限制并发，
+// 不需要像 locallimit.LocalActiveLimit 一样预先设置最大并发数
+type AdaptiveActiveLimit struct {
+	limiter *bbr.Limiter
+}
+
+func NewAdaptiveActiveLimit(opts ...bbr.Option) *AdaptiveActiveLimit {
+	return &AdaptiveActiveLimit{
+		limiter: bbr.New(opts...),
+	}
+}
+
+// Limiter 返回使用的限流器
+func (a *AdaptiveActiveLimit) Limiter() *bbr.Limiter {
+	return a.limiter
+}
+
+func (a *AdaptiveActiveLimit) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		done, err := a.limiter.Allow()
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusTooManyRequests)
+			return
+		}
+		defer done()
+		ctx.Next()
+	}
+}
@@ -0,0 +1,53 @@
+package adaptivelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apus-run/van/pkg/bbr"
+)
+
+func TestAdaptiveActiveLimit_Build(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// CPU 一直过载，还没有统计数据时最多允许 1 个并发
+	limit := NewAdaptiveActiveLimit(bbr.WithCPU(func() int64 { return 1000 }))
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	server := gin.New()
+	server.Use(limit.Build())
+	server.GET("/slow", func(ctx *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		ctx.Status(http.StatusOK)
+	})
+	server.GET("/fast", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/slow", nil))
+			assert.Equal(t, http.StatusOK, resp.Code)
+		}()
+		<-entered
+	}
+	assert.Equal(t, int64(2), limit.Limiter().Stat().InFlight)
+
+	resp := httptest.NewRecorder()
+	server.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int64(0), limit.Limiter().Stat().InFlight)
+}
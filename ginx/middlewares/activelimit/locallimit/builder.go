@@ -1,6 +1,7 @@
 package locallimit
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -27,13 +28,21 @@ func (a *LocalActiveLimit) SetMaxActive(maxActive int64) *LocalActiveLimit {
 	return a
 }
 
+// Acquire 占用一个活跃名额，返回是否未超过限制；无论结果如何都需要调用 Release 归还
+func (a *LocalActiveLimit) Acquire(_ context.Context) (bool, error) {
+	return a.countActive.Add(1) <= a.maxActive.Load(), nil
+}
+
+// Release 归还 Acquire 占用的名额
+func (a *LocalActiveLimit) Release(_ context.Context) {
+	a.countActive.Sub(1)
+}
+
 func (a *LocalActiveLimit) Build() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		current := a.countActive.Add(1)
-		defer func() {
-			a.countActive.Sub(1)
-		}()
-		if current <= a.maxActive.Load() {
+		ok, _ := a.Acquire(ctx)
+		defer a.Release(ctx)
+		if ok {
 			ctx.Next()
 		} else {
 			ctx.AbortWithStatus(http.StatusTooManyRequests)
@@ -1,6 +1,7 @@
 package redislimit
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -41,21 +42,33 @@ func (a *RedisActiveLimit) SetLogFunc(fun func(msg any, args ...any)) *RedisActi
 	return a
 }
 
+// Acquire 占用一个活跃名额，返回是否未超过限制；err 为 nil 时需要调用 Release 归还
+func (a *RedisActiveLimit) Acquire(ctx context.Context) (bool, error) {
+	currentCount, err := a.cmd.Incr(ctx, a.key).Result()
+	if err != nil {
+		a.logFn("redis 加一操作", err)
+		return false, err
+	}
+	return currentCount <= a.maxActive.Load(), nil
+}
+
+// Release 归还 Acquire 占用的名额
+func (a *RedisActiveLimit) Release(ctx context.Context) {
+	if err := a.cmd.Decr(ctx, a.key).Err(); err != nil {
+		a.logFn("redis 减一操作", err)
+	}
+}
+
 func (a *RedisActiveLimit) Build() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		currentCount, err := a.cmd.Incr(ctx, a.key).Result()
+		ok, err := a.Acquire(ctx)
 		if err != nil {
 			//为了安全性 直接返回异常
-			a.logFn("redis 加一操作", err)
 			ctx.AbortWithStatus(http.StatusInternalServerError)
 			return
 		}
-		defer func() {
-			if err = a.cmd.Decr(ctx, a.key).Err(); err != nil {
-				a.logFn("redis 减一操作", err)
-			}
-		}()
-		if currentCount <= a.maxActive.Load() {
+		defer a.Release(ctx)
+		if ok {
 			ctx.Next()
 		} else {
 			a.logFn("web server ", "限流中..")
@@ -0,0 +1,95 @@
+// Package apikey 使用 API key 鉴权服务间请求，并像 auth 中间件一样设置 claims，
+// 之后可以使用 authz 中间件授权，如 authz.WithRolesClaim("scopes")
+package apikey
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/apus-run/van/authx/apikey"
+	"github.com/apus-run/van/errorsx"
+	"github.com/apus-run/van/ginx/middlewares/auth"
+)
+
+// KeyKey gin.Context 中保存 *apikey.Key 的键
+const KeyKey = "apikey"
+
+// HeaderAPIKey 默认读取 API key 的请求头
+const HeaderAPIKey = "X-API-Key"
+
+type Builder struct {
+	manager *apikey.Manager
+	// 请求需要的 scopes
+	scopes []string
+	// 从请求中读取 API key
+	keyFunc func(ctx *gin.Context) string
+}
+
+func NewBuilder(manager *apikey.Manager) *Builder {
+	return &Builder{
+		manager: manager,
+		keyFunc: func(ctx *gin.Context) string {
+			return ctx.GetHeader(HeaderAPIKey)
+		},
+	}
+}
+
+// SetScopes 设置请求需要的 scopes，API key 缺少任一 scope 时拒绝
+func (b *Builder) SetScopes(scopes ...string) *Builder {
+	b.scopes = scopes
+	return b
+}
+
+// SetKeyFunc 设置读取 API key 的方法，默认读取 X-API-Key 请求头
+func (b *Builder) SetKeyFunc(fn func(ctx *gin.Context) string) *Builder {
+	b.keyFunc = fn
+	return b
+}
+
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		apiKey := b.keyFunc(ctx)
+		if apiKey == "" {
+			abort(ctx, errorsx.Unauthorized("ApiKeyMissing").WithMessage("API key 为空"))
+			return
+		}
+
+		key, err := b.manager.Verify(ctx.Request.Context(), apiKey)
+		if err != nil {
+			if errors.Is(err, apikey.ErrKeyInvalid) {
+				abort(ctx, errorsx.Unauthorized("ApiKeyInvalid").WithMessage(err.Error()))
+				return
+			}
+			// 存储不可用等内部错误
+			slog.Error("验证 API key 失败", slog.Any("err", err))
+			abort(ctx, errorsx.InternalServer("AuthFailed").WithMessage("验证 API key 失败"))
+			return
+		}
+		if !key.HasScopes(b.scopes...) {
+			abort(ctx, errorsx.Forbidden("ScopeMissing").WithMessage("API key 缺少权限"))
+			return
+		}
+
+		claims := key.Claims()
+		ctx.Set(KeyKey, key)
+		ctx.Set(auth.ClaimsKey, func() jwt.Claims { return claims })
+		ctx.Next()
+	}
+}
+
+// FromContext 返回 API key 中间件设置的 key
+func FromContext(ctx *gin.Context) (*apikey.Key, bool) {
+	val, ok := ctx.Get(KeyKey)
+	if !ok {
+		return nil, false
+	}
+	key, ok := val.(*apikey.Key)
+	return key, ok
+}
+
+func abort(ctx *gin.Context, err *errorsx.Error) {
+	ctx.AbortWithStatusJSON(err.Code, err)
+}
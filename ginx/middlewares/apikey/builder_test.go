@@ -0,0 +1,68 @@
+package apikey
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/authx/apikey"
+	"github.com/apus-run/van/cache/memory"
+	"github.com/apus-run/van/errorsx"
+	"github.com/apus-run/van/ginx/middlewares/auth"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := apikey.New(apikey.NewStore(memory.New()))
+	key, err := m.Create(context.Background(), &apikey.Key{Subject: "svc-billing", Scopes: []string{"orders:read"}})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		scopes   []string
+		key      string
+		wantCode int
+		reason   string
+	}{
+		{name: "API key 有效", scopes: []string{"orders:read"}, key: key, wantCode: http.StatusOK},
+		{name: "没有 API key", wantCode: http.StatusUnauthorized, reason: "ApiKeyMissing"},
+		{name: "API key 无效", key: key + "0", wantCode: http.StatusUnauthorized, reason: "ApiKeyInvalid"},
+		{name: "缺少 scope", scopes: []string{"orders:write"}, key: key, wantCode: http.StatusForbidden, reason: "ScopeMissing"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := gin.New()
+			server.Use(NewBuilder(m).SetScopes(tc.scopes...).Build())
+			server.GET("/orders", func(ctx *gin.Context) {
+				k, ok := FromContext(ctx)
+				require.True(t, ok)
+				claims, ok := auth.FromContext[*apikey.Claims](ctx)
+				require.True(t, ok)
+				assert.Equal(t, k.Subject, claims.Subject)
+				ctx.String(http.StatusOK, claims.Subject)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tc.key != "" {
+				req.Header.Set(HeaderAPIKey, tc.key)
+			}
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			if tc.reason == "" {
+				assert.Equal(t, "svc-billing", recorder.Body.String())
+				return
+			}
+			var e errorsx.Error
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &e))
+			assert.Equal(t, tc.reason, e.Reason)
+		})
+	}
+}
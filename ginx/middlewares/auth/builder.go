@@ -1,58 +1,147 @@
 package auth
 
 import (
+	"context"
 	"errors"
+	"log/slog"
+	"path"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 
-	"github.com/apus-run/van/ginx"
-	// jwtx "github.com/apus-run/van/anthx/jwt"
+	jwtx "github.com/apus-run/van/authx/jwt"
+	"github.com/apus-run/van/errorsx"
 )
 
-// Builder 鉴权，验证用户token是否有效
-type Builder struct {
+// ClaimsKey gin.Context 中保存 claims 的键，值的类型为 func() jwt.Claims，与 ginx.WC、ginx.BC 一致
+const ClaimsKey = "claims"
+
+var _ Parser[*jwt.RegisteredClaims] = (*jwtx.JwtAuth[*jwt.RegisteredClaims])(nil)
+
+// Parser 解析并验证 token，*jwtx.JwtAuth 实现了该接口
+type Parser[C jwt.Claims] interface {
+	ParseClaims(ctx context.Context, accessToken string) (C, error)
+}
+
+// Builder 鉴权，验证用户token是否有效，C 为 token 的 claims 类型
+type Builder[C jwt.Claims] struct {
+	parser Parser[C]
 	// 白名单路由地址集合, 放行
 	whitePathList []string
+	// 可选鉴权: 没有 token 的请求也放行，但 token 无效时仍然拒绝
+	optional bool
+	// 从请求中读取 token
+	tokenFunc func(ctx *gin.Context) (string, error)
 }
 
-func NewBuilder() *Builder {
-	return &Builder{
+func NewBuilder[C jwt.Claims](parser Parser[C]) *Builder[C] {
+	return &Builder[C]{
+		parser:        parser,
 		whitePathList: []string{},
+		tokenFunc:     getJwtFromHeader,
 	}
 }
 
-func (b *Builder) IgnorePaths(whitePath string) *Builder {
-	b.whitePathList = append(b.whitePathList, whitePath)
+// IgnorePaths 设置白名单路由，支持 path.Match 的通配符，如 /users/*/avatar，
+// 以 /** 结尾时匹配该路径及其下的所有路径，如 /public/**
+func (b *Builder[C]) IgnorePaths(whitePaths ...string) *Builder[C] {
+	b.whitePathList = append(b.whitePathList, whitePaths...)
+	return b
+}
+
+// SetOptional 设置可选鉴权，没有 token 的请求放行且不设置 claims
+func (b *Builder[C]) SetOptional(optional bool) *Builder[C] {
+	b.optional = optional
+	return b
+}
+
+// SetTokenFunc 设置读取 token 的方法，默认读取 Authorization: Bearer <token>
+func (b *Builder[C]) SetTokenFunc(fn func(ctx *gin.Context) (string, error)) *Builder[C] {
+	b.tokenFunc = fn
 	return b
 }
 
-func (b *Builder) Build() gin.HandlerFunc {
-	return ginx.Handle(func(ctx *ginx.Context) {
+func (b *Builder[C]) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
 		// 白名单路由放行
-		for _, path := range b.whitePathList {
-			if strings.Contains(ctx.Request.URL.Path, path) {
+		if b.ignored(ctx.Request.URL.Path) {
+			ctx.Next()
+			return
+		}
+
+		tokenString, err := b.tokenFunc(ctx)
+		if err != nil {
+			if !errors.Is(err, errTokenMissing) {
+				abort(ctx, errorsx.Unauthorized("TokenInvalid").WithMessage(err.Error()))
+				return
+			}
+			if b.optional {
 				ctx.Next()
 				return
 			}
+			abort(ctx, errorsx.Unauthorized("TokenMissing").WithMessage(err.Error()))
+			return
 		}
 
-		// tokenString, err := getJwtFromHeader(ctx)
-		// if err != nil {
-		// 	ctx.JSONE(http.StatusUnauthorized, "invalid token", nil)
-		// 	ctx.Abort()
-		// 	return
-		// }
+		claims, err := b.parser.ParseClaims(ctx.Request.Context(), tokenString)
+		if err != nil {
+			if errors.Is(err, jwtx.ErrTokenInvalid) || errors.Is(err, jwtx.ErrUnSupportSigningMethod) {
+				abort(ctx, errorsx.Unauthorized("TokenInvalid").WithMessage(err.Error()))
+				return
+			}
+			// 存储不可用等内部错误
+			slog.Error("验证 token 失败", slog.Any("err", err))
+			abort(ctx, errorsx.InternalServer("AuthFailed").WithMessage("验证 token 失败"))
+			return
+		}
 
+		ctx.Set(ClaimsKey, func() jwt.Claims { return claims })
 		ctx.Next()
-	})
+	}
+}
+
+func (b *Builder[C]) ignored(urlPath string) bool {
+	for _, pattern := range b.whitePathList {
+		if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if urlPath == prefix || strings.HasPrefix(urlPath, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, urlPath); ok {
+			return true
+		}
+	}
+	return false
 }
 
-func getJwtFromHeader(ctx *ginx.Context) (string, error) {
+// FromContext 返回鉴权中间件设置的 claims
+func FromContext[C jwt.Claims](ctx *gin.Context) (C, bool) {
+	var zero C
+	val, ok := ctx.Get(ClaimsKey)
+	if !ok {
+		return zero, false
+	}
+	fn, ok := val.(func() jwt.Claims)
+	if !ok {
+		return zero, false
+	}
+	claims, ok := fn().(C)
+	return claims, ok
+}
+
+func abort(ctx *gin.Context, err *errorsx.Error) {
+	ctx.AbortWithStatusJSON(err.Code, err)
+}
+
+var errTokenMissing = errors.New("token 为空")
+
+func getJwtFromHeader(ctx *gin.Context) (string, error) {
 	// 读取请求头的 token
 	tokenString := ctx.GetHeader("Authorization")
 	if len(tokenString) == 0 {
-		return "", errors.New("token 为空")
+		return "", errTokenMissing
 	}
 	strs := strings.SplitN(tokenString, " ", 2)
 	if len(strs) != 2 || strs[0] != "Bearer" {
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jwtx "github.com/apus-run/van/authx/jwt"
+	"github.com/apus-run/van/errorsx"
+	"github.com/apus-run/van/ginx"
+)
+
+type errParser struct{}
+
+func (errParser) ParseClaims(ctx context.Context, accessToken string) (*jwt.RegisteredClaims, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func newToken(t *testing.T, j *jwtx.JwtAuth[*jwt.RegisteredClaims]) string {
+	token, err := j.Sign(context.Background())
+	require.NoError(t, err)
+	return token.GetToken()
+}
+
+func TestBuilder_Build(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	j := jwtx.NewJwtAuth[*jwt.RegisteredClaims](nil, jwtx.WithClaims(func() jwt.Claims {
+		return &jwt.RegisteredClaims{
+			Subject:   "1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}
+	}))
+	other := jwtx.NewJwtAuth[*jwt.RegisteredClaims](nil, jwtx.WithKeyfunc(func(token *jwt.Token) (any, error) {
+		return []byte("other"), nil
+	}), jwtx.WithClaims(func() jwt.Claims {
+		return &jwt.RegisteredClaims{Subject: "1"}
+	}))
+
+	testCases := []struct {
+		name     string
+		builder  func() *Builder[*jwt.RegisteredClaims]
+		path     string
+		header   string
+		wantCode int
+		wantSub  string
+		reason   string
+	}{
+		{
+			name:     "token 有效",
+			builder:  func() *Builder[*jwt.RegisteredClaims] { return NewBuilder(j) },
+			path:     "/users/me",
+			header:   "Bearer " + newToken(t, j),
+			wantCode: http.StatusOK,
+			wantSub:  "1",
+		},
+		{
+			name:     "没有 token",
+			builder:  func() *Builder[*jwt.RegisteredClaims] { return NewBuilder(j) },
+			path:     "/users/me",
+			wantCode: http.StatusUnauthorized,
+			reason:   "TokenMissing",
+		},
+		{
+			name:     "不是 Bearer token",
+			builder:  func() *Builder[*jwt.RegisteredClaims] { return NewBuilder(j) },
+			path:     "/users/me",
+			header:   "Basic dmFuOnZhbg==",
+			wantCode: http.StatusUnauthorized,
+			reason:   "TokenInvalid",
+		},
+		{
+			name:     "签名错误",
+			builder:  func() *Builder[*jwt.RegisteredClaims] { return NewBuilder(j) },
+			path:     "/users/me",
+			header:   "Bearer " + newToken(t, other),
+			wantCode: http.StatusUnauthorized,
+			reason:   "TokenInvalid",
+		},
+		{
+			name:     "可选鉴权, 没有 token",
+			builder:  func() *Builder[*jwt.RegisteredClaims] { return NewBuilder(j).SetOptional(true) },
+			path:     "/users/me",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "可选鉴权, token 无效",
+			builder:  func() *Builder[*jwt.RegisteredClaims] { return NewBuilder(j).SetOptional(true) },
+			path:     "/users/me",
+			header:   "Bearer invalid",
+			wantCode: http.StatusUnauthorized,
+			reason:   "TokenInvalid",
+		},
+		{
+			name:     "白名单通配符",
+			builder:  func() *Builder[*jwt.RegisteredClaims] { return NewBuilder(j).IgnorePaths("/users/*/avatar") },
+			path:     "/users/1/avatar",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "白名单前缀",
+			builder:  func() *Builder[*jwt.RegisteredClaims] { return NewBuilder(j).IgnorePaths("/public/**") },
+			path:     "/public/css/app.css",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "白名单不按子串匹配",
+			builder:  func() *Builder[*jwt.RegisteredClaims] { return NewBuilder(j).IgnorePaths("/public/**") },
+			path:     "/api/public/users",
+			wantCode: http.StatusUnauthorized,
+			reason:   "TokenMissing",
+		},
+		{
+			name:     "内部错误",
+			builder:  func() *Builder[*jwt.RegisteredClaims] { return NewBuilder[*jwt.RegisteredClaims](errParser{}) },
+			path:     "/users/me",
+			header:   "Bearer " + newToken(t, j),
+			wantCode: http.StatusInternalServerError,
+			reason:   "AuthFailed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := gin.New()
+			server.Use(tc.builder().Build())
+			server.NoRoute(func(ctx *gin.Context) {
+				claims, ok := FromContext[*jwt.RegisteredClaims](ctx)
+				if ok {
+					ctx.String(http.StatusOK, claims.Subject)
+					return
+				}
+				ctx.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			if tc.reason != "" {
+				e := new(errorsx.Error)
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), e))
+				assert.Equal(t, tc.reason, e.Reason)
+				return
+			}
+			assert.Equal(t, tc.wantSub, resp.Body.String())
+		})
+	}
+}
+
+func TestBuilder_WC(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	j := jwtx.NewJwtAuth[*jwt.RegisteredClaims](nil, jwtx.WithClaims(func() jwt.Claims {
+		return &jwt.RegisteredClaims{Subject: "1"}
+	}))
+
+	server := gin.New()
+	server.Use(NewBuilder(j).Build())
+	server.GET("/users/me", ginx.WC(func(ctx *gin.Context, claims func() jwt.Claims) (ginx.Result, error) {
+		sub, err := claims().GetSubject()
+		return ginx.Result{Code: ginx.CodeOK, Data: sub}, err
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+	req.Header.Set("Authorization", "Bearer "+newToken(t, j))
+	resp := httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"code":0,"msg":"","data":"1"}`, resp.Body.String())
+}
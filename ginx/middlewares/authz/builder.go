@@ -0,0 +1,103 @@
+// Package authz 按 authz.Engine 的策略对已鉴权的请求授权，需要在 auth 中间件之后使用
+package authz
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/apus-run/van/authz"
+	"github.com/apus-run/van/errorsx"
+	"github.com/apus-run/van/ginx/middlewares/auth"
+)
+
+type Builder struct {
+	engine *authz.Engine
+	// 请求的 action，默认为 HTTP 方法
+	actionFunc func(ctx *gin.Context) string
+	// 请求的 resource，默认为请求路径
+	resourceFunc func(ctx *gin.Context) string
+}
+
+func NewBuilder(engine *authz.Engine) *Builder {
+	return &Builder{
+		engine: engine,
+		actionFunc: func(ctx *gin.Context) string {
+			return ctx.Request.Method
+		},
+		resourceFunc: func(ctx *gin.Context) string {
+			return ctx.Request.URL.Path
+		},
+	}
+}
+
+// SetActionFunc 设置请求的 action
+func (b *Builder) SetActionFunc(fn func(ctx *gin.Context) string) *Builder {
+	b.actionFunc = fn
+	return b
+}
+
+// SetResourceFunc 设置请求的 resource，如使用路由模板 ctx.FullPath()
+func (b *Builder) SetResourceFunc(fn func(ctx *gin.Context) string) *Builder {
+	b.resourceFunc = fn
+	return b
+}
+
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		val, _ := ctx.Get(auth.ClaimsKey)
+		claims, ok := val.(func() jwt.Claims)
+		if !ok {
+			abort(ctx, errorsx.Unauthorized("ClaimsMissing").WithMessage("没有鉴权信息"))
+			return
+		}
+
+		req, err := b.engine.Request(claims(), b.actionFunc(ctx), b.resourceFunc(ctx))
+		if err != nil {
+			slog.Error("创建授权请求失败", slog.Any("err", err))
+			abort(ctx, errorsx.InternalServer("AuthzFailed").WithMessage("授权失败"))
+			return
+		}
+		req.Attrs["request"] = requestAttrs(ctx)
+
+		// 拒绝的原因会暴露策略的角色，只记录在决策日志中
+		if d := b.engine.Authorize(ctx.Request.Context(), req); !d.Permitted() {
+			abort(ctx, errorsx.Forbidden("PermissionDenied").WithMessage("没有权限"))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// requestAttrs 返回条件可以引用的请求属性，header 的键为小写
+func requestAttrs(ctx *gin.Context) map[string]any {
+	header := make(map[string]string, len(ctx.Request.Header))
+	for k := range ctx.Request.Header {
+		header[strings.ToLower(k)] = ctx.Request.Header.Get(k)
+	}
+	query := make(map[string]string)
+	for k, v := range ctx.Request.URL.Query() {
+		if len(v) > 0 {
+			query[k] = v[0]
+		}
+	}
+	param := make(map[string]string, len(ctx.Params))
+	for _, p := range ctx.Params {
+		param[p.Key] = p.Value
+	}
+	return map[string]any{
+		"method": ctx.Request.Method,
+		"path":   ctx.Request.URL.Path,
+		"route":  ctx.FullPath(),
+		"ip":     ctx.ClientIP(),
+		"header": header,
+		"query":  query,
+		"param":  param,
+	}
+}
+
+func abort(ctx *gin.Context, err *errorsx.Error) {
+	ctx.AbortWithStatusJSON(err.Code, err)
+}
@@ -0,0 +1,118 @@
+package authz
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/authz"
+	"github.com/apus-run/van/errorsx"
+	"github.com/apus-run/van/ginx/middlewares/auth"
+)
+
+type claims struct {
+	Roles  []string `json:"roles"`
+	Tenant string   `json:"tenant"`
+	jwt.RegisteredClaims
+}
+
+func newEngine(t *testing.T, opts ...authz.Option) *authz.Engine {
+	e, err := authz.New(&authz.Policy{Roles: []authz.Role{{
+		Name: "viewer",
+		Permissions: []authz.Permission{{
+			Actions:   []string{"GET"},
+			Resources: []string{"/tenants/*/orders/**"},
+			Conditions: []authz.Condition{
+				{Attr: "request.param.tenant", Values: []string{"${claims.tenant}"}},
+			},
+		}},
+	}}}, opts...)
+	require.NoError(t, err)
+	return e
+}
+
+func TestBuilder_Build(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	viewer := &claims{Roles: []string{"viewer"}, Tenant: "t1"}
+
+	testCases := []struct {
+		name     string
+		opts     []authz.Option
+		claims   jwt.Claims
+		method   string
+		path     string
+		wantCode int
+		reason   string
+	}{
+		{
+			name:     "授权通过",
+			claims:   viewer,
+			method:   http.MethodGet,
+			path:     "/tenants/t1/orders/1",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "没有 claims",
+			method:   http.MethodGet,
+			path:     "/tenants/t1/orders/1",
+			wantCode: http.StatusUnauthorized,
+			reason:   "ClaimsMissing",
+		},
+		{
+			name:     "条件不满足",
+			claims:   viewer,
+			method:   http.MethodGet,
+			path:     "/tenants/t2/orders/1",
+			wantCode: http.StatusForbidden,
+			reason:   "PermissionDenied",
+		},
+		{
+			name:     "没有权限",
+			claims:   viewer,
+			method:   http.MethodDelete,
+			path:     "/tenants/t1/orders/1",
+			wantCode: http.StatusForbidden,
+			reason:   "PermissionDenied",
+		},
+		{
+			name:     "试运行放行",
+			opts:     []authz.Option{authz.WithDryRun(true)},
+			claims:   viewer,
+			method:   http.MethodDelete,
+			path:     "/tenants/t1/orders/1",
+			wantCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := gin.New()
+			server.Use(func(ctx *gin.Context) {
+				if tc.claims != nil {
+					ctx.Set(auth.ClaimsKey, func() jwt.Claims { return tc.claims })
+				}
+			})
+			server.Use(NewBuilder(newEngine(t, tc.opts...)).Build())
+			server.Handle(tc.method, "/tenants/:tenant/orders/:id", func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			if tc.reason != "" {
+				var e errorsx.Error
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &e))
+				assert.Equal(t, tc.reason, e.Reason)
+				assert.NotContains(t, e.Message, "role", "不暴露策略的角色")
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// Package breaker 按路由熔断，路由返回 5xx 的比例过高时在本地直接拒绝请求。
+package breaker
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/apus-run/van/pkg/breaker"
+)
+
+type Builder struct {
+	group *breaker.Group
+	// 请求的熔断器名称
+	nameFunc func(ctx *gin.Context) string
+	// 判断请求是否失败
+	failed func(ctx *gin.Context) bool
+}
+
+// NewBuilder 默认每个路由一个 SRE 熔断器，响应状态码为 5xx 时记为失败
+func NewBuilder() *Builder {
+	return &Builder{
+		group: breaker.NewGroup(nil),
+		nameFunc: func(ctx *gin.Context) string {
+			return ctx.Request.Method + " " + ctx.FullPath()
+		},
+		failed: func(ctx *gin.Context) bool {
+			return ctx.Writer.Status() >= http.StatusInternalServerError
+		},
+	}
+}
+
+// SetGroup 设置熔断器分组
+func (b *Builder) SetGroup(group *breaker.Group) *Builder {
+	b.group = group
+	return b
+}
+
+// SetNameFunc 设置请求的熔断器名称
+func (b *Builder) SetNameFunc(fn func(ctx *gin.Context) string) *Builder {
+	b.nameFunc = fn
+	return b
+}
+
+// SetFailedFunc 设置判断请求失败的方法
+func (b *Builder) SetFailedFunc(fn func(ctx *gin.Context) bool) *Builder {
+	b.failed = fn
+	return b
+}
+
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		brk := b.group.Get(b.nameFunc(ctx))
+		if err := brk.Allow(); err != nil {
+			ctx.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+
+		ctx.Next()
+
+		if b.failed(ctx) {
+			brk.MarkFailed()
+		} else {
+			brk.MarkSuccess()
+		}
+	}
+}
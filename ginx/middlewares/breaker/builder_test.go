@@ -0,0 +1,46 @@
+package breaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apus-run/van/pkg/breaker"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	group := breaker.NewGroup(func() breaker.Breaker {
+		return breaker.NewSRE(breaker.WithRequest(10))
+	})
+	server := gin.New()
+	server.Use(NewBuilder().SetGroup(group).Build())
+	server.GET("/fail", func(ctx *gin.Context) {
+		ctx.Status(http.StatusInternalServerError)
+	})
+	server.GET("/ok", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	serve := func(path string) int {
+		resp := httptest.NewRecorder()
+		server.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+		return resp.Code
+	}
+
+	rejected := 0
+	for i := 0; i < 100; i++ {
+		if serve("/fail") == http.StatusServiceUnavailable {
+			rejected++
+		}
+	}
+	assert.Greater(t, rejected, 0)
+
+	// 每个路由单独熔断
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, http.StatusOK, serve("/ok"))
+	}
+}
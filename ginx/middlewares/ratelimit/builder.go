@@ -0,0 +1,100 @@
+// Package ratelimit 限制每个用户或 IP 在一段时间内的请求数，支持令牌桶、GCRA 和滑动窗口算法，
+// 以及进程内和 Redis 两种存储。响应中会设置 X-RateLimit-* 头，被限流时还会设置 Retry-After。
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderLimit      = "X-RateLimit-Limit"
+	HeaderRemaining  = "X-RateLimit-Remaining"
+	HeaderReset      = "X-RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+)
+
+type Builder struct {
+	limiter  Limiter
+	keyFunc  KeyFunc
+	prefix   string
+	failOpen bool
+	logFn    func(msg any, args ...any)
+}
+
+// NewBuilder 默认按客户端 IP 限流
+func NewBuilder(limiter Limiter) *Builder {
+	return &Builder{
+		limiter: limiter,
+		keyFunc: KeyByIP,
+		prefix:  "ratelimit:",
+		logFn: func(msg any, args ...any) {
+			fmt.Println(fmt.Sprintf("%v  详细信息: %v", msg, args))
+		},
+	}
+}
+
+// SetKeyFunc 设置请求的限流 key
+func (b *Builder) SetKeyFunc(fn KeyFunc) *Builder {
+	b.keyFunc = fn
+	return b
+}
+
+// SetPrefix 设置限流 key 的前缀，默认为 ratelimit:
+func (b *Builder) SetPrefix(prefix string) *Builder {
+	b.prefix = prefix
+	return b
+}
+
+// SetFailOpen 设置限流器出错时是否放行，默认返回 500
+func (b *Builder) SetFailOpen(failOpen bool) *Builder {
+	b.failOpen = failOpen
+	return b
+}
+
+func (b *Builder) SetLogFunc(fn func(msg any, args ...any)) *Builder {
+	b.logFn = fn
+	return b
+}
+
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := b.keyFunc(ctx)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+
+		res, err := b.limiter.Allow(ctx, b.prefix+key)
+		if err != nil {
+			b.logFn("限流器出错", err)
+			if b.failOpen {
+				ctx.Next()
+				return
+			}
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		h := ctx.Writer.Header()
+		h.Set(HeaderLimit, strconv.FormatInt(res.Limit, 10))
+		h.Set(HeaderRemaining, strconv.FormatInt(res.Remaining, 10))
+		h.Set(HeaderReset, seconds(res.ResetAfter))
+		if !res.Allowed {
+			h.Set(HeaderRetryAfter, seconds(res.RetryAfter))
+			ctx.AbortWithStatus(http.StatusTooManyRequests)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// seconds 返回向上取整的秒数
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
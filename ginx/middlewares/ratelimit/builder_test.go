@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errLimiter struct{}
+
+func (errLimiter) Allow(context.Context, string) (Result, error) {
+	return Result{}, errors.New("limiter down")
+}
+
+type subjectParser struct{}
+
+func (subjectParser) ParseClaims(_ context.Context, token string) (*jwt.RegisteredClaims, error) {
+	if token == "bad" {
+		return nil, errors.New("token is invalid")
+	}
+	return &jwt.RegisteredClaims{Subject: token}, nil
+}
+
+func newServer(b *Builder) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	server.Use(b.SetLogFunc(func(msg any, args ...any) {}).Build())
+	server.GET("/ratelimit", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	return server
+}
+
+func do(server *gin.Engine, ip, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/ratelimit", nil)
+	req.RemoteAddr = ip + ":1234"
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp := httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestBuilder_Build(t *testing.T) {
+	limiter, err := NewLocalLimiter(GCRA, PerMinute(2))
+	require.NoError(t, err)
+	server := newServer(NewBuilder(limiter))
+
+	resp := do(server, "10.0.0.1", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "2", resp.Header().Get(HeaderLimit))
+	assert.Equal(t, "1", resp.Header().Get(HeaderRemaining))
+	assert.Equal(t, "30", resp.Header().Get(HeaderReset))
+
+	assert.Equal(t, http.StatusOK, do(server, "10.0.0.1", "").Code)
+
+	resp = do(server, "10.0.0.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "0", resp.Header().Get(HeaderRemaining))
+	assert.Equal(t, "30", resp.Header().Get(HeaderRetryAfter))
+
+	assert.Equal(t, http.StatusOK, do(server, "10.0.0.2", "").Code)
+}
+
+func TestBuilder_KeyBySubject(t *testing.T) {
+	limiter, err := NewLocalLimiter(TokenBucket, PerMinute(1))
+	require.NoError(t, err)
+	server := newServer(NewBuilder(limiter).SetKeyFunc(KeyBySubject(subjectParser{})))
+
+	assert.Equal(t, http.StatusOK, do(server, "10.0.0.1", "alice").Code)
+	assert.Equal(t, http.StatusTooManyRequests, do(server, "10.0.0.2", "alice").Code, "limited by subject across IPs")
+	assert.Equal(t, http.StatusOK, do(server, "10.0.0.1", "bob").Code)
+
+	assert.Equal(t, http.StatusOK, do(server, "10.0.0.1", "bad").Code, "falls back to the IP")
+	assert.Equal(t, http.StatusTooManyRequests, do(server, "10.0.0.1", "").Code)
+}
+
+func TestBuilder_LimiterError(t *testing.T) {
+	assert.Equal(t, http.StatusInternalServerError, do(newServer(NewBuilder(errLimiter{})), "10.0.0.1", "").Code)
+	assert.Equal(t, http.StatusOK, do(newServer(NewBuilder(errLimiter{}).SetFailOpen(true)), "10.0.0.1", "").Code)
+}
@@ -0,0 +1,46 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyFunc 返回请求的限流 key，返回空字符串时不限流
+type KeyFunc func(ctx *gin.Context) string
+
+// ClaimsParser 解析 token 的声明，authx/jwt.JwtAuth 实现了该接口
+type ClaimsParser interface {
+	ParseClaims(ctx context.Context, token string) (*jwt.RegisteredClaims, error)
+}
+
+// KeyByIP 按客户端 IP 限流
+func KeyByIP(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// KeyByHeader 按请求头限流，请求头为空时按客户端 IP 限流
+func KeyByHeader(name string) KeyFunc {
+	return func(ctx *gin.Context) string {
+		if v := ctx.GetHeader(name); v != "" {
+			return "header:" + v
+		}
+		return KeyByIP(ctx)
+	}
+}
+
+// KeyBySubject 按 Bearer token 的 sub 限流，没有有效 token 时按客户端 IP 限流
+func KeyBySubject(parser ClaimsParser) KeyFunc {
+	return func(ctx *gin.Context) string {
+		scheme, token, ok := strings.Cut(ctx.GetHeader("Authorization"), " ")
+		if ok && scheme == "Bearer" && token != "" {
+			claims, err := parser.ParseClaims(ctx, token)
+			if err == nil && claims.Subject != "" {
+				return "sub:" + claims.Subject
+			}
+		}
+		return KeyByIP(ctx)
+	}
+}
@@ -0,0 +1,173 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+var _ Limiter = (*LocalLimiter)(nil)
+
+// state 一个 key 的限流状态，不同算法使用不同的字段
+type state struct {
+	// 令牌桶: 剩余令牌和上次补充的时间
+	tokens float64
+	last   time.Time
+	// GCRA: 理论到达时间
+	tat time.Time
+	// 滑动窗口: 当前窗口的序号和当前、上一个窗口的计数
+	window     int64
+	curr, prev int64
+	// idleAt 之后状态等同于初始状态，可以被清理
+	idleAt time.Time
+}
+
+// LocalLimiter 进程内的限流器
+type LocalLimiter struct {
+	algorithm Algorithm
+	limit     Limit
+	now       func() time.Time
+
+	mux       sync.Mutex
+	states    map[string]*state
+	lastSweep time.Time
+}
+
+// NewLocalLimiter 创建进程内的限流器
+func NewLocalLimiter(algorithm Algorithm, limit Limit) (*LocalLimiter, error) {
+	if err := limit.validate(); err != nil {
+		return nil, err
+	}
+	return &LocalLimiter{
+		algorithm: algorithm,
+		limit:     limit,
+		now:       time.Now,
+		states:    make(map[string]*state),
+	}, nil
+}
+
+func (l *LocalLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	s, ok := l.states[key]
+	if !ok {
+		s = &state{tokens: float64(l.limit.burst()), last: now, tat: now, window: l.windowOf(now)}
+		l.states[key] = s
+	}
+
+	switch l.algorithm {
+	case GCRA:
+		return l.gcra(s, now), nil
+	case SlidingWindow:
+		return l.slidingWindow(s, now), nil
+	default:
+		return l.tokenBucket(s, now), nil
+	}
+}
+
+func (l *LocalLimiter) tokenBucket(s *state, now time.Time) Result {
+	capacity := float64(l.limit.burst())
+	interval := float64(l.limit.interval())
+
+	s.tokens = math.Min(capacity, s.tokens+float64(now.Sub(s.last))/interval)
+	s.last = now
+
+	res := Result{Limit: l.limit.burst()}
+	if s.tokens >= 1 {
+		s.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - s.tokens) * interval)
+	}
+	res.Remaining = int64(s.tokens)
+	res.ResetAfter = time.Duration((capacity - s.tokens) * interval)
+	s.idleAt = now.Add(res.ResetAfter)
+	return res
+}
+
+func (l *LocalLimiter) gcra(s *state, now time.Time) Result {
+	interval := l.limit.interval()
+	offset := interval * time.Duration(l.limit.burst())
+
+	tat := s.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	newTat := tat.Add(interval)
+	allowAt := newTat.Add(-offset)
+
+	res := Result{Limit: l.limit.burst()}
+	if now.Before(allowAt) {
+		res.RetryAfter = allowAt.Sub(now)
+		res.ResetAfter = tat.Sub(now)
+		return res
+	}
+	s.tat = newTat
+	s.idleAt = newTat
+	res.Allowed = true
+	res.Remaining = int64((offset - newTat.Sub(now)) / interval)
+	res.ResetAfter = newTat.Sub(now)
+	return res
+}
+
+func (l *LocalLimiter) slidingWindow(s *state, now time.Time) Result {
+	period := l.limit.Period
+	window := l.windowOf(now)
+	switch {
+	case s.window == window-1:
+		s.prev, s.curr = s.curr, 0
+	case s.window < window-1:
+		s.prev, s.curr = 0, 0
+	}
+	s.window = window
+
+	elapsed := now.Sub(time.Unix(0, window*int64(period)))
+	res := slidingWindowResult(l.limit.Rate, period, elapsed, s.prev, s.curr)
+	if res.Allowed {
+		s.curr++
+	}
+	s.idleAt = time.Unix(0, (window+2)*int64(period))
+	return res
+}
+
+// slidingWindowResult 计算滑动窗口的结果，elapsed 为当前窗口已经过的时间
+func slidingWindowResult(limit int64, period, elapsed time.Duration, prev, curr int64) Result {
+	weight := float64(prev)*(1-float64(elapsed)/float64(period)) + float64(curr)
+
+	res := Result{Limit: limit, ResetAfter: 2*period - elapsed}
+	if weight+1 <= float64(limit) {
+		res.Allowed = true
+		res.Remaining = int64(float64(limit) - weight - 1)
+		return res
+	}
+	// 上一个窗口的权重随时间下降，计算到允许下一个请求的时间
+	if free := float64(limit - curr - 1); free >= 0 && prev > 0 {
+		wait := time.Duration(float64(period)*(1-free/float64(prev))) - elapsed
+		res.RetryAfter = max(wait, time.Millisecond)
+	} else {
+		res.RetryAfter = period - elapsed
+	}
+	return res
+}
+
+func (l *LocalLimiter) windowOf(now time.Time) int64 {
+	return now.UnixNano() / int64(l.limit.Period)
+}
+
+// sweep 定期清理已恢复到初始状态的 key
+func (l *LocalLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, s := range l.states {
+		if !now.Before(s.idleAt) {
+			delete(l.states, key)
+		}
+	}
+}
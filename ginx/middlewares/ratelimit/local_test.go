@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestLimiter(t *testing.T, algorithm Algorithm, limit Limit) (*LocalLimiter, *fakeClock) {
+	l, err := NewLocalLimiter(algorithm, limit)
+	require.NoError(t, err)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	l.now = clock.Now
+	return l, clock
+}
+
+func allowN(t *testing.T, l Limiter, key string, n int) int {
+	allowed := 0
+	for i := 0; i < n; i++ {
+		res, err := l.Allow(context.Background(), key)
+		require.NoError(t, err)
+		if res.Allowed {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestLocalLimiter_Burst(t *testing.T) {
+	for _, algorithm := range []Algorithm{TokenBucket, GCRA} {
+		t.Run(algorithm.String(), func(t *testing.T) {
+			l, clock := newTestLimiter(t, algorithm, Limit{Rate: 10, Period: time.Second, Burst: 5})
+			ctx := context.Background()
+
+			res, err := l.Allow(ctx, "a")
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, int64(5), res.Limit)
+			assert.Equal(t, int64(4), res.Remaining)
+
+			assert.Equal(t, 4, allowN(t, l, "a", 10), "the burst is exhausted")
+
+			res, err = l.Allow(ctx, "a")
+			require.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.Equal(t, int64(0), res.Remaining)
+			assert.InDelta(t, 100*time.Millisecond, res.RetryAfter, float64(time.Millisecond))
+
+			assert.Equal(t, 5, allowN(t, l, "b", 10), "keys are limited separately")
+
+			clock.Advance(100 * time.Millisecond)
+			assert.Equal(t, 1, allowN(t, l, "a", 10), "one request per interval")
+
+			clock.Advance(time.Second)
+			assert.Equal(t, 5, allowN(t, l, "a", 10), "the burst is restored")
+		})
+	}
+}
+
+func TestLocalLimiter_SlidingWindow(t *testing.T) {
+	l, clock := newTestLimiter(t, SlidingWindow, PerSecond(10))
+	ctx := context.Background()
+
+	assert.Equal(t, 10, allowN(t, l, "a", 20))
+
+	res, err := l.Allow(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter, "the next window")
+
+	// half of the previous window still counts
+	clock.Advance(1500 * time.Millisecond)
+	assert.Equal(t, 5, allowN(t, l, "a", 20))
+
+	res, err = l.Allow(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 100*time.Millisecond, res.RetryAfter)
+
+	clock.Advance(100 * time.Millisecond)
+	assert.Equal(t, 1, allowN(t, l, "a", 20))
+
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, 10, allowN(t, l, "a", 20))
+}
+
+func TestLocalLimiter_Sweep(t *testing.T) {
+	l, clock := newTestLimiter(t, TokenBucket, PerSecond(1))
+	allowN(t, l, "a", 1)
+	assert.Len(t, l.states, 1)
+
+	clock.Advance(2 * time.Minute)
+	allowN(t, l, "b", 1)
+	assert.Len(t, l.states, 1)
+}
+
+func TestLimit_Validate(t *testing.T) {
+	_, err := NewLocalLimiter(TokenBucket, Limit{Rate: 0, Period: time.Second})
+	assert.Error(t, err)
+	_, err = NewLocalLimiter(TokenBucket, Limit{Rate: 10, Period: time.Nanosecond})
+	assert.Error(t, err)
+	_, err = NewRedisLimiter(nil, GCRA, Limit{Rate: 10, Period: time.Second, Burst: -1})
+	assert.Error(t, err)
+}
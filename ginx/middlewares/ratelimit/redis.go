@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ Limiter = (*RedisLimiter)(nil)
+
+// 脚本使用 Redis 服务器的时间，单位为微秒，返回 {allowed, remaining, retry_after, reset_after}
+var (
+	tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local s = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(s[1]) or capacity
+local ts = tonumber(s[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) / interval)
+
+local allowed, retry = 0, 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = (1 - tokens) * interval
+end
+local reset = (capacity - tokens) * interval
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(reset / 1000) + 1000)
+return {allowed, math.floor(tokens), math.ceil(retry), math.ceil(reset)}
+`)
+
+	gcraScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local tat = tonumber(redis.call("GET", KEYS[1])) or now
+tat = math.max(tat, now)
+local offset = interval * burst
+local new_tat = tat + interval
+local allow_at = new_tat - offset
+
+if now < allow_at then
+	return {0, 0, math.ceil(allow_at - now), math.ceil(tat - now)}
+end
+redis.call("SET", KEYS[1], string.format("%d", new_tat), "PX", math.ceil((new_tat - now) / 1000) + 1000)
+return {1, math.floor((offset - (new_tat - now)) / interval), 0, math.ceil(new_tat - now)}
+`)
+
+	slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local window = math.floor(now / period)
+
+local s = redis.call("HMGET", KEYS[1], "window", "curr", "prev")
+local w = tonumber(s[1]) or window
+local curr = tonumber(s[2]) or 0
+local prev = tonumber(s[3]) or 0
+if w == window - 1 then
+	prev, curr = curr, 0
+elseif w < window - 1 then
+	prev, curr = 0, 0
+end
+
+local elapsed = now - window * period
+local weight = prev * (1 - elapsed / period) + curr
+local reset = 2 * period - elapsed
+
+if weight + 1 > limit then
+	local retry = period - elapsed
+	local free = limit - curr - 1
+	if free >= 0 and prev > 0 then
+		retry = math.max(period * (1 - free / prev) - elapsed, 1000)
+	end
+	return {0, 0, math.ceil(retry), math.ceil(reset)}
+end
+
+curr = curr + 1
+redis.call("HSET", KEYS[1], "window", string.format("%d", window), "curr", curr, "prev", prev)
+redis.call("PEXPIRE", KEYS[1], math.ceil(2 * period / 1000) + 1000)
+return {1, math.floor(limit - weight - 1), 0, math.ceil(reset)}
+`)
+)
+
+// RedisLimiter 基于 Redis 的分布式限流器，每次判断是一次原子的 Lua 脚本调用
+type RedisLimiter struct {
+	cmd       redis.Cmdable
+	algorithm Algorithm
+	limit     Limit
+}
+
+// NewRedisLimiter 创建基于 Redis 的限流器
+func NewRedisLimiter(cmd redis.Cmdable, algorithm Algorithm, limit Limit) (*RedisLimiter, error) {
+	if err := limit.validate(); err != nil {
+		return nil, err
+	}
+	return &RedisLimiter{
+		cmd:       cmd,
+		algorithm: algorithm,
+		limit:     limit,
+	}, nil
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	var (
+		script *redis.Script
+		args   []any
+		limit  = l.limit.burst()
+	)
+	switch l.algorithm {
+	case GCRA:
+		script, args = gcraScript, []any{l.limit.interval().Microseconds(), l.limit.burst()}
+	case SlidingWindow:
+		script, args = slidingWindowScript, []any{l.limit.Rate, l.limit.Period.Microseconds()}
+		limit = l.limit.Rate
+	default:
+		script, args = tokenBucketScript, []any{l.limit.burst(), l.limit.interval().Microseconds()}
+	}
+
+	vals, err := script.Run(ctx, l.cmd, []string{key}, args...).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(vals) != 4 {
+		return Result{}, fmt.Errorf("ratelimit: unexpected script result %v", vals)
+	}
+	return Result{
+		Allowed:    vals[0] == 1,
+		Limit:      limit,
+		Remaining:  vals[1],
+		RetryAfter: time.Duration(vals[2]) * time.Microsecond,
+		ResetAfter: time.Duration(vals[3]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/apus-run/van/cache/mocks"
+)
+
+func TestRedisLimiter_Allow(t *testing.T) {
+	testCases := []struct {
+		name      string
+		algorithm Algorithm
+		limit     Limit
+		args      []any
+		val       any
+		err       error
+
+		want    Result
+		wantErr bool
+	}{
+		{
+			name:      "token bucket allowed",
+			algorithm: TokenBucket,
+			limit:     Limit{Rate: 10, Period: time.Second, Burst: 20},
+			args:      []any{int64(20), int64(100000)},
+			val:       []any{int64(1), int64(19), int64(0), int64(100000)},
+			want:      Result{Allowed: true, Limit: 20, Remaining: 19, ResetAfter: 100 * time.Millisecond},
+		},
+		{
+			name:      "gcra rejected",
+			algorithm: GCRA,
+			limit:     PerSecond(10),
+			args:      []any{int64(100000), int64(10)},
+			val:       []any{int64(0), int64(0), int64(50000), int64(1000000)},
+			want:      Result{Limit: 10, RetryAfter: 50 * time.Millisecond, ResetAfter: time.Second},
+		},
+		{
+			name:      "sliding window allowed",
+			algorithm: SlidingWindow,
+			limit:     Limit{Rate: 100, Period: time.Minute, Burst: 5},
+			args:      []any{int64(100), int64(60000000)},
+			val:       []any{int64(1), int64(42), int64(0), int64(90000000)},
+			want:      Result{Allowed: true, Limit: 100, Remaining: 42, ResetAfter: 90 * time.Second},
+		},
+		{
+			name:      "redis error",
+			algorithm: TokenBucket,
+			limit:     PerSecond(10),
+			args:      []any{int64(10), int64(100000)},
+			err:       errors.New("network error"),
+			wantErr:   true,
+		},
+		{
+			name:      "unexpected result",
+			algorithm: TokenBucket,
+			limit:     PerSecond(10),
+			args:      []any{int64(10), int64(100000)},
+			val:       []any{int64(1)},
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cmd := mocks.NewMockCmdable(ctrl)
+			res := redis.NewCmd(context.Background())
+			res.SetVal(tc.val)
+			res.SetErr(tc.err)
+			cmd.EXPECT().
+				EvalSha(gomock.Any(), gomock.Any(), []string{"ratelimit:ip:1"}, tc.args...).
+				Return(res)
+
+			l, err := NewRedisLimiter(cmd, tc.algorithm, tc.limit)
+			require.NoError(t, err)
+			got, err := l.Allow(context.Background(), "ratelimit:ip:1")
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Algorithm 限流算法
+type Algorithm int
+
+const (
+	// TokenBucket 令牌桶，按速率补充令牌，允许 Burst 个请求的突发
+	TokenBucket Algorithm = iota
+	// GCRA 通用信元速率算法，效果与令牌桶相同，只需保存一个时间戳
+	GCRA
+	// SlidingWindow 滑动窗口，按当前和上一个窗口的计数加权估算周期内的请求数，不允许突发
+	SlidingWindow
+)
+
+func (a Algorithm) String() string {
+	switch a {
+	case TokenBucket:
+		return "token_bucket"
+	case GCRA:
+		return "gcra"
+	case SlidingWindow:
+		return "sliding_window"
+	default:
+		return fmt.Sprintf("Algorithm(%d)", int(a))
+	}
+}
+
+// Limit 限流规则，每个 Period 允许 Rate 个请求
+type Limit struct {
+	Rate   int64
+	Period time.Duration
+	// Burst 允许突发的请求数，为 0 时等于 Rate，滑动窗口不使用
+	Burst int64
+}
+
+// PerSecond 每秒 rate 个请求
+func PerSecond(rate int64) Limit {
+	return Limit{Rate: rate, Period: time.Second}
+}
+
+// PerMinute 每分钟 rate 个请求
+func PerMinute(rate int64) Limit {
+	return Limit{Rate: rate, Period: time.Minute}
+}
+
+// PerHour 每小时 rate 个请求
+func PerHour(rate int64) Limit {
+	return Limit{Rate: rate, Period: time.Hour}
+}
+
+// burst 返回允许突发的请求数
+func (l Limit) burst() int64 {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Rate
+}
+
+// interval 返回两个请求之间的平均间隔
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Rate)
+}
+
+func (l Limit) validate() error {
+	if l.Rate <= 0 || l.Period <= 0 || l.Burst < 0 || l.interval() < time.Microsecond {
+		return fmt.Errorf("ratelimit: invalid limit %d/%s burst %d", l.Rate, l.Period, l.Burst)
+	}
+	return nil
+}
+
+// Result 一次限流判断的结果
+type Result struct {
+	Allowed bool
+	// Limit 周期内允许的请求数
+	Limit int64
+	// Remaining 剩余可用的请求数
+	Remaining int64
+	// RetryAfter 被拒绝时到下一个请求被允许的时间
+	RetryAfter time.Duration
+	// ResetAfter 到配额完全恢复的时间
+	ResetAfter time.Duration
+}
+
+// Limiter 限流器
+type Limiter interface {
+	// Allow 消耗 key 的一个请求配额
+	Allow(ctx context.Context, key string) (Result, error)
+}
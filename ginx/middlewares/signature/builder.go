@@ -0,0 +1,58 @@
+// Package signature 验证 HMAC 签名的服务间请求，签名方使用 signature.Transport
+package signature
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/apus-run/van/authx/signature"
+	"github.com/apus-run/van/errorsx"
+)
+
+// KeyIDKey gin.Context 中保存签名 key id 的键
+const KeyIDKey = "signature_key_id"
+
+type Builder struct {
+	verifier *signature.Verifier
+}
+
+func NewBuilder(verifier *signature.Verifier) *Builder {
+	return &Builder{
+		verifier: verifier,
+	}
+}
+
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		keyID, err := b.verifier.Verify(ctx.Request)
+		switch {
+		case err == nil:
+		case errors.Is(err, signature.ErrSignatureMissing):
+			abort(ctx, errorsx.Unauthorized("SignatureMissing").WithMessage(err.Error()))
+			return
+		case errors.Is(err, signature.ErrSignatureInvalid):
+			abort(ctx, errorsx.Unauthorized("SignatureInvalid").WithMessage(err.Error()))
+			return
+		default:
+			// 密钥或 nonce 存储不可用等内部错误
+			slog.Error("验证签名失败", slog.Any("err", err))
+			abort(ctx, errorsx.InternalServer("AuthFailed").WithMessage("验证签名失败"))
+			return
+		}
+
+		ctx.Set(KeyIDKey, keyID)
+		ctx.Next()
+	}
+}
+
+// FromContext 返回签名中间件设置的 key id
+func FromContext(ctx *gin.Context) (string, bool) {
+	keyID := ctx.GetString(KeyIDKey)
+	return keyID, keyID != ""
+}
+
+func abort(ctx *gin.Context, err *errorsx.Error) {
+	ctx.AbortWithStatusJSON(err.Code, err)
+}
@@ -0,0 +1,71 @@
+package signature
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/authx/signature"
+	"github.com/apus-run/van/cache/memory"
+	"github.com/apus-run/van/errorsx"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secrets := func(ctx context.Context, keyID string) ([]byte, error) {
+		switch keyID {
+		case "billing":
+			return []byte("secret"), nil
+		case "broken":
+			return nil, errors.New("secrets unavailable")
+		}
+		return nil, signature.ErrUnknownKey
+	}
+
+	testCases := []struct {
+		name     string
+		keyID    string
+		wantCode int
+		reason   string
+	}{
+		{name: "签名有效", keyID: "billing", wantCode: http.StatusOK},
+		{name: "没有签名", wantCode: http.StatusUnauthorized, reason: "SignatureMissing"},
+		{name: "未知的 key", keyID: "unknown", wantCode: http.StatusUnauthorized, reason: "SignatureInvalid"},
+		{name: "内部错误", keyID: "broken", wantCode: http.StatusInternalServerError, reason: "AuthFailed"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := gin.New()
+			server.Use(NewBuilder(signature.NewVerifier(secrets, memory.New())).Build())
+			server.POST("/orders", func(ctx *gin.Context) {
+				keyID, ok := FromContext(ctx)
+				require.True(t, ok)
+				ctx.String(http.StatusOK, keyID)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"id":1}`))
+			if tc.keyID != "" {
+				require.NoError(t, signature.NewSigner(tc.keyID, []byte("secret")).Sign(req))
+			}
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			if tc.reason == "" {
+				assert.Equal(t, tc.keyID, recorder.Body.String())
+				return
+			}
+			var e errorsx.Error
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &e))
+			assert.Equal(t, tc.reason, e.Reason)
+		})
+	}
+}
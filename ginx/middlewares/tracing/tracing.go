@@ -42,6 +42,22 @@ func WithTracerProvider(provider oteltrace.TracerProvider) TraceOption {
 	}
 }
 
+// Config returns the tracer provider and propagators opts resolve to,
+// the global ones are used when none are specified.
+func Config(opts ...TraceOption) (oteltrace.TracerProvider, propagation.TextMapPropagator) {
+	cfg := traceConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.TracerProvider == nil {
+		cfg.TracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.Propagators == nil {
+		cfg.Propagators = otel.GetTextMapPropagator()
+	}
+	return cfg.TracerProvider, cfg.Propagators
+}
+
 // Tracing returns interceptor that will trace incoming requests.
 // The service parameter should describe the name of the (virtual)
 // server handling the request.
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// LivenessService is the gRPC health service name reporting liveness.
+// The empty service name reports readiness, any other name reports the
+// registered check with that name.
+const LivenessService = "liveness"
+
+var _ healthpb.HealthServer = (*grpcServer)(nil)
+
+type grpcServer struct {
+	healthpb.UnimplementedHealthServer
+
+	h *Health
+}
+
+// GRPCServer returns the grpc.health.v1 service backed by h.
+func (h *Health) GRPCServer() healthpb.HealthServer {
+	return &grpcServer{h: h}
+}
+
+// Check implements healthpb.HealthServer.
+func (s *grpcServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	st, ok := s.status(ctx, req.GetService())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+// Watch implements healthpb.HealthServer.
+func (s *grpcServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx := stream.Context()
+	ticker := time.NewTicker(s.h.options.watchInterval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	first := true
+	for {
+		st, ok := s.status(ctx, req.GetService())
+		if !ok {
+			st = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+		if first || st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return status.Error(codes.Canceled, "stream has ended")
+			}
+			first = false
+			last = st
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *grpcServer) status(ctx context.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	switch service {
+	case "":
+		return toServingStatus(s.h.Ready(ctx).Status), true
+	case LivenessService:
+		return toServingStatus(s.h.Live(ctx).Status), true
+	}
+	res, ok := s.h.CheckOne(ctx, service)
+	if !ok {
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, false
+	}
+	st := toServingStatus(res.Status)
+	if s.h.Draining() {
+		st = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return st, true
+}
+
+func toServingStatus(st Status) healthpb.HealthCheckResponse_ServingStatus {
+	if st == StatusServing {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
@@ -0,0 +1,246 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status is the serving status of a check or of the whole service.
+type Status string
+
+const (
+	// StatusServing means the check passed.
+	StatusServing Status = "SERVING"
+	// StatusNotServing means the check failed or the service is draining.
+	StatusNotServing Status = "NOT_SERVING"
+)
+
+// Kind is the kind of a check.
+type Kind int
+
+const (
+	// Readiness checks decide whether the service can receive traffic.
+	Readiness Kind = iota
+	// Liveness checks decide whether the process should be restarted.
+	Liveness
+)
+
+// Checker checks the health of a single dependency.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is an adapter to allow the use of ordinary functions as Checker.
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f(ctx).
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Pinger is implemented by clients that can ping their backend, such as *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// PingChecker returns a Checker that pings p.
+func PingChecker(p Pinger) Checker {
+	return CheckerFunc(p.PingContext)
+}
+
+// CheckResult is the result of a single check.
+type CheckResult struct {
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Result is the aggregated result of a set of checks.
+type Result struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type check struct {
+	name    string
+	kind    Kind
+	checker Checker
+}
+
+// Health aggregates named checks and reports health, readiness and liveness.
+type Health struct {
+	options *options
+
+	mu     sync.RWMutex
+	checks []check
+
+	draining atomic.Bool
+}
+
+// New create a health checks aggregator.
+func New(opts ...Option) *Health {
+	o := &options{
+		timeout:       3 * time.Second,
+		watchInterval: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Health{options: o}
+}
+
+// Register registers a readiness check with the given name.
+// A check registered with an existing name replaces the previous one.
+func (h *Health) Register(name string, checker Checker) {
+	h.register(name, Readiness, checker)
+}
+
+// RegisterLiveness registers a liveness check with the given name.
+// A check registered with an existing name replaces the previous one.
+func (h *Health) RegisterLiveness(name string, checker Checker) {
+	h.register(name, Liveness, checker)
+}
+
+func (h *Health) register(name string, kind Kind, checker Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := range h.checks {
+		if h.checks[i].name == name {
+			h.checks[i] = check{name: name, kind: kind, checker: checker}
+			return
+		}
+	}
+	h.checks = append(h.checks, check{name: name, kind: kind, checker: checker})
+}
+
+// Deregister removes the check with the given name.
+func (h *Health) Deregister(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := range h.checks {
+		if h.checks[i].name == name {
+			h.checks = append(h.checks[:i], h.checks[i+1:]...)
+			return
+		}
+	}
+}
+
+// Names returns the sorted names of all registered checks.
+func (h *Health) Names() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	names := make([]string, 0, len(h.checks))
+	for _, c := range h.checks {
+		names = append(names, c.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Shutdown marks the service as draining, readiness reports NOT_SERVING from now on.
+func (h *Health) Shutdown() {
+	h.draining.Store(true)
+}
+
+// Resume clears the draining mark set by Shutdown.
+func (h *Health) Resume() {
+	h.draining.Store(false)
+}
+
+// Draining reports whether Shutdown has been called.
+func (h *Health) Draining() bool {
+	return h.draining.Load()
+}
+
+// Health runs every registered check.
+func (h *Health) Health(ctx context.Context) *Result {
+	return h.run(ctx, func(check) bool { return true })
+}
+
+// Ready runs the readiness checks, it always fails while draining.
+func (h *Health) Ready(ctx context.Context) *Result {
+	res := h.run(ctx, func(c check) bool { return c.kind == Readiness })
+	if h.Draining() {
+		res.Status = StatusNotServing
+	}
+	return res
+}
+
+// Live runs the liveness checks.
+func (h *Health) Live(ctx context.Context) *Result {
+	return h.run(ctx, func(c check) bool { return c.kind == Liveness })
+}
+
+// CheckOne runs the check with the given name, ok is false if it does not exist.
+func (h *Health) CheckOne(ctx context.Context, name string) (res CheckResult, ok bool) {
+	h.mu.RLock()
+	var found *check
+	for i := range h.checks {
+		if h.checks[i].name == name {
+			c := h.checks[i]
+			found = &c
+			break
+		}
+	}
+	h.mu.RUnlock()
+	if found == nil {
+		return CheckResult{}, false
+	}
+	return h.do(ctx, found.checker), true
+}
+
+func (h *Health) run(ctx context.Context, filter func(check) bool) *Result {
+	h.mu.RLock()
+	checks := make([]check, 0, len(h.checks))
+	for _, c := range h.checks {
+		if filter(c) {
+			checks = append(checks, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	res := &Result{Status: StatusServing}
+	if len(checks) == 0 {
+		return res
+	}
+
+	results := make([]CheckResult, len(checks))
+	wg := sync.WaitGroup{}
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			results[i] = h.do(ctx, c.checker)
+		}(i, c)
+	}
+	wg.Wait()
+
+	res.Checks = make(map[string]CheckResult, len(checks))
+	for i, c := range checks {
+		res.Checks[c.name] = results[i]
+		if results[i].Status != StatusServing {
+			res.Status = StatusNotServing
+		}
+	}
+	return res
+}
+
+func (h *Health) do(ctx context.Context, checker Checker) (res CheckResult) {
+	if h.options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.options.timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			res = CheckResult{Status: StatusNotServing, Error: fmt.Sprintf("panic: %v", r)}
+		}
+	}()
+	if err := checker.Check(ctx); err != nil {
+		return CheckResult{Status: StatusNotServing, Error: err.Error()}
+	}
+	return CheckResult{Status: StatusServing}
+}
@@ -0,0 +1,114 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealth(t *testing.T) {
+	ctx := context.Background()
+	h := New()
+
+	assert.Equal(t, StatusServing, h.Health(ctx).Status)
+	assert.Equal(t, StatusServing, h.Ready(ctx).Status)
+	assert.Equal(t, StatusServing, h.Live(ctx).Status)
+
+	h.Register("db", CheckerFunc(func(context.Context) error { return nil }))
+	h.Register("redis", CheckerFunc(func(context.Context) error { return errors.New("connection refused") }))
+	h.RegisterLiveness("loop", CheckerFunc(func(context.Context) error { return nil }))
+	assert.Equal(t, []string{"db", "loop", "redis"}, h.Names())
+
+	res := h.Ready(ctx)
+	assert.Equal(t, StatusNotServing, res.Status)
+	assert.Equal(t, StatusServing, res.Checks["db"].Status)
+	assert.Equal(t, "connection refused", res.Checks["redis"].Error)
+	assert.NotContains(t, res.Checks, "loop")
+
+	assert.Equal(t, StatusServing, h.Live(ctx).Status)
+	assert.Equal(t, StatusNotServing, h.Health(ctx).Status)
+
+	h.Deregister("redis")
+	assert.Equal(t, StatusServing, h.Ready(ctx).Status)
+
+	h.Shutdown()
+	assert.Equal(t, StatusNotServing, h.Ready(ctx).Status)
+	assert.Equal(t, StatusServing, h.Live(ctx).Status)
+}
+
+func TestHealth_Timeout(t *testing.T) {
+	h := New(WithTimeout(10 * time.Millisecond))
+	h.Register("slow", CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	h.Register("panic", CheckerFunc(func(context.Context) error { panic("boom") }))
+
+	res := h.Ready(context.Background())
+	assert.Equal(t, StatusNotServing, res.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), res.Checks["slow"].Error)
+	assert.Equal(t, "panic: boom", res.Checks["panic"].Error)
+}
+
+func TestHealth_Handler(t *testing.T) {
+	h := New()
+	h.Register("db", CheckerFunc(func(context.Context) error { return nil }))
+	handler := h.Handler()
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{HealthzPath, http.StatusOK},
+		{ReadyzPath, http.StatusOK},
+		{LivezPath, http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		assert.Equal(t, tt.want, w.Code, tt.path)
+		assert.Contains(t, w.Body.String(), `"status":"SERVING"`)
+	}
+
+	h.Shutdown()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ReadyzPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, LivezPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestHealth_GRPC(t *testing.T) {
+	ctx := context.Background()
+	h := New()
+	h.Register("db", CheckerFunc(func(context.Context) error { return nil }))
+	srv := h.GRPCServer()
+
+	resp, err := srv.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	resp, err = srv.Check(ctx, &healthpb.HealthCheckRequest{Service: "db"})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	_, err = srv.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Error(t, err)
+
+	h.Shutdown()
+	resp, err = srv.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	resp, err = srv.Check(ctx, &healthpb.HealthCheckRequest{Service: LivenessService})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
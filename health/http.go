@@ -0,0 +1,40 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// Handler returns a http.Handler serving HealthzPath, ReadyzPath and LivezPath.
+func (h *Health) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(HealthzPath, h.handle(h.Health))
+	mux.Handle(ReadyzPath, h.handle(h.Ready))
+	mux.Handle(LivezPath, h.handle(h.Live))
+	return mux
+}
+
+func (h *Health) handle(fn func(ctx context.Context) *Result) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		res := fn(r.Context())
+		code := http.StatusOK
+		if res.Status != StatusServing {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+		w.WriteHeader(code)
+		if r.Method == http.MethodHead {
+			return
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	})
+}
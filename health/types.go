@@ -0,0 +1,35 @@
+package health
+
+import (
+	"time"
+)
+
+const (
+	// HealthzPath reports the aggregated result of every registered check.
+	HealthzPath = "/healthz"
+	// ReadyzPath reports whether the service is ready to receive traffic.
+	ReadyzPath = "/readyz"
+	// LivezPath reports whether the process is alive.
+	LivezPath = "/livez"
+)
+
+// Option is health option.
+type Option func(o *options)
+
+// options is health options.
+type options struct {
+	// timeout of a single check
+	timeout time.Duration
+	// interval of re-evaluating checks for gRPC Watch streams
+	watchInterval time.Duration
+}
+
+// WithTimeout with the timeout of a single check.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithWatchInterval with the interval the gRPC Watch stream re-evaluates checks.
+func WithWatchInterval(interval time.Duration) Option {
+	return func(o *options) { o.watchInterval = interval }
+}
@@ -0,0 +1,202 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/apus-run/van/pkg/retry"
+)
+
+// Client 创建分布式锁
+type Client struct {
+	backend        Backend
+	attemptTimeout time.Duration
+}
+
+func NewClient(backend Backend, opts ...Option) *Client {
+	options := Apply(opts...)
+	return &Client{
+		backend:        backend,
+		attemptTimeout: options.AttemptTimeout,
+	}
+}
+
+// TryLock 尝试加锁一次，锁已被占用时返回 ErrFailedToPreemptLock
+func (c *Client) TryLock(ctx context.Context, key string, expiration time.Duration) (*Lock, error) {
+	token := uuid.NewString()
+	ok, err := c.backend.Acquire(ctx, key, token, expiration)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrFailedToPreemptLock
+	}
+	return c.newLock(key, token, expiration), nil
+}
+
+// Lock 加锁，锁被占用或单次加锁超时时按 strategy 重试，直到重试次数用完或 ctx 结束。
+// strategy 为 nil 时只加锁一次。
+func (c *Client) Lock(ctx context.Context, key string, expiration time.Duration, strategy retry.Strategy) (*Lock, error) {
+	// 重试时使用同一个令牌，超时但实际成功的加锁在重试时会被确认
+	token := uuid.NewString()
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		actx, cancel := context.WithTimeout(ctx, c.attemptTimeout)
+		ok, err := c.backend.Acquire(actx, key, token, expiration)
+		cancel()
+		if ok {
+			return c.newLock(key, token, expiration), nil
+		}
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if strategy == nil {
+			return nil, lockError(err)
+		}
+		interval, more := strategy.Next()
+		if !more {
+			return nil, lockError(err)
+		}
+		if timer == nil {
+			timer = time.NewTimer(interval)
+		} else {
+			timer.Reset(interval)
+		}
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// lockError 返回最后一次加锁的错误
+func lockError(err error) error {
+	if err != nil {
+		return err
+	}
+	return ErrFailedToPreemptLock
+}
+
+func (c *Client) newLock(key, token string, expiration time.Duration) *Lock {
+	return &Lock{
+		backend:    c.backend,
+		key:        key,
+		token:      token,
+		expiration: expiration,
+		unlocked:   make(chan struct{}),
+	}
+}
+
+// Lock 一把已持有的锁
+type Lock struct {
+	backend    Backend
+	key        string
+	token      string
+	expiration time.Duration
+
+	unlockOnce sync.Once
+	unlocked   chan struct{}
+}
+
+// Key 返回锁的键
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Token 返回锁的令牌，用于区分锁的持有者
+func (l *Lock) Token() string {
+	return l.token
+}
+
+// Refresh 续约，将过期时间重置为加锁时的过期时间
+func (l *Lock) Refresh(ctx context.Context) error {
+	ok, err := l.backend.Refresh(ctx, l.key, l.token, l.expiration)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLockNotHold
+	}
+	return nil
+}
+
+// AutoRefresh 看门狗，每隔 interval 续约一次，直到 Unlock 或续约失败，timeout 为单次续约的超时时间。
+// 续约超时会立即重试，该方法会阻塞，通常在单独的 goroutine 中调用。
+func (l *Lock) AutoRefresh(interval, timeout time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	retryCh := make(chan struct{}, 1)
+
+	refresh := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		err := l.Refresh(ctx)
+		if errors.Is(err, context.DeadlineExceeded) {
+			// 已有排队的重试时不再重复排队，阻塞发送会使看门狗永远无法退出
+			select {
+			case retryCh <- struct{}{}:
+			default:
+			}
+			return nil
+		}
+		// 续约与 Unlock 并发时锁已被释放
+		if err != nil && l.isUnlocked() {
+			return nil
+		}
+		return err
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := refresh(); err != nil {
+				return err
+			}
+		case <-retryCh:
+			if err := refresh(); err != nil {
+				return err
+			}
+		case <-l.unlocked:
+			return nil
+		}
+	}
+}
+
+func (l *Lock) isUnlocked() bool {
+	select {
+	case <-l.unlocked:
+		return true
+	default:
+		return false
+	}
+}
+
+// Unlock 释放锁并停止看门狗，锁已过期或被其他持有者占用时返回 ErrLockNotHold
+func (l *Lock) Unlock(ctx context.Context) error {
+	l.unlockOnce.Do(func() {
+		close(l.unlocked)
+	})
+	ok, err := l.backend.Release(ctx, l.key, l.token)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLockNotHold
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package lock_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/lock"
+	"github.com/apus-run/van/lock/memory"
+	"github.com/apus-run/van/pkg/retry"
+)
+
+func TestClient_TryLock(t *testing.T) {
+	ctx := context.Background()
+	c := memory.NewClient()
+
+	l, err := c.TryLock(ctx, "order:1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "order:1", l.Key())
+	assert.NotEmpty(t, l.Token())
+
+	_, err = c.TryLock(ctx, "order:1", time.Minute)
+	assert.ErrorIs(t, err, lock.ErrFailedToPreemptLock)
+
+	require.NoError(t, l.Refresh(ctx))
+	require.NoError(t, l.Unlock(ctx))
+	assert.ErrorIs(t, l.Unlock(ctx), lock.ErrLockNotHold)
+	assert.ErrorIs(t, l.Refresh(ctx), lock.ErrLockNotHold)
+
+	l2, err := c.TryLock(ctx, "order:1", time.Minute)
+	require.NoError(t, err)
+	assert.NotEqual(t, l.Token(), l2.Token())
+}
+
+func TestClient_Expired(t *testing.T) {
+	ctx := context.Background()
+	c := memory.NewClient()
+
+	l, err := c.TryLock(ctx, "k", 50*time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	l2, err := c.TryLock(ctx, "k", time.Minute)
+	require.NoError(t, err)
+	assert.ErrorIs(t, l.Unlock(ctx), lock.ErrLockNotHold)
+	require.NoError(t, l2.Unlock(ctx))
+}
+
+func TestClient_Lock(t *testing.T) {
+	ctx := context.Background()
+	c := memory.NewClient()
+
+	held, err := c.TryLock(ctx, "k", time.Minute)
+	require.NoError(t, err)
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		s, err := retry.NewFixedIntervalRetryStrategy(10*time.Millisecond, 3)
+		require.NoError(t, err)
+		_, err = c.Lock(ctx, "k", time.Minute, s)
+		assert.ErrorIs(t, err, lock.ErrFailedToPreemptLock)
+	})
+
+	t.Run("nil strategy", func(t *testing.T) {
+		_, err := c.Lock(ctx, "k", time.Minute, nil)
+		assert.ErrorIs(t, err, lock.ErrFailedToPreemptLock)
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		s, err := retry.NewFixedIntervalRetryStrategy(10*time.Millisecond, 0)
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err = c.Lock(ctx, "k", time.Minute, s)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("acquired after release", func(t *testing.T) {
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = held.Unlock(ctx)
+		}()
+		s, err := retry.NewFixedIntervalRetryStrategy(10*time.Millisecond, 0)
+		require.NoError(t, err)
+		l, err := c.Lock(ctx, "k", time.Minute, s)
+		require.NoError(t, err)
+		require.NoError(t, l.Unlock(ctx))
+	})
+}
+
+// flakyBackend times out the first acquires, which nevertheless succeed.
+type flakyBackend struct {
+	lock.Backend
+	timeouts atomic.Int32
+}
+
+func (b *flakyBackend) Acquire(ctx context.Context, key, token string, expiration time.Duration) (bool, error) {
+	ok, err := b.Backend.Acquire(ctx, key, token, expiration)
+	if b.timeouts.Add(-1) >= 0 {
+		return false, context.DeadlineExceeded
+	}
+	return ok, err
+}
+
+func TestClient_LockTimeout(t *testing.T) {
+	ctx := context.Background()
+	b := &flakyBackend{Backend: memory.New()}
+	b.timeouts.Store(2)
+	c := lock.NewClient(b, lock.WithAttemptTimeout(100*time.Millisecond))
+
+	s, err := retry.NewFixedIntervalRetryStrategy(time.Millisecond, 5)
+	require.NoError(t, err)
+	l, err := c.Lock(ctx, "k", time.Minute, s)
+	require.NoError(t, err, "the timed out acquire is confirmed by the retry with the same token")
+	require.NoError(t, l.Unlock(ctx))
+
+	b.timeouts.Store(10)
+	s, err = retry.NewFixedIntervalRetryStrategy(time.Millisecond, 2)
+	require.NoError(t, err)
+	_, err = c.Lock(ctx, "other", time.Minute, s)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestLock_AutoRefresh(t *testing.T) {
+	ctx := context.Background()
+	c := memory.NewClient()
+
+	l, err := c.TryLock(ctx, "k", 100*time.Millisecond)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- l.AutoRefresh(30*time.Millisecond, time.Second)
+	}()
+
+	time.Sleep(300 * time.Millisecond)
+	_, err = c.TryLock(ctx, "k", time.Minute)
+	assert.ErrorIs(t, err, lock.ErrFailedToPreemptLock, "the watchdog keeps the lock")
+
+	require.NoError(t, l.Unlock(ctx))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("watchdog not stopped by Unlock")
+	}
+}
+
+func TestLock_AutoRefreshLost(t *testing.T) {
+	ctx := context.Background()
+	b := memory.New()
+	c := lock.NewClient(b)
+
+	l, err := c.TryLock(ctx, "k", time.Minute)
+	require.NoError(t, err)
+	// another holder takes the lock over
+	_, err = b.Release(ctx, "k", l.Token())
+	require.NoError(t, err)
+	_, err = b.Acquire(ctx, "k", "other", time.Minute)
+	require.NoError(t, err)
+
+	err = l.AutoRefresh(10*time.Millisecond, time.Second)
+	assert.True(t, errors.Is(err, lock.ErrLockNotHold))
+}
+
+// timeoutBackend 续约总是超时
+type timeoutBackend struct {
+	lock.Backend
+}
+
+func (timeoutBackend) Refresh(ctx context.Context, key, token string, expiration time.Duration) (bool, error) {
+	<-ctx.Done()
+	return false, ctx.Err()
+}
+
+func TestLock_AutoRefreshTimeout(t *testing.T) {
+	ctx := context.Background()
+	c := lock.NewClient(timeoutBackend{Backend: memory.New()})
+
+	l, err := c.TryLock(ctx, "k", time.Minute)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- l.AutoRefresh(time.Millisecond, time.Millisecond)
+	}()
+
+	// 重试与定时续约都超时
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, l.Unlock(ctx))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("watchdog not stopped by Unlock")
+	}
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/apus-run/van/lock"
+)
+
+var _ lock.Backend = (*Backend)(nil)
+
+type item struct {
+	token    string
+	expireAt time.Time
+}
+
+// Backend 进程内的锁存储，用于测试和单机部署
+type Backend struct {
+	mux   sync.Mutex
+	locks map[string]item
+	now   func() time.Time
+}
+
+func New() *Backend {
+	return &Backend{
+		locks: make(map[string]item),
+		now:   time.Now,
+	}
+}
+
+// NewClient 创建进程内的锁客户端
+func NewClient(opts ...lock.Option) *lock.Client {
+	return lock.NewClient(New(), opts...)
+}
+
+func (b *Backend) Acquire(ctx context.Context, key, token string, expiration time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if it, ok := b.held(key); ok && it.token != token {
+		return false, nil
+	}
+	b.locks[key] = item{token: token, expireAt: b.now().Add(expiration)}
+	return true, nil
+}
+
+func (b *Backend) Refresh(ctx context.Context, key, token string, expiration time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if it, ok := b.held(key); !ok || it.token != token {
+		return false, nil
+	}
+	b.locks[key] = item{token: token, expireAt: b.now().Add(expiration)}
+	return true, nil
+}
+
+func (b *Backend) Release(ctx context.Context, key, token string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if it, ok := b.held(key); !ok || it.token != token {
+		return false, nil
+	}
+	delete(b.locks, key)
+	return true, nil
+}
+
+// held 返回未过期的锁，过期的锁会被删除
+func (b *Backend) held(key string) (item, bool) {
+	it, ok := b.locks[key]
+	if !ok {
+		return item{}, false
+	}
+	if !b.now().Before(it.expireAt) {
+		delete(b.locks, key)
+		return item{}, false
+	}
+	return it, true
+}
@@ -0,0 +1,33 @@
+package lock
+
+import "time"
+
+// Option is config option.
+type Option func(*Options)
+
+type Options struct {
+	// AttemptTimeout 单次加锁的超时时间，超时后按重试策略继续加锁
+	AttemptTimeout time.Duration
+}
+
+// DefaultOptions .
+func DefaultOptions() *Options {
+	return &Options{
+		AttemptTimeout: time.Second,
+	}
+}
+
+func Apply(opts ...Option) *Options {
+	options := DefaultOptions()
+	for _, o := range opts {
+		o(options)
+	}
+	return options
+}
+
+// WithAttemptTimeout 设置单次加锁的超时时间
+func WithAttemptTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.AttemptTimeout = d
+	}
+}
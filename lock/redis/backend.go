@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/apus-run/van/lock"
+)
+
+var _ lock.Backend = (*Backend)(nil)
+
+var (
+	// acquireScript 未被占用时加锁，已被同一令牌占用时重置过期时间
+	acquireScript = redis.NewScript(`
+local val = redis.call("GET", KEYS[1])
+if val == false then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+elseif val == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+	// refreshScript 被同一令牌占用时重置过期时间
+	refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+	// releaseScript 被同一令牌占用时释放
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+)
+
+// Backend 基于 Redis 的锁存储，通过 Lua 脚本保证检查令牌和修改的原子性
+type Backend struct {
+	client redis.Cmdable
+}
+
+func New(client redis.Cmdable) *Backend {
+	return &Backend{
+		client: client,
+	}
+}
+
+// NewClient 创建基于 Redis 的分布式锁客户端
+func NewClient(client redis.Cmdable, opts ...lock.Option) *lock.Client {
+	return lock.NewClient(New(client), opts...)
+}
+
+func (b *Backend) Acquire(ctx context.Context, key, token string, expiration time.Duration) (bool, error) {
+	return b.run(ctx, acquireScript, key, token, expiration.Milliseconds())
+}
+
+func (b *Backend) Refresh(ctx context.Context, key, token string, expiration time.Duration) (bool, error) {
+	return b.run(ctx, refreshScript, key, token, expiration.Milliseconds())
+}
+
+func (b *Backend) Release(ctx context.Context, key, token string) (bool, error) {
+	return b.run(ctx, releaseScript, key, token)
+}
+
+func (b *Backend) run(ctx context.Context, script *redis.Script, key string, args ...any) (bool, error) {
+	n, err := script.Run(ctx, b.client, []string{key}, args...).Int64()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,132 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/apus-run/van/cache/mocks"
+	"github.com/apus-run/van/lock"
+	lockredis "github.com/apus-run/van/lock/redis"
+)
+
+func TestClient_TryLock(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(*gomock.Controller) redis.Cmdable
+
+		wantErr error
+	}{
+		{
+			name: "locked",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := mocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal(int64(1))
+				cmd.EXPECT().
+					EvalSha(gomock.Any(), gomock.Any(), []string{"key"}, gomock.Any(), int64(60000)).
+					Return(res)
+				return cmd
+			},
+		},
+		{
+			name: "held by another",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := mocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal(int64(0))
+				cmd.EXPECT().
+					EvalSha(gomock.Any(), gomock.Any(), []string{"key"}, gomock.Any(), int64(60000)).
+					Return(res)
+				return cmd
+			},
+			wantErr: lock.ErrFailedToPreemptLock,
+		},
+		{
+			name: "network error",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := mocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetErr(errors.New("network error"))
+				cmd.EXPECT().
+					EvalSha(gomock.Any(), gomock.Any(), []string{"key"}, gomock.Any(), int64(60000)).
+					Return(res)
+				return cmd
+			},
+			wantErr: errors.New("network error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			c := lockredis.NewClient(tc.mock(ctrl))
+			l, err := c.TryLock(context.Background(), "key", time.Minute)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, "key", l.Key())
+			assert.NotEmpty(t, l.Token())
+		})
+	}
+}
+
+func TestLock_Unlock(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		released int64
+
+		wantErr error
+	}{
+		{
+			name:     "unlocked",
+			released: 1,
+		},
+		{
+			name:     "not held",
+			released: 0,
+			wantErr:  lock.ErrLockNotHold,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cmd := mocks.NewMockCmdable(ctrl)
+			locked := redis.NewCmd(context.Background())
+			locked.SetVal(int64(1))
+			released := redis.NewCmd(context.Background())
+			released.SetVal(tc.released)
+
+			var token any
+			cmd.EXPECT().
+				EvalSha(gomock.Any(), gomock.Any(), []string{"key"}, gomock.Any(), int64(60000)).
+				DoAndReturn(func(_ context.Context, _ string, _ []string, args ...any) *redis.Cmd {
+					token = args[0]
+					return locked
+				})
+			cmd.EXPECT().
+				EvalSha(gomock.Any(), gomock.Any(), []string{"key"}, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, _ []string, args ...any) *redis.Cmd {
+					assert.Equal(t, token, args[0], "released with the token of the lock")
+					return released
+				})
+
+			l, err := lockredis.NewClient(cmd).TryLock(context.Background(), "key", time.Minute)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantErr, l.Unlock(context.Background()))
+		})
+	}
+}
@@ -0,0 +1,26 @@
+// Package lock 提供带令牌的分布式锁，支持看门狗自动续约和按 retry.Strategy 重试加锁。
+// 锁的存储由 Backend 实现，lock/redis 基于 Redis，lock/memory 用于测试和单机部署。
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrFailedToPreemptLock 锁已被其他持有者占用
+	ErrFailedToPreemptLock = errors.New("lock: 抢锁失败")
+	// ErrLockNotHold 锁已过期或被其他持有者占用
+	ErrLockNotHold = errors.New("lock: 未持有锁")
+)
+
+// Backend 锁的存储，所有操作都必须是原子的
+type Backend interface {
+	// Acquire 在 key 未被占用或已被 token 占用时占用 key，并设置过期时间
+	Acquire(ctx context.Context, key, token string, expiration time.Duration) (bool, error)
+	// Refresh 在 key 被 token 占用时重置过期时间
+	Refresh(ctx context.Context, key, token string, expiration time.Duration) (bool, error)
+	// Release 在 key 被 token 占用时释放 key
+	Release(ctx context.Context, key, token string) (bool, error)
+}
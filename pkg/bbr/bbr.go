@@ -0,0 +1,149 @@
+// Package bbr 提供参考 TCP BBR 拥塞控制的自适应限流: 统计窗口内每个桶的最大通过数 maxPass 和
+// 最小平均耗时 minRT，估算系统的最大并发 maxInFlight = maxPass * minRT * 每秒的桶数。
+// CPU 使用率超过阈值且当前并发超过 maxInFlight 时拒绝请求，过载结束后的 1 秒内仍按并发数限流。
+package bbr
+
+import (
+	"errors"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/apus-run/van/pkg/window"
+)
+
+// ErrLimitExceed 系统过载，请求被拒绝
+var ErrLimitExceed = errors.New("bbr: 系统过载, 请求被拒绝")
+
+// coolDown 过载结束后继续限流的时间
+const coolDown = time.Second
+
+// Stat 限流器的统计
+type Stat struct {
+	CPU         int64
+	InFlight    int64
+	MaxInFlight int64
+	MaxPass     int64
+	MinRT       int64
+}
+
+// Limiter 自适应限流器
+type Limiter struct {
+	cpuThreshold int64
+	cpu          func() int64
+	// pass 每个桶的完成请求数
+	pass *window.Window
+	// rt 每个桶的请求耗时，单位为毫秒
+	rt *window.Window
+	// bucketPerSecond 每秒的桶数
+	bucketPerSecond float64
+
+	inFlight atomic.Int64
+	// prevDrop 上次过载的时间，单位为纳秒
+	prevDrop atomic.Int64
+	now      func() time.Time
+}
+
+func New(opts ...Option) *Limiter {
+	options := Apply(opts...)
+	bucketDuration := options.Window / time.Duration(options.Bucket)
+	return &Limiter{
+		cpuThreshold:    options.CPUThreshold,
+		cpu:             options.CPU,
+		pass:            window.New(options.Bucket, bucketDuration),
+		rt:              window.New(options.Bucket, bucketDuration),
+		bucketPerSecond: float64(time.Second) / float64(bucketDuration),
+		now:             time.Now,
+	}
+}
+
+// Allow 判断请求是否可以执行，允许时返回请求结束后必须调用的 done
+func (l *Limiter) Allow() (func(), error) {
+	if l.shouldDrop() {
+		return nil, ErrLimitExceed
+	}
+	l.inFlight.Add(1)
+	start := l.now()
+	return func() {
+		if rt := l.now().Sub(start).Milliseconds(); rt > 0 {
+			l.rt.Add(float64(rt))
+		} else {
+			l.rt.Add(0)
+		}
+		l.inFlight.Add(-1)
+		l.pass.Add(1)
+	}, nil
+}
+
+// Stat 返回当前的统计
+func (l *Limiter) Stat() Stat {
+	return Stat{
+		CPU:         l.cpuUsage(),
+		InFlight:    l.inFlight.Load(),
+		MaxInFlight: l.maxInFlight(),
+		MaxPass:     l.maxPass(),
+		MinRT:       l.minRT(),
+	}
+}
+
+func (l *Limiter) shouldDrop() bool {
+	now := l.now().UnixNano()
+	if l.cpuUsage() < l.cpuThreshold {
+		prevDrop := l.prevDrop.Load()
+		if prevDrop == 0 {
+			return false
+		}
+		if time.Duration(now-prevDrop) <= coolDown {
+			return l.overloaded()
+		}
+		l.prevDrop.CompareAndSwap(prevDrop, 0)
+		return false
+	}
+
+	drop := l.overloaded()
+	if drop {
+		l.prevDrop.CompareAndSwap(0, now)
+	}
+	return drop
+}
+
+// overloaded 判断当前并发是否超过估算的最大并发
+func (l *Limiter) overloaded() bool {
+	inFlight := l.inFlight.Load()
+	return inFlight > 1 && inFlight > l.maxInFlight()
+}
+
+func (l *Limiter) cpuUsage() int64 {
+	if l.cpuThreshold <= 0 || l.cpu == nil {
+		return 0
+	}
+	return l.cpu()
+}
+
+func (l *Limiter) maxInFlight() int64 {
+	return int64(math.Floor(float64(l.maxPass()*l.minRT())*l.bucketPerSecond/1000 + 0.5))
+}
+
+// maxPass 返回已结束的桶中最大的完成请求数
+func (l *Limiter) maxPass() int64 {
+	var maxPass int64 = 1
+	for _, b := range l.pass.Buckets(false) {
+		maxPass = max(maxPass, int64(b.Sum))
+	}
+	return maxPass
+}
+
+// minRT 返回已结束的桶中最小的平均耗时，单位为毫秒
+func (l *Limiter) minRT() int64 {
+	minRT := math.MaxFloat64
+	for _, b := range l.rt.Buckets(false) {
+		if b.Count == 0 {
+			continue
+		}
+		minRT = math.Min(minRT, b.Sum/float64(b.Count))
+	}
+	if minRT == math.MaxFloat64 {
+		return 1
+	}
+	return max(int64(math.Ceil(minRT)), 1)
+}
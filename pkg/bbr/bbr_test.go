@@ -0,0 +1,93 @@
+package bbr
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	var cpu atomic.Int64
+	l := New(WithWindow(time.Second), WithBucket(10), WithCPU(cpu.Load))
+
+	// idle CPU, nothing is dropped
+	var dones []func()
+	for i := 0; i < 10; i++ {
+		done, err := l.Allow()
+		require.NoError(t, err)
+		dones = append(dones, done)
+	}
+	for _, done := range dones {
+		done()
+	}
+
+	// overloaded, the in-flight requests exceed the estimated capacity
+	cpu.Store(900)
+	done1, err := l.Allow()
+	require.NoError(t, err)
+	done2, err := l.Allow()
+	require.NoError(t, err)
+	_, err = l.Allow()
+	assert.ErrorIs(t, err, ErrLimitExceed)
+
+	stat := l.Stat()
+	assert.Equal(t, int64(900), stat.CPU)
+	assert.Equal(t, int64(2), stat.InFlight)
+
+	// still limited during the cool down
+	cpu.Store(100)
+	_, err = l.Allow()
+	assert.ErrorIs(t, err, ErrLimitExceed)
+
+	done1()
+	done2()
+	done, err := l.Allow()
+	require.NoError(t, err)
+	done()
+}
+
+func TestLimiter_CoolDown(t *testing.T) {
+	var cpu atomic.Int64
+	cpu.Store(900)
+	l := New(WithCPU(cpu.Load))
+
+	now := time.Unix(1700000000, 0)
+	l.now = func() time.Time { return now }
+
+	done1, err := l.Allow()
+	require.NoError(t, err)
+	_, err = l.Allow()
+	require.NoError(t, err)
+	_, err = l.Allow()
+	assert.ErrorIs(t, err, ErrLimitExceed)
+
+	cpu.Store(100)
+	now = now.Add(2 * coolDown)
+	done, err := l.Allow()
+	require.NoError(t, err, "not limited after the cool down")
+	done()
+	done1()
+}
+
+func TestLimiter_WithoutCPU(t *testing.T) {
+	l := New(WithCPUThreshold(0), WithCPU(func() int64 {
+		t.Fatal("CPU read")
+		return 0
+	}))
+
+	_, err := l.Allow()
+	require.NoError(t, err)
+	_, err = l.Allow()
+	require.NoError(t, err)
+	_, err = l.Allow()
+	assert.ErrorIs(t, err, ErrLimitExceed)
+}
+
+func TestCPUUsage(t *testing.T) {
+	usage := CPUUsage()
+	assert.GreaterOrEqual(t, usage, int64(0))
+	assert.LessOrEqual(t, usage, int64(1000))
+}
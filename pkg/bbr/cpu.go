@@ -0,0 +1,44 @@
+package bbr
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// cpuInterval CPU 使用率的采样间隔
+	cpuInterval = 500 * time.Millisecond
+	// cpuDecay 滑动平均的衰减系数
+	cpuDecay = 0.95
+)
+
+var (
+	cpuOnce  sync.Once
+	cpuUsage atomic.Int64
+)
+
+// CPUUsage 返回系统的 CPU 使用率，单位为千分之一，取值 [0, 1000]。
+// 首次调用时启动后台采样，不支持的系统上始终返回 0。
+func CPUUsage() int64 {
+	cpuOnce.Do(func() {
+		s := newCPUSampler()
+		if s == nil {
+			return
+		}
+		go func() {
+			ticker := time.NewTicker(cpuInterval)
+			defer ticker.Stop()
+			var avg float64
+			for range ticker.C {
+				usage, ok := s.sample()
+				if !ok {
+					continue
+				}
+				avg = avg*cpuDecay + usage*(1-cpuDecay)
+				cpuUsage.Store(int64(avg))
+			}
+		}()
+	})
+	return cpuUsage.Load()
+}
@@ -0,0 +1,66 @@
+//go:build linux
+
+package bbr
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// cpuSampler 读取 /proc/stat 计算两次采样之间的 CPU 使用率，不考虑 cgroup 的限制
+type cpuSampler struct {
+	idle, total uint64
+}
+
+func newCPUSampler() *cpuSampler {
+	s := &cpuSampler{}
+	if _, ok := s.sample(); !ok {
+		return nil
+	}
+	return s
+}
+
+// sample 返回距上次采样的 CPU 使用率，单位为千分之一
+func (s *cpuSampler) sample() (float64, bool) {
+	idle, total, ok := readProcStat()
+	if !ok {
+		return 0, false
+	}
+	dIdle, dTotal := idle-s.idle, total-s.total
+	s.idle, s.total = idle, total
+	if dTotal == 0 {
+		return 0, false
+	}
+	return float64(dTotal-dIdle) / float64(dTotal) * 1000, true
+}
+
+func readProcStat() (idle, total uint64, ok bool) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0, 0, false
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		// user nice system idle iowait irq softirq steal，guest 已计入 user
+		for i, field := range fields[1:min(len(fields), 9)] {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, 0, false
+			}
+			total += v
+			if i == 3 || i == 4 {
+				idle += v
+			}
+		}
+		return idle, total, true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package bbr
+
+type cpuSampler struct{}
+
+func newCPUSampler() *cpuSampler {
+	return nil
+}
+
+func (s *cpuSampler) sample() (float64, bool) {
+	return 0, false
+}
@@ -0,0 +1,63 @@
+package bbr
+
+import "time"
+
+// Option is config option.
+type Option func(*Options)
+
+type Options struct {
+	// Window 统计窗口的时长
+	Window time.Duration
+	// Bucket 统计窗口的桶数
+	Bucket int
+	// CPUThreshold CPU 使用率超过该值（千分之一）时开始限流，为 0 时只按并发数限流
+	CPUThreshold int64
+	// CPU 返回 CPU 使用率（千分之一），默认为 CPUUsage
+	CPU func() int64
+}
+
+// DefaultOptions .
+func DefaultOptions() *Options {
+	return &Options{
+		Window:       10 * time.Second,
+		Bucket:       100,
+		CPUThreshold: 800,
+		CPU:          CPUUsage,
+	}
+}
+
+func Apply(opts ...Option) *Options {
+	options := DefaultOptions()
+	for _, o := range opts {
+		o(options)
+	}
+	return options
+}
+
+// WithWindow 设置统计窗口的时长
+func WithWindow(d time.Duration) Option {
+	return func(o *Options) {
+		o.Window = d
+	}
+}
+
+// WithBucket 设置统计窗口的桶数
+func WithBucket(bucket int) Option {
+	return func(o *Options) {
+		o.Bucket = bucket
+	}
+}
+
+// WithCPUThreshold 设置开始限流的 CPU 使用率
+func WithCPUThreshold(threshold int64) Option {
+	return func(o *Options) {
+		o.CPUThreshold = threshold
+	}
+}
+
+// WithCPU 设置 CPU 使用率的来源
+func WithCPU(fn func() int64) Option {
+	return func(o *Options) {
+		o.CPU = fn
+	}
+}
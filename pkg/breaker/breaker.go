@@ -0,0 +1,129 @@
+// Package breaker 提供 Google SRE 的自适应熔断（客户端节流）:
+// 统计窗口内的请求数 requests 和成功数 accepts，以 max(0, (requests - K*accepts) / (requests + 1))
+// 的概率在本地拒绝请求，依赖恢复后拒绝的概率随成功数增加自动下降。
+// 参考 https://sre.google/sre-book/handling-overload/
+package breaker
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/apus-run/van/pkg/window"
+)
+
+// ErrNotAllowed 请求被熔断
+var ErrNotAllowed = errors.New("breaker: 熔断中, 请求被拒绝")
+
+// Breaker 熔断器
+type Breaker interface {
+	// Allow 判断请求是否可以执行，被熔断时返回 ErrNotAllowed
+	Allow() error
+	// MarkSuccess 记录一次成功的请求
+	MarkSuccess()
+	// MarkFailed 记录一次失败的请求
+	MarkFailed()
+}
+
+var _ Breaker = (*SRE)(nil)
+
+// SRE 自适应熔断器
+type SRE struct {
+	k       float64
+	request int64
+	// stat 的 Sum 为成功数，Count 为请求数
+	stat *window.Window
+	rand func() float64
+}
+
+func NewSRE(opts ...Option) *SRE {
+	options := Apply(opts...)
+	return &SRE{
+		k:       options.K,
+		request: options.Request,
+		stat:    window.New(options.Bucket, options.Window/time.Duration(options.Bucket)),
+		rand:    rand.Float64,
+	}
+}
+
+func (b *SRE) Allow() error {
+	if b.rand() < b.dropRatio() {
+		// 被拒绝的请求也计入请求数
+		b.MarkFailed()
+		return ErrNotAllowed
+	}
+	return nil
+}
+
+func (b *SRE) MarkSuccess() {
+	b.stat.Add(1)
+}
+
+func (b *SRE) MarkFailed() {
+	b.stat.Add(0)
+}
+
+// DropRatio 返回当前拒绝请求的概率
+func (b *SRE) DropRatio() float64 {
+	return b.dropRatio()
+}
+
+func (b *SRE) dropRatio() float64 {
+	stat := b.stat.Reduce()
+	requests := float64(stat.Count)
+	if stat.Count < b.request {
+		return 0
+	}
+	return math.Max(0, (requests-b.k*stat.Sum)/(requests+1))
+}
+
+// Group 按名称分组的熔断器，例如每个下游服务或接口一个熔断器
+type Group struct {
+	mu       sync.RWMutex
+	breakers map[string]Breaker
+	new      func() Breaker
+}
+
+// NewGroup 创建熔断器分组，new 为 nil 时使用默认配置的 SRE 熔断器
+func NewGroup(new func() Breaker) *Group {
+	if new == nil {
+		new = func() Breaker { return NewSRE() }
+	}
+	return &Group{
+		breakers: make(map[string]Breaker),
+		new:      new,
+	}
+}
+
+// Get 返回名称对应的熔断器，不存在时创建
+func (g *Group) Get(name string) Breaker {
+	g.mu.RLock()
+	b, ok := g.breakers[name]
+	g.mu.RUnlock()
+	if ok {
+		return b
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if b, ok = g.breakers[name]; !ok {
+		b = g.new()
+		g.breakers[name] = b
+	}
+	return b
+}
+
+// Do 在熔断器允许时执行 fn，并根据 fn 的结果记录成功或失败
+func Do(b Breaker, fn func() error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		b.MarkFailed()
+		return err
+	}
+	b.MarkSuccess()
+	return nil
+}
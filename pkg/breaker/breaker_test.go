@@ -0,0 +1,61 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSRE(t *testing.T) {
+	b := NewSRE(WithRequest(10), WithWindow(time.Minute))
+	b.rand = func() float64 { return 0.5 }
+
+	// not enough requests
+	for i := 0; i < 9; i++ {
+		b.MarkFailed()
+	}
+	assert.NoError(t, b.Allow())
+	assert.Equal(t, float64(0), b.DropRatio())
+
+	// every request failed
+	b.MarkFailed()
+	assert.InDelta(t, 10.0/11, b.DropRatio(), 1e-9)
+	assert.ErrorIs(t, b.Allow(), ErrNotAllowed)
+
+	// the dependency recovers, the rejected request counts as a request
+	for i := 0; i < 30; i++ {
+		b.MarkSuccess()
+	}
+	assert.Equal(t, float64(0), b.DropRatio())
+	assert.NoError(t, b.Allow())
+}
+
+func TestSRE_Expire(t *testing.T) {
+	b := NewSRE(WithRequest(1), WithWindow(100*time.Millisecond), WithBucket(2))
+	b.rand = func() float64 { return 0 }
+
+	b.MarkFailed()
+	b.MarkFailed()
+	assert.ErrorIs(t, b.Allow(), ErrNotAllowed)
+
+	time.Sleep(150 * time.Millisecond)
+	assert.NoError(t, b.Allow())
+}
+
+func TestDo(t *testing.T) {
+	b := NewSRE(WithRequest(2), WithK(1))
+	b.rand = func() float64 { return 0 }
+
+	boom := errors.New("boom")
+	assert.NoError(t, Do(b, func() error { return nil }))
+	assert.ErrorIs(t, Do(b, func() error { return boom }), boom)
+	assert.ErrorIs(t, Do(b, func() error { return nil }), ErrNotAllowed)
+}
+
+func TestGroup(t *testing.T) {
+	g := NewGroup(nil)
+	assert.Same(t, g.Get("user"), g.Get("user"))
+	assert.NotSame(t, g.Get("user"), g.Get("order"))
+}
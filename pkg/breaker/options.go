@@ -0,0 +1,63 @@
+package breaker
+
+import "time"
+
+// Option is config option.
+type Option func(*Options)
+
+type Options struct {
+	// K 倍率，越小越容易熔断，Google SRE 建议为 2，默认 1.5
+	K float64
+	// Request 窗口内的请求数少于 Request 时不熔断
+	Request int64
+	// Window 统计窗口的时长
+	Window time.Duration
+	// Bucket 统计窗口的桶数
+	Bucket int
+}
+
+// DefaultOptions .
+func DefaultOptions() *Options {
+	return &Options{
+		K:       1.5,
+		Request: 100,
+		Window:  3 * time.Second,
+		Bucket:  10,
+	}
+}
+
+func Apply(opts ...Option) *Options {
+	options := DefaultOptions()
+	for _, o := range opts {
+		o(options)
+	}
+	return options
+}
+
+// WithK 设置倍率
+func WithK(k float64) Option {
+	return func(o *Options) {
+		o.K = k
+	}
+}
+
+// WithRequest 设置开始熔断的最少请求数
+func WithRequest(request int64) Option {
+	return func(o *Options) {
+		o.Request = request
+	}
+}
+
+// WithWindow 设置统计窗口的时长
+func WithWindow(d time.Duration) Option {
+	return func(o *Options) {
+		o.Window = d
+	}
+}
+
+// WithBucket 设置统计窗口的桶数
+func WithBucket(bucket int) Option {
+	return func(o *Options) {
+		o.Bucket = bucket
+	}
+}
@@ -296,3 +296,27 @@ func ObjectGoPrintSideBySide(a, b interface{}) string {
 	w.Flush()
 	return buf.String()
 }
+
+// ObjectReflectDiffPaths returns the sorted paths of the fields differing between a and b,
+// relative to the objects, e.g. "DB.DSN" or "Hosts[1]". It returns "" when a and b are
+// scalars which differ, and nil when they are equal.
+func ObjectReflectDiffPaths(a, b interface{}) []string {
+	vA, vB := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !vA.IsValid() || !vB.IsValid() || vA.Type() != vB.Type() {
+		if reflect.DeepEqual(a, b) {
+			return nil
+		}
+		return []string{""}
+	}
+	diffs := objectReflectDiff(field.NewPath("object"), vA, vB)
+	if len(diffs) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		p := strings.TrimPrefix(d.path.String(), "object")
+		paths = append(paths, strings.TrimPrefix(p, "."))
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -130,3 +130,25 @@ func TestLimit(t *testing.T) {
 		}
 	}
 }
+
+func TestObjectReflectDiffPaths(t *testing.T) {
+	type db struct{ DSN string }
+	type config struct {
+		DB    db
+		Hosts []string
+		Tags  map[string]string
+	}
+	a := config{DB: db{DSN: "a"}, Hosts: []string{"h1"}, Tags: map[string]string{"env": "dev"}}
+	b := config{DB: db{DSN: "b"}, Hosts: []string{"h1", "h2"}, Tags: map[string]string{"env": "dev"}}
+
+	if paths := ObjectReflectDiffPaths(a, a); paths != nil {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+	paths := ObjectReflectDiffPaths(a, b)
+	if len(paths) != 2 || paths[0] != "DB.DSN" || paths[1] != "Hosts[1]" {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+	if paths := ObjectReflectDiffPaths(1, 2); len(paths) != 1 || paths[0] != "" {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+}
@@ -0,0 +1,102 @@
+// Package window 提供按时间分桶的滑动窗口，用于熔断和自适应限流的统计。
+package window
+
+import (
+	"sync"
+	"time"
+)
+
+// Bucket 一个时间桶的统计
+type Bucket struct {
+	// Sum 桶内所有值的和
+	Sum float64
+	// Count 桶内值的个数
+	Count int64
+}
+
+// Window 由 size 个时长为 bucketDuration 的桶组成的滑动窗口，过期的桶会被重置
+type Window struct {
+	mu             sync.Mutex
+	buckets        []Bucket
+	bucketDuration time.Duration
+	// offset 当前桶的下标
+	offset int
+	// lastAppend 当前桶的开始时间
+	lastAppend time.Time
+	now        func() time.Time
+}
+
+func New(size int, bucketDuration time.Duration) *Window {
+	return newWindow(size, bucketDuration, time.Now)
+}
+
+func newWindow(size int, bucketDuration time.Duration, now func() time.Time) *Window {
+	if size <= 0 {
+		size = 1
+	}
+	return &Window{
+		buckets:        make([]Bucket, size),
+		bucketDuration: bucketDuration,
+		lastAppend:     now(),
+		now:            now,
+	}
+}
+
+// Add 向当前桶添加一个值
+func (w *Window) Add(v float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.advance()
+	b := &w.buckets[w.offset]
+	b.Sum += v
+	b.Count++
+}
+
+// Buckets 按时间顺序返回未过期的桶，includeCurrent 为 false 时不包含未结束的当前桶
+func (w *Window) Buckets(includeCurrent bool) []Bucket {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.advance()
+	size := len(w.buckets)
+	n := size
+	if !includeCurrent {
+		n--
+	}
+	// 最早的桶在当前桶之后，当前桶在最后
+	buckets := make([]Bucket, 0, n)
+	for i := 1; i <= n; i++ {
+		buckets = append(buckets, w.buckets[(w.offset+i)%size])
+	}
+	return buckets
+}
+
+// Reduce 对未过期的桶求和
+func (w *Window) Reduce() Bucket {
+	var total Bucket
+	for _, b := range w.Buckets(true) {
+		total.Sum += b.Sum
+		total.Count += b.Count
+	}
+	return total
+}
+
+// BucketDuration 返回桶的时长
+func (w *Window) BucketDuration() time.Duration {
+	return w.bucketDuration
+}
+
+// advance 将当前桶移动到当前时间，并重置经过的桶
+func (w *Window) advance() {
+	span := int(w.now().Sub(w.lastAppend) / w.bucketDuration)
+	if span <= 0 {
+		return
+	}
+	size := len(w.buckets)
+	for i := 1; i <= span && i <= size; i++ {
+		w.buckets[(w.offset+i)%size] = Bucket{}
+	}
+	w.offset = (w.offset + span) % size
+	w.lastAppend = w.lastAppend.Add(time.Duration(span) * w.bucketDuration)
+}
@@ -0,0 +1,32 @@
+package window
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindow(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	w := newWindow(3, 100*time.Millisecond, func() time.Time { return now })
+
+	w.Add(1)
+	w.Add(2)
+	assert.Equal(t, Bucket{Sum: 3, Count: 2}, w.Reduce())
+
+	now = now.Add(100 * time.Millisecond)
+	w.Add(4)
+	assert.Equal(t, []Bucket{{}, {Sum: 3, Count: 2}, {Sum: 4, Count: 1}}, w.Buckets(true))
+	assert.Equal(t, []Bucket{{}, {Sum: 3, Count: 2}}, w.Buckets(false))
+
+	// the first bucket expires
+	now = now.Add(250 * time.Millisecond)
+	assert.Equal(t, Bucket{Sum: 4, Count: 1}, w.Reduce())
+	w.Add(8)
+	assert.Equal(t, []Bucket{{Sum: 4, Count: 1}, {}, {Sum: 8, Count: 1}}, w.Buckets(true))
+
+	// every bucket expires
+	now = now.Add(time.Second)
+	assert.Equal(t, Bucket{}, w.Reduce())
+}
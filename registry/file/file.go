@@ -0,0 +1,233 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apus-run/van/registry"
+	"github.com/apus-run/van/registry/internal/watch"
+)
+
+var _ registry.Registry = (*Registry)(nil)
+
+var (
+	// ErrInvalidInstance is returned when registering an instance without a valid name or id.
+	ErrInvalidInstance = errors.New("registry: instance name and id are required")
+	// ErrInvalidName is returned for a service name which is not a valid directory name.
+	ErrInvalidName = errors.New("registry: invalid service name")
+)
+
+const (
+	ext = ".json"
+
+	// defaultInterval is the watch polling interval when TTL is not set.
+	defaultInterval = time.Second
+)
+
+// Registry is a registry backed by a shared directory, each instance is stored
+// in <dir>/<name>/<id>.json. Instances are kept alive by touching their file
+// every TTL/3, files not modified within TTL are considered expired and removed.
+// A zero TTL disables expiration.
+type Registry struct {
+	dir     string
+	options *registry.Options
+
+	mu         sync.Mutex
+	heartbeats map[string]context.CancelFunc
+}
+
+// New create a registry storing instances under dir.
+func New(dir string, opts ...registry.Option) *Registry {
+	o := &registry.Options{
+		Context: context.Background(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Registry{
+		dir:        dir,
+		options:    o,
+		heartbeats: make(map[string]context.CancelFunc),
+	}
+}
+
+// Register implements registry.Registry.
+func (r *Registry) Register(_ context.Context, ins *registry.ServiceInstance) error {
+	if ins == nil || !validName(ins.Name) || !validName(ins.ID) {
+		return ErrInvalidInstance
+	}
+	data, err := json.Marshal(ins)
+	if err != nil {
+		return err
+	}
+	path := r.path(ins.Name, ins.ID)
+	if err = write(path, data); err != nil {
+		return err
+	}
+
+	if r.options.TTL > 0 {
+		ctx, cancel := context.WithCancel(r.options.Context)
+		r.mu.Lock()
+		if stop, ok := r.heartbeats[path]; ok {
+			stop()
+		}
+		r.heartbeats[path] = cancel
+		r.mu.Unlock()
+		go r.heartbeat(ctx, path, data)
+	}
+	return nil
+}
+
+// Deregister implements registry.Registry.
+func (r *Registry) Deregister(_ context.Context, ins *registry.ServiceInstance) error {
+	if ins == nil || !validName(ins.Name) || !validName(ins.ID) {
+		return ErrInvalidInstance
+	}
+	path := r.path(ins.Name, ins.ID)
+
+	r.mu.Lock()
+	if stop, ok := r.heartbeats[path]; ok {
+		stop()
+		delete(r.heartbeats, path)
+	}
+	r.mu.Unlock()
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// GetService implements registry.Registry.
+func (r *Registry) GetService(_ context.Context, name string) ([]*registry.ServiceInstance, error) {
+	if !validName(name) {
+		return nil, ErrInvalidName
+	}
+	dir := filepath.Join(r.dir, url.PathEscape(name))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	instances := make([]*registry.ServiceInstance, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ext) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if r.options.TTL > 0 && now.Sub(info.ModTime()) > r.options.TTL {
+			// the owner stopped heartbeating, clean up on its behalf.
+			_ = os.Remove(path)
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		ins := &registry.ServiceInstance{}
+		if err = json.Unmarshal(data, ins); err != nil {
+			continue
+		}
+		instances = append(instances, ins)
+	}
+	return instances, nil
+}
+
+// Watch implements registry.Registry, the directory is polled every TTL/2.
+func (r *Registry) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	w := watch.New(ctx, func(ctx context.Context) ([]*registry.ServiceInstance, error) {
+		return r.GetService(ctx, name)
+	})
+
+	interval := defaultInterval
+	if r.options.TTL > 0 {
+		interval = r.options.TTL / 2
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.Done():
+				return
+			case <-ticker.C:
+				w.Notify()
+			}
+		}
+	}()
+	return w, nil
+}
+
+// Close stops heartbeats of the registered instances, they expire after TTL.
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for path, stop := range r.heartbeats {
+		stop()
+		delete(r.heartbeats, path)
+	}
+	return nil
+}
+
+func (r *Registry) heartbeat(ctx context.Context, path string, data []byte) {
+	ticker := time.NewTicker(r.options.TTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			if err := os.Chtimes(path, now, now); err != nil {
+				// the file was removed by someone else, write it back.
+				_ = write(path, data)
+			}
+		}
+	}
+}
+
+// validName reports whether the escaped name is a file name of the registry directory,
+// url.PathEscape escapes the separators but not the dots of "." and "..".
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".."
+}
+
+func (r *Registry) path(name, id string) string {
+	return filepath.Join(r.dir, url.PathEscape(name), url.PathEscape(id)+ext)
+}
+
+// write writes data to path atomically so readers never see partial files.
+func write(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
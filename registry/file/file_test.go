@@ -0,0 +1,97 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/registry"
+)
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	r := New(dir, registry.WithTTL(60*time.Millisecond))
+	ins := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "van",
+		Version:   "v1.0.0",
+		Metadata:  map[string]string{"zone": "a"},
+		Endpoints: []string{"http://127.0.0.1:8000"},
+	}
+
+	require.NoError(t, r.Register(ctx, ins))
+	assert.FileExists(t, filepath.Join(dir, "van", "1.json"))
+
+	// another process sharing the directory.
+	peer := New(dir, registry.WithTTL(60*time.Millisecond))
+	w, err := peer.Watch(ctx, "van")
+	require.NoError(t, err)
+	defer w.Stop()
+
+	got, err := w.Next()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.True(t, got[0].Equal(ins))
+
+	ins2 := &registry.ServiceInstance{ID: "2", Name: "van", Endpoints: []string{"grpc://127.0.0.1:9000"}}
+	require.NoError(t, peer.Register(ctx, ins2))
+	got, err = w.Next()
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	require.NoError(t, peer.Deregister(ctx, ins2))
+	got, err = w.Next()
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	// heartbeats keep the instance alive past its TTL.
+	time.Sleep(150 * time.Millisecond)
+	got, err = peer.GetService(ctx, "van")
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	// without heartbeats it expires and the watcher is notified.
+	require.NoError(t, r.Close())
+	got, err = w.Next()
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	_, err = os.Stat(filepath.Join(dir, "van", "1.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRegistry_NoService(t *testing.T) {
+	r := New(t.TempDir())
+	got, err := r.GetService(context.Background(), "missing")
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+	assert.NoError(t, r.Deregister(context.Background(), &registry.ServiceInstance{ID: "1", Name: "missing"}))
+}
+
+func TestRegistry_InvalidName(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "registry")
+	r := New(dir)
+
+	for _, ins := range []*registry.ServiceInstance{
+		{ID: "1", Name: ".."},
+		{ID: "1", Name: "."},
+		{ID: "..", Name: "van"},
+		{ID: "", Name: "van"},
+	} {
+		assert.ErrorIs(t, r.Register(ctx, ins), ErrInvalidInstance, ins.Name+"/"+ins.ID)
+		assert.ErrorIs(t, r.Deregister(ctx, ins), ErrInvalidInstance, ins.Name+"/"+ins.ID)
+	}
+	_, err := r.GetService(ctx, "..")
+	assert.ErrorIs(t, err, ErrInvalidName)
+
+	// 没有文件写到注册目录之外
+	entries, err := os.ReadDir(filepath.Dir(dir))
+	require.NoError(t, err)
+	assert.Len(t, entries, 0)
+}
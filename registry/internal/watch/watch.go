@@ -0,0 +1,127 @@
+package watch
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/apus-run/van/registry"
+)
+
+var _ registry.Watcher = (*Watcher)(nil)
+
+// Fetch returns the current instances of the watched service.
+type Fetch func(ctx context.Context) ([]*registry.ServiceInstance, error)
+
+// Watcher is a registry.Watcher which re-fetches the instances of a service
+// every time it is notified, and only returns from Next when they changed.
+type Watcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	fetch  Fetch
+	event  chan struct{}
+
+	mu    sync.Mutex
+	first bool
+	last  []*registry.ServiceInstance
+}
+
+// New create a watcher, it stops when ctx is done or Stop is called.
+func New(ctx context.Context, fetch Fetch) *Watcher {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Watcher{
+		ctx:    ctx,
+		cancel: cancel,
+		fetch:  fetch,
+		event:  make(chan struct{}, 1),
+		first:  true,
+	}
+}
+
+// Notify signals the watcher the instances may have changed, it never blocks.
+func (w *Watcher) Notify() {
+	select {
+	case w.event <- struct{}{}:
+	default:
+	}
+}
+
+// Done is closed when the watcher stops.
+func (w *Watcher) Done() <-chan struct{} {
+	return w.ctx.Done()
+}
+
+// Next implements registry.Watcher.
+func (w *Watcher) Next() ([]*registry.ServiceInstance, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.first {
+		w.first = false
+		ins, err := w.fetch(w.ctx)
+		if err != nil {
+			return nil, err
+		}
+		w.last = ins
+		if len(ins) > 0 {
+			return ins, nil
+		}
+	}
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case <-w.event:
+		}
+		ins, err := w.fetch(w.ctx)
+		if err != nil {
+			return nil, err
+		}
+		if Equal(w.last, ins) {
+			continue
+		}
+		w.last = ins
+		return ins, nil
+	}
+}
+
+// Stop implements registry.Watcher.
+func (w *Watcher) Stop() error {
+	w.cancel()
+	return nil
+}
+
+// Equal reports whether a and b hold the same instances, regardless of order.
+func Equal(a, b []*registry.ServiceInstance) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = sorted(a), sorted(b)
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sorted(ins []*registry.ServiceInstance) []*registry.ServiceInstance {
+	s := make([]*registry.ServiceInstance, len(ins))
+	copy(s, ins)
+	sort.Slice(s, func(i, j int) bool { return s[i].ID < s[j].ID })
+	return s
+}
+
+// Clone returns a deep copy of ins.
+func Clone(ins *registry.ServiceInstance) *registry.ServiceInstance {
+	c := *ins
+	if ins.Metadata != nil {
+		c.Metadata = make(map[string]string, len(ins.Metadata))
+		for k, v := range ins.Metadata {
+			c.Metadata[k] = v
+		}
+	}
+	c.Endpoints = append([]string(nil), ins.Endpoints...)
+	return &c
+}
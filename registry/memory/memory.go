@@ -0,0 +1,225 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/apus-run/van/registry"
+	"github.com/apus-run/van/registry/internal/watch"
+)
+
+var _ registry.Registry = (*Registry)(nil)
+
+// ErrInvalidInstance is returned when registering an instance without name or id.
+var ErrInvalidInstance = errors.New("registry: instance name and id are required")
+
+type entry struct {
+	ins      *registry.ServiceInstance
+	expireAt time.Time
+	cancel   context.CancelFunc
+}
+
+// Registry is an in-process registry, instances are kept alive by heartbeats
+// every TTL/3 and expire when not refreshed within TTL. A zero TTL disables expiration.
+type Registry struct {
+	options *registry.Options
+
+	mu       sync.Mutex
+	services map[string]map[string]*entry
+	watchers map[string]map[*watch.Watcher]struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New create an in-process registry.
+func New(opts ...registry.Option) *Registry {
+	o := &registry.Options{
+		Context: context.Background(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	ctx, cancel := context.WithCancel(o.Context)
+	r := &Registry{
+		options:  o,
+		services: make(map[string]map[string]*entry),
+		watchers: make(map[string]map[*watch.Watcher]struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	if o.TTL > 0 {
+		go r.reap()
+	}
+	return r
+}
+
+// Register implements registry.Registry.
+func (r *Registry) Register(_ context.Context, ins *registry.ServiceInstance) error {
+	if ins == nil || ins.Name == "" || ins.ID == "" {
+		return ErrInvalidInstance
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	instances, ok := r.services[ins.Name]
+	if !ok {
+		instances = make(map[string]*entry)
+		r.services[ins.Name] = instances
+	}
+	if old, ok := instances[ins.ID]; ok && old.cancel != nil {
+		old.cancel()
+	}
+	e := &entry{ins: watch.Clone(ins)}
+	if r.options.TTL > 0 {
+		var ctx context.Context
+		ctx, e.cancel = context.WithCancel(r.ctx)
+		e.expireAt = time.Now().Add(r.options.TTL)
+		go r.heartbeat(ctx, e)
+	}
+	instances[ins.ID] = e
+	r.notify(ins.Name)
+	return nil
+}
+
+// Deregister implements registry.Registry.
+func (r *Registry) Deregister(_ context.Context, ins *registry.ServiceInstance) error {
+	if ins == nil {
+		return ErrInvalidInstance
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	instances := r.services[ins.Name]
+	e, ok := instances[ins.ID]
+	if !ok {
+		return nil
+	}
+	if e.cancel != nil {
+		e.cancel()
+	}
+	delete(instances, ins.ID)
+	if len(instances) == 0 {
+		delete(r.services, ins.Name)
+	}
+	r.notify(ins.Name)
+	return nil
+}
+
+// GetService implements registry.Registry.
+func (r *Registry) GetService(_ context.Context, name string) ([]*registry.ServiceInstance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	instances := make([]*registry.ServiceInstance, 0, len(r.services[name]))
+	for _, e := range r.services[name] {
+		if r.expired(e, now) {
+			continue
+		}
+		instances = append(instances, watch.Clone(e.ins))
+	}
+	return instances, nil
+}
+
+// Watch implements registry.Registry.
+func (r *Registry) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	w := watch.New(ctx, func(ctx context.Context) ([]*registry.ServiceInstance, error) {
+		return r.GetService(ctx, name)
+	})
+
+	r.mu.Lock()
+	if r.watchers[name] == nil {
+		r.watchers[name] = make(map[*watch.Watcher]struct{})
+	}
+	r.watchers[name][w] = struct{}{}
+	r.mu.Unlock()
+
+	go func() {
+		<-w.Done()
+		r.mu.Lock()
+		delete(r.watchers[name], w)
+		if len(r.watchers[name]) == 0 {
+			delete(r.watchers, name)
+		}
+		r.mu.Unlock()
+	}()
+	return w, nil
+}
+
+// Close stops heartbeats of the registered instances and the reaping of the expired ones,
+// so with a TTL the instances are removed at once and their watchers are notified.
+func (r *Registry) Close() error {
+	r.cancel()
+	if r.options.TTL <= 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range r.services {
+		delete(r.services, name)
+		r.notify(name)
+	}
+	return nil
+}
+
+func (r *Registry) heartbeat(ctx context.Context, e *entry) {
+	ticker := time.NewTicker(r.options.TTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			e.expireAt = time.Now().Add(r.options.TTL)
+			r.mu.Unlock()
+		}
+	}
+}
+
+// reap removes the expired instances and notifies their watchers.
+func (r *Registry) reap() {
+	ticker := time.NewTicker(r.options.TTL / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		// r.ctx is also done with the context of the options
+		case <-r.ctx.Done():
+			return
+		}
+
+		r.mu.Lock()
+		now := time.Now()
+		for name, instances := range r.services {
+			changed := false
+			for id, e := range instances {
+				if r.expired(e, now) {
+					delete(instances, id)
+					changed = true
+				}
+			}
+			if len(instances) == 0 {
+				delete(r.services, name)
+			}
+			if changed {
+				r.notify(name)
+			}
+		}
+		r.mu.Unlock()
+	}
+}
+
+func (r *Registry) expired(e *entry, now time.Time) bool {
+	return r.options.TTL > 0 && now.After(e.expireAt)
+}
+
+// notify must be called with r.mu held.
+func (r *Registry) notify(name string) {
+	for w := range r.watchers[name] {
+		w.Notify()
+	}
+}
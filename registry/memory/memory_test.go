@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/registry"
+)
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	r := New()
+	ins := &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "van",
+		Version:   "v1.0.0",
+		Endpoints: []string{"http://127.0.0.1:8000"},
+	}
+
+	assert.ErrorIs(t, r.Register(ctx, &registry.ServiceInstance{Name: "van"}), ErrInvalidInstance)
+
+	w, err := r.Watch(ctx, "van")
+	require.NoError(t, err)
+	defer w.Stop()
+
+	require.NoError(t, r.Register(ctx, ins))
+	got, err := w.Next()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.True(t, got[0].Equal(ins))
+
+	got, err = r.GetService(ctx, "van")
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	ins2 := &registry.ServiceInstance{ID: "2", Name: "van", Endpoints: []string{"grpc://127.0.0.1:9000"}}
+	require.NoError(t, r.Register(ctx, ins2))
+	got, err = w.Next()
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	require.NoError(t, r.Deregister(ctx, ins))
+	got, err = w.Next()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "2", got[0].ID)
+
+	require.NoError(t, w.Stop())
+	_, err = w.Next()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRegistry_TTL(t *testing.T) {
+	ctx := context.Background()
+	r := New(registry.WithTTL(60 * time.Millisecond))
+	ins := &registry.ServiceInstance{ID: "1", Name: "van"}
+	require.NoError(t, r.Register(ctx, ins))
+
+	w, err := r.Watch(ctx, "van")
+	require.NoError(t, err)
+	defer w.Stop()
+	got, err := w.Next()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	// heartbeats keep the instance alive past its TTL.
+	time.Sleep(150 * time.Millisecond)
+	got, err = r.GetService(ctx, "van")
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	// closing removes the instance and notifies the watcher.
+	require.NoError(t, r.Close())
+	got, err = w.Next()
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	got, err = r.GetService(ctx, "van")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestRegistry_CloseStopsReap(t *testing.T) {
+	r := New(registry.WithTTL(20 * time.Millisecond))
+	require.NoError(t, r.Close())
+
+	// an instance registered after Close has no heartbeat, and is no longer reaped.
+	ins := &registry.ServiceInstance{ID: "1", Name: "van"}
+	require.NoError(t, r.Register(context.Background(), ins))
+	time.Sleep(60 * time.Millisecond)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	assert.Len(t, r.services["van"], 1)
+}
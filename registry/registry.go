@@ -105,5 +105,21 @@ func (n *noopRegistry) GetService(ctx context.Context, name string) ([]*ServiceI
 	return nil, nil
 }
 func (n *noopRegistry) Watch(ctx context.Context, name string) (Watcher, error) {
-	return nil, nil
+	ctx, cancel := context.WithCancel(ctx)
+	return &noopWatcher{ctx: ctx, cancel: cancel}, nil
+}
+
+// noopWatcher never finds any instance, Next blocks until it is stopped.
+type noopWatcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (w *noopWatcher) Next() ([]*ServiceInstance, error) {
+	<-w.ctx.Done()
+	return nil, w.ctx.Err()
+}
+func (w *noopWatcher) Stop() error {
+	w.cancel()
+	return nil
 }
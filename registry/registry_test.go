@@ -14,3 +14,11 @@ func TestRegistry(t *testing.T) {
 	assert.Nil(t, reg.Deregister(ctx, &ServiceInstance{}))
 	assert.Nil(t, reg.Register(ctx, &ServiceInstance{}))
 }
+
+func TestNoopWatcher(t *testing.T) {
+	w, err := NoopRegistry.Watch(context.Background(), "van")
+	assert.NoError(t, err)
+	assert.NoError(t, w.Stop())
+	_, err = w.Next()
+	assert.ErrorIs(t, err, context.Canceled)
+}
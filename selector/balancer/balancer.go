@@ -0,0 +1,111 @@
+package balancer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+
+	"github.com/apus-run/van/selector"
+	"github.com/apus-run/van/selector/resolver"
+)
+
+// The names of the balancers picking with the pickers of the selector package.
+const (
+	RoundRobin     = "van_round_robin"
+	WeightedRandom = "van_weighted_random"
+	P2C            = "van_p2c"
+	ConsistentHash = "van_consistent_hash"
+)
+
+func init() {
+	Register(RoundRobin, func() selector.Picker { return selector.NewRoundRobin() })
+	Register(WeightedRandom, func() selector.Picker { return selector.NewWeightedRandom() })
+	Register(P2C, func() selector.Picker { return selector.NewP2C() })
+	Register(ConsistentHash, func() selector.Picker { return selector.NewConsistentHash() })
+}
+
+// Register registers a gRPC balancer named name, it picks the ready subconns with a
+// picker of newPicker per ClientConn. The nodes of the subconns are those of the
+// discovery resolver, so that the weights and the metadata of the instances apply.
+func Register(name string, newPicker func() selector.Picker) {
+	balancer.Register(&builder{name: name, newPicker: newPicker})
+}
+
+// WithBalancer selects the balancer name through the default service config, e.g.
+//
+//	conn, err := grpc.NewClient("discovery:///user-service",
+//		grpc.WithResolvers(resolver.NewBuilder(r)),
+//		balancer.WithBalancer(balancer.P2C))
+func WithBalancer(name string) grpc.DialOption {
+	return grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}]}`, name))
+}
+
+type builder struct {
+	name      string
+	newPicker func() selector.Picker
+}
+
+// Name implements balancer.Builder.
+func (b *builder) Name() string {
+	return b.name
+}
+
+// Build implements balancer.Builder, the picker state, such as the P2C stats,
+// is not shared by the ClientConns.
+func (b *builder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	pb := &pickerBuilder{picker: b.newPicker()}
+	return base.NewBalancerBuilder(b.name, pb, base.Config{HealthCheck: true}).Build(cc, opts)
+}
+
+type pickerBuilder struct {
+	picker selector.Picker
+}
+
+// Build implements base.PickerBuilder.
+func (b *pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &picker{
+		picker:   b.picker,
+		nodes:    make([]*selector.Node, 0, len(info.ReadySCs)),
+		subConns: make(map[*selector.Node]balancer.SubConn, len(info.ReadySCs)),
+	}
+	for sc, sci := range info.ReadySCs {
+		node, ok := resolver.NodeFromAddress(sci.Address)
+		if !ok {
+			node = &selector.Node{Scheme: "grpc", Address: sci.Address.Addr, Weight: selector.DefaultWeight}
+		}
+		p.nodes = append(p.nodes, node)
+		p.subConns[node] = sc
+	}
+	// the ready subconns are a map, the pickers are given the nodes in a stable order
+	slices.SortFunc(p.nodes, func(a, b *selector.Node) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+	return p
+}
+
+type picker struct {
+	picker   selector.Picker
+	nodes    []*selector.Node
+	subConns map[*selector.Node]balancer.SubConn
+}
+
+// Pick implements balancer.Picker.
+func (p *picker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	node, done, err := p.picker.Pick(info.Ctx, p.nodes)
+	if err != nil {
+		return balancer.PickResult{}, err
+	}
+	return balancer.PickResult{
+		SubConn: p.subConns[node],
+		Done: func(di balancer.DoneInfo) {
+			done(info.Ctx, selector.DoneInfo{Err: di.Err})
+		},
+	}, nil
+}
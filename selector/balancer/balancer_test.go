@@ -0,0 +1,124 @@
+package balancer
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/apus-run/van/registry"
+	"github.com/apus-run/van/registry/memory"
+	"github.com/apus-run/van/selector"
+	"github.com/apus-run/van/selector/resolver"
+)
+
+// countingPicker 统计 Pick 与 done 的次数
+type countingPicker struct {
+	selector.Picker
+	picks, dones *atomic.Int32
+}
+
+func (p countingPicker) Pick(ctx context.Context, nodes []*selector.Node) (*selector.Node, selector.DoneFunc, error) {
+	p.picks.Add(1)
+	node, done, err := p.Picker.Pick(ctx, nodes)
+	return node, func(ctx context.Context, di selector.DoneInfo) {
+		p.dones.Add(1)
+		done(ctx, di)
+	}, err
+}
+
+// newServers starts n gRPC servers registered as the instances of van, and returns
+// the calls served by each.
+func newServers(t *testing.T, n int) (registry.Registry, []*atomic.Int32) {
+	ctx := context.Background()
+	r := memory.New()
+	calls := make([]*atomic.Int32, n)
+	for i := range calls {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		served := new(atomic.Int32)
+		calls[i] = served
+		srv := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			served.Add(1)
+			return handler(ctx, req)
+		}))
+		healthpb.RegisterHealthServer(srv, health.NewServer())
+		go func() { _ = srv.Serve(lis) }()
+		t.Cleanup(srv.Stop)
+
+		require.NoError(t, r.Register(ctx, &registry.ServiceInstance{
+			ID:        strconv.Itoa(i),
+			Name:      "van",
+			Endpoints: []string{"grpc://" + lis.Addr().String()},
+		}))
+	}
+	return r, calls
+}
+
+func dial(t *testing.T, r registry.Registry, name string) healthpb.HealthClient {
+	conn, err := grpc.NewClient("discovery:///van",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithResolvers(resolver.NewBuilder(r)),
+		WithBalancer(name),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestBalancer(t *testing.T) {
+	var picks, dones atomic.Int32
+	Register("van_test_counting", func() selector.Picker {
+		return countingPicker{Picker: selector.NewRoundRobin(), picks: &picks, dones: &dones}
+	})
+	r, calls := newServers(t, 2)
+	client := dial(t, r, "van_test_counting")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	// 等待两个 subconn 都就绪
+	require.Eventually(t, func() bool {
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		return err == nil && calls[0].Load() > 0 && calls[1].Load() > 0
+	}, 3*time.Second, time.Millisecond)
+
+	before := [2]int32{calls[0].Load(), calls[1].Load()}
+	for i := 0; i < 10; i++ {
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(5), calls[0].Load()-before[0], "round-robin over the two nodes")
+	assert.Equal(t, int32(5), calls[1].Load()-before[1], "round-robin over the two nodes")
+	assert.Equal(t, picks.Load(), dones.Load())
+	assert.Equal(t, calls[0].Load()+calls[1].Load(), picks.Load())
+}
+
+func TestBalancer_ConsistentHash(t *testing.T) {
+	r, calls := newServers(t, 2)
+	client := dial(t, r, ConsistentHash)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	require.Eventually(t, func() bool {
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		return err == nil && calls[0].Load() > 0 && calls[1].Load() > 0
+	}, 3*time.Second, time.Millisecond)
+
+	before := [2]int32{calls[0].Load(), calls[1].Load()}
+	hctx := selector.WithHashKey(ctx, "user:1")
+	for i := 0; i < 10; i++ {
+		_, err := client.Check(hctx, &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+	}
+	served := [2]int32{calls[0].Load() - before[0], calls[1].Load() - before[1]}
+	assert.ElementsMatch(t, []int32{0, 10}, served[:], "the calls of a key go to one node")
+}
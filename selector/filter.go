@@ -0,0 +1,38 @@
+package selector
+
+import (
+	"context"
+)
+
+// Filter filters the candidate nodes of a selection.
+type Filter func(ctx context.Context, nodes []*Node) []*Node
+
+// Version keeps the nodes of the given version.
+func Version(version string) Filter {
+	return func(_ context.Context, nodes []*Node) []*Node {
+		filtered := make([]*Node, 0, len(nodes))
+		for _, n := range nodes {
+			if n.Version == version {
+				filtered = append(filtered, n)
+			}
+		}
+		return filtered
+	}
+}
+
+// Metadata keeps the nodes whose metadata contains every md pair.
+func Metadata(md map[string]string) Filter {
+	return func(_ context.Context, nodes []*Node) []*Node {
+		filtered := make([]*Node, 0, len(nodes))
+	next:
+		for _, n := range nodes {
+			for k, v := range md {
+				if n.Metadata[k] != v {
+					continue next
+				}
+			}
+			filtered = append(filtered, n)
+		}
+		return filtered
+	}
+}
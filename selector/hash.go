@@ -0,0 +1,102 @@
+package selector
+
+import (
+	"context"
+	"hash/crc32"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// replicas is the number of virtual nodes of a node of DefaultWeight.
+const replicas = 160
+
+type hashKey struct{}
+
+// WithHashKey returns a copy of ctx carrying the key consistent hash pickers hash on.
+func WithHashKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, hashKey{}, key)
+}
+
+// HashKeyFromContext returns the key set by WithHashKey.
+func HashKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(hashKey{}).(string)
+	return key, ok
+}
+
+var _ Picker = (*ConsistentHash)(nil)
+
+// ConsistentHash picks the node owning the context hash key on a ring of
+// virtual nodes, the number of virtual nodes is proportional to the weight.
+// Without hash key a random node is picked.
+type ConsistentHash struct {
+	mu   sync.Mutex
+	sig  string
+	ring *ring
+}
+
+type ring struct {
+	hashes []uint32
+	nodes  map[uint32]*Node
+}
+
+// NewConsistentHash create a consistent hash picker.
+func NewConsistentHash() *ConsistentHash {
+	return &ConsistentHash{}
+}
+
+// Pick implements Picker.
+func (p *ConsistentHash) Pick(ctx context.Context, nodes []*Node) (*Node, DoneFunc, error) {
+	if len(nodes) == 0 {
+		return nil, nil, ErrNoAvailable
+	}
+	key, ok := HashKeyFromContext(ctx)
+	if !ok {
+		return nodes[rand.IntN(len(nodes))], noopDone, nil
+	}
+
+	r := p.get(nodes)
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.nodes[r.hashes[i]], noopDone, nil
+}
+
+// get returns the ring of nodes, it is only rebuilt when nodes change.
+func (p *ConsistentHash) get(nodes []*Node) *ring {
+	addrs := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		addrs = append(addrs, n.Address+"#"+strconv.Itoa(n.Weight))
+	}
+	sort.Strings(addrs)
+	sig := strings.Join(addrs, ",")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ring != nil && p.sig == sig {
+		return p.ring
+	}
+
+	r := &ring{nodes: make(map[uint32]*Node)}
+	for _, n := range nodes {
+		count := replicas * n.Weight / DefaultWeight
+		if count <= 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			h := crc32.ChecksumIEEE([]byte(n.Address + "#" + strconv.Itoa(i)))
+			if _, ok := r.nodes[h]; ok {
+				continue
+			}
+			r.nodes[h] = n
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	p.sig, p.ring = sig, r
+	return r
+}
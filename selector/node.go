@@ -0,0 +1,89 @@
+package selector
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/apus-run/van/registry"
+)
+
+const (
+	// WeightKey is the instance metadata key holding the node weight.
+	WeightKey = "weight"
+	// DefaultWeight is the weight of nodes without a valid WeightKey.
+	DefaultWeight = 100
+)
+
+// Node is a service instance endpoint of a given scheme.
+type Node struct {
+	// Scheme is the endpoint scheme without the secure suffix, e.g. "http" or "grpc".
+	Scheme string
+	// Address is the endpoint host:port.
+	Address string
+	// Secure reports whether the endpoint requires TLS.
+	Secure bool
+
+	ServiceName string
+	InstanceID  string
+	Version     string
+	Metadata    map[string]string
+	Weight      int
+}
+
+// NewNode returns the node of the first ins endpoint matching scheme, ok is
+// false if there is none. Both "https://host" and "http://host?isSecure=true"
+// are recognized as a secure "http" endpoint.
+func NewNode(scheme string, ins *registry.ServiceInstance) (node *Node, ok bool) {
+	for _, e := range ins.Endpoints {
+		u, err := url.Parse(e)
+		if err != nil {
+			continue
+		}
+		s, secure := u.Scheme, false
+		if s == scheme+"s" {
+			s, secure = scheme, true
+		}
+		if s != scheme {
+			continue
+		}
+		if v, err := strconv.ParseBool(u.Query().Get("isSecure")); err == nil {
+			secure = secure || v
+		}
+
+		weight := DefaultWeight
+		if w, err := strconv.Atoi(strings.TrimSpace(ins.Metadata[WeightKey])); err == nil && w > 0 {
+			weight = w
+		}
+		return &Node{
+			Scheme:      scheme,
+			Address:     u.Host,
+			Secure:      secure,
+			ServiceName: ins.Name,
+			InstanceID:  ins.ID,
+			Version:     ins.Version,
+			Metadata:    ins.Metadata,
+			Weight:      weight,
+		}, true
+	}
+	return nil, false
+}
+
+// NewNodes returns the nodes of the instances having an endpoint matching scheme.
+func NewNodes(scheme string, instances []*registry.ServiceInstance) []*Node {
+	nodes := make([]*Node, 0, len(instances))
+	for _, ins := range instances {
+		if n, ok := NewNode(scheme, ins); ok {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// URLScheme returns the node URL scheme, e.g. "https" for a secure "http" node.
+func (n *Node) URLScheme() string {
+	if n.Secure {
+		return n.Scheme + "s"
+	}
+	return n.Scheme
+}
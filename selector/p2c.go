@@ -0,0 +1,142 @@
+package selector
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	// decay is the time constant of the latency and success rate moving averages.
+	decay = 600 * time.Millisecond
+	// forcePick is the time after which an unpicked node is picked anyway,
+	// so that a node penalized by a bad past gets a chance to recover.
+	forcePick = 3 * time.Second
+	// penalty is the latency assumed for a node without any statistics.
+	penalty = 250 * time.Millisecond
+	// idle is the time after which the statistics of a node neither picked nor
+	// answering are dropped, the node is likely gone from the discovery.
+	idle = time.Minute
+)
+
+var _ Picker = (*P2C)(nil)
+
+// P2C is a power of two choices picker, it picks the least loaded of two
+// random nodes, the load being the EWMA of the latency weighted by the
+// in-flight requests and the success rate.
+type P2C struct {
+	mu     sync.Mutex
+	stats  map[string]*p2cStat
+	pruned time.Time // last prune of the idle statistics
+}
+
+type p2cStat struct {
+	latency  float64 // EWMA of the latency in nanoseconds
+	success  float64 // EWMA of the success rate in [0, 1]
+	inflight int64
+	stamp    time.Time // last update of the moving averages
+	picked   time.Time // last pick
+}
+
+// NewP2C create a P2C-EWMA picker.
+func NewP2C() *P2C {
+	return &P2C{stats: make(map[string]*p2cStat), pruned: time.Now()}
+}
+
+// Pick implements Picker.
+func (p *P2C) Pick(_ context.Context, nodes []*Node) (*Node, DoneFunc, error) {
+	switch len(nodes) {
+	case 0:
+		return nil, nil, ErrNoAvailable
+	case 1:
+		return nodes[0], p.pick(nodes[0], time.Now()), nil
+	}
+
+	a := rand.IntN(len(nodes))
+	b := rand.IntN(len(nodes) - 1)
+	if b >= a {
+		b++
+	}
+	na, nb := nodes[a], nodes[b]
+
+	now := time.Now()
+	p.mu.Lock()
+	sa, sb := p.stat(na.Address, now), p.stat(nb.Address, now)
+	if sa.load(na.Weight) > sb.load(nb.Weight) {
+		na, nb, sa, sb = nb, na, sb, sa
+	}
+	// the chosen is na, give nb a chance if it was not picked for long.
+	if now.Sub(sb.picked) > forcePick {
+		na = nb
+	}
+	p.mu.Unlock()
+	return na, p.pick(na, now), nil
+}
+
+func (p *P2C) pick(n *Node, now time.Time) DoneFunc {
+	p.mu.Lock()
+	if now.Sub(p.pruned) > idle {
+		p.prune(now)
+	}
+	s := p.stat(n.Address, now)
+	s.inflight++
+	s.picked = now
+	p.mu.Unlock()
+
+	return func(_ context.Context, di DoneInfo) {
+		end := time.Now()
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		s.inflight--
+
+		td := end.Sub(s.stamp)
+		if td < 0 {
+			td = 0
+		}
+		w := math.Exp(-float64(td) / float64(decay))
+		lag := float64(end.Sub(now))
+		if lag < 0 {
+			lag = 0
+		}
+		s.latency = s.latency*w + lag*(1-w)
+		ok := 1.0
+		if di.Err != nil {
+			ok = 0
+		}
+		s.success = s.success*w + ok*(1-w)
+		s.stamp = end
+	}
+}
+
+// stat must be called with p.mu held.
+func (p *P2C) stat(addr string, now time.Time) *p2cStat {
+	s, ok := p.stats[addr]
+	if !ok {
+		s = &p2cStat{latency: float64(penalty), success: 1, stamp: now, picked: now}
+		p.stats[addr] = s
+	}
+	return s
+}
+
+// prune drops the statistics of the idle nodes, it must be called with p.mu held.
+func (p *P2C) prune(now time.Time) {
+	for addr, s := range p.stats {
+		if s.inflight == 0 && now.Sub(s.picked) > idle && now.Sub(s.stamp) > idle {
+			delete(p.stats, addr)
+		}
+	}
+	p.pruned = now
+}
+
+func (s *p2cStat) load(weight int) float64 {
+	if weight <= 0 {
+		weight = DefaultWeight
+	}
+	success := s.success
+	if success < 0.01 {
+		success = 0.01
+	}
+	return math.Sqrt(s.latency+1) * float64(s.inflight+1) / (success * float64(weight))
+}
@@ -0,0 +1,61 @@
+package selector
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+func noopDone(context.Context, DoneInfo) {}
+
+var _ Picker = (*RoundRobin)(nil)
+
+// RoundRobin picks the nodes in turn.
+type RoundRobin struct {
+	next atomic.Uint64
+}
+
+// NewRoundRobin create a round-robin picker.
+func NewRoundRobin() *RoundRobin {
+	return &RoundRobin{}
+}
+
+// Pick implements Picker.
+func (p *RoundRobin) Pick(_ context.Context, nodes []*Node) (*Node, DoneFunc, error) {
+	if len(nodes) == 0 {
+		return nil, nil, ErrNoAvailable
+	}
+	i := p.next.Add(1) - 1
+	return nodes[i%uint64(len(nodes))], noopDone, nil
+}
+
+var _ Picker = (*WeightedRandom)(nil)
+
+// WeightedRandom picks a random node with a probability proportional to its weight.
+type WeightedRandom struct{}
+
+// NewWeightedRandom create a weighted random picker.
+func NewWeightedRandom() *WeightedRandom {
+	return &WeightedRandom{}
+}
+
+// Pick implements Picker.
+func (p *WeightedRandom) Pick(_ context.Context, nodes []*Node) (*Node, DoneFunc, error) {
+	if len(nodes) == 0 {
+		return nil, nil, ErrNoAvailable
+	}
+	total := 0
+	for _, n := range nodes {
+		total += n.Weight
+	}
+	if total <= 0 {
+		return nodes[rand.IntN(len(nodes))], noopDone, nil
+	}
+	r := rand.IntN(total)
+	for _, n := range nodes {
+		if r -= n.Weight; r < 0 {
+			return n, noopDone, nil
+		}
+	}
+	return nodes[len(nodes)-1], noopDone, nil
+}
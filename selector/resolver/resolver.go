@@ -0,0 +1,190 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/apus-run/van/registry"
+	"github.com/apus-run/van/selector"
+)
+
+// Scheme is the gRPC target scheme, e.g. discovery:///user-service.
+const Scheme = "discovery"
+
+// nodeKey is the address attribute key holding the nodeAttr.
+type nodeKey struct{}
+
+// nodeAttr is the address attribute of the node. The nodes of equal fields are equal,
+// so that the address of an unchanged instance keeps its subconn across the updates.
+type nodeAttr struct {
+	node *selector.Node
+}
+
+func (a nodeAttr) Equal(o any) bool {
+	b, ok := o.(nodeAttr)
+	if !ok {
+		return false
+	}
+	x, y := a.node, b.node
+	return x.Scheme == y.Scheme && x.Address == y.Address && x.Secure == y.Secure &&
+		x.ServiceName == y.ServiceName && x.InstanceID == y.InstanceID &&
+		x.Version == y.Version && x.Weight == y.Weight && maps.Equal(x.Metadata, y.Metadata)
+}
+
+// NodeFromAddress returns the node of a resolved address.
+func NodeFromAddress(addr resolver.Address) (*selector.Node, bool) {
+	a, ok := addr.Attributes.Value(nodeKey{}).(nodeAttr)
+	return a.node, ok
+}
+
+// Option is resolver builder option.
+type Option func(o *options)
+
+// options is resolver builder options.
+type options struct {
+	// timeout of the initial service lookup
+	timeout time.Duration
+	// node filters
+	filters []selector.Filter
+}
+
+// WithTimeout with the timeout of the initial service lookup.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithFilter with node filters, such as selector.Version.
+func WithFilter(filters ...selector.Filter) Option {
+	return func(o *options) { o.filters = append(o.filters, filters...) }
+}
+
+type builder struct {
+	registry registry.Registry
+	options  *options
+}
+
+// NewBuilder create a resolver.Builder resolving discovery:///<name> targets
+// to the grpc endpoints of the instances found in r. The addresses are balanced
+// by pick_first unless a balancer of the selector/balancer package is selected.
+//
+//	conn, err := grpc.NewClient("discovery:///user-service",
+//		grpc.WithResolvers(resolver.NewBuilder(r)),
+//		balancer.WithBalancer(balancer.P2C))
+func NewBuilder(r registry.Registry, opts ...Option) resolver.Builder {
+	o := &options{
+		timeout: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &builder{registry: r, options: o}
+}
+
+// Scheme implements resolver.Builder.
+func (b *builder) Scheme() string {
+	return Scheme
+}
+
+// Build implements resolver.Builder.
+func (b *builder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	name := strings.TrimPrefix(target.URL.Path, "/")
+	if name == "" {
+		name = target.URL.Host
+	}
+	if name == "" {
+		return nil, errors.New("resolver: missing service name in target")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &discoveryResolver{
+		name:    name,
+		cc:      cc,
+		ctx:     ctx,
+		cancel:  cancel,
+		filters: b.options.filters,
+	}
+
+	lctx, lcancel := context.WithTimeout(ctx, b.options.timeout)
+	defer lcancel()
+	instances, err := b.registry.GetService(lctx, name)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	r.update(instances)
+
+	if r.watcher, err = b.registry.Watch(ctx, name); err != nil {
+		cancel()
+		return nil, err
+	}
+	go r.watch()
+	return r, nil
+}
+
+type discoveryResolver struct {
+	name    string
+	cc      resolver.ClientConn
+	watcher registry.Watcher
+	filters []selector.Filter
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (r *discoveryResolver) watch() {
+	for {
+		instances, err := r.watcher.Next()
+		if err != nil {
+			if r.ctx.Err() != nil {
+				return
+			}
+			slog.Error("[resolver] watch service failed", "service", r.name, "error", err)
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		r.update(instances)
+	}
+}
+
+func (r *discoveryResolver) update(instances []*registry.ServiceInstance) {
+	nodes := selector.NewNodes("grpc", instances)
+	for _, f := range r.filters {
+		nodes = f(r.ctx, nodes)
+	}
+	addrs := make([]resolver.Address, 0, len(nodes))
+	for _, n := range nodes {
+		addrs = append(addrs, resolver.Address{
+			Addr:       n.Address,
+			Attributes: attributes.New(nodeKey{}, nodeAttr{node: n}),
+		})
+	}
+	if len(addrs) == 0 {
+		r.cc.ReportError(selector.ErrNoAvailable)
+		return
+	}
+	if err := r.cc.UpdateState(resolver.State{Addresses: addrs}); err != nil {
+		slog.Error("[resolver] update state failed", "service", r.name, "error", err)
+	}
+}
+
+// ResolveNow implements resolver.Resolver.
+func (r *discoveryResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+// Close implements resolver.Resolver.
+func (r *discoveryResolver) Close() {
+	r.cancel()
+	if r.watcher != nil {
+		_ = r.watcher.Stop()
+	}
+}
@@ -0,0 +1,89 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/apus-run/van/registry"
+	"github.com/apus-run/van/registry/memory"
+	"github.com/apus-run/van/selector"
+)
+
+func TestResolver(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	ctx := context.Background()
+	r := memory.New()
+	require.NoError(t, r.Register(ctx, &registry.ServiceInstance{
+		ID:        "1",
+		Name:      "van",
+		Version:   "v1",
+		Endpoints: []string{"grpc://" + lis.Addr().String()},
+	}))
+
+	conn, err := grpc.NewClient("discovery:///van",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithResolvers(NewBuilder(r, WithFilter(selector.Version("v1")))),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	cctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(cctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+type fakeClientConn struct {
+	resolver.ClientConn
+	states []resolver.State
+}
+
+func (c *fakeClientConn) UpdateState(s resolver.State) error {
+	c.states = append(c.states, s)
+	return nil
+}
+
+func TestResolver_Update(t *testing.T) {
+	cc := &fakeClientConn{}
+	r := &discoveryResolver{name: "van", cc: cc, ctx: context.Background()}
+	instances := func(version string) []*registry.ServiceInstance {
+		return []*registry.ServiceInstance{{
+			ID:        "1",
+			Name:      "van",
+			Version:   version,
+			Metadata:  map[string]string{"zone": "a"},
+			Endpoints: []string{"grpc://127.0.0.1:9000"},
+		}}
+	}
+	r.update(instances("v1"))
+	r.update(instances("v1"))
+	r.update(instances("v2"))
+	require.Len(t, cc.states, 3)
+
+	first, same, changed := cc.states[0].Addresses[0], cc.states[1].Addresses[0], cc.states[2].Addresses[0]
+	assert.Empty(t, first.ServerName, "TLS verifies the host of the address")
+	assert.True(t, first.Equal(same), "an unchanged instance keeps its address")
+	assert.False(t, first.Equal(changed))
+
+	n, ok := NodeFromAddress(first)
+	require.True(t, ok)
+	assert.Equal(t, "127.0.0.1:9000", n.Address)
+	assert.Equal(t, "v1", n.Version)
+}
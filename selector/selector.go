@@ -0,0 +1,130 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/apus-run/van/registry"
+)
+
+// ErrNoAvailable is returned by Select when no node is available.
+var ErrNoAvailable = errors.New("selector: no available node")
+
+// DoneInfo is the result of a call made to a selected node.
+type DoneInfo struct {
+	Err error
+}
+
+// DoneFunc must be called once the call to the selected node finished.
+type DoneFunc func(ctx context.Context, di DoneInfo)
+
+// Picker picks a node among the candidates.
+type Picker interface {
+	Pick(ctx context.Context, nodes []*Node) (*Node, DoneFunc, error)
+}
+
+// Selector selects a node among the current instances of a service.
+type Selector struct {
+	options *options
+	nodes   atomic.Pointer[[]*Node]
+
+	cancel  context.CancelFunc
+	watcher registry.Watcher
+}
+
+// New create a selector, its nodes are set with Apply.
+func New(opts ...Option) *Selector {
+	o := &options{
+		scheme: "http",
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.picker == nil {
+		o.picker = NewRoundRobin()
+	}
+	return &Selector{options: o}
+}
+
+// Discover create a selector kept up to date with the instances of the
+// service name found in r, it stops watching once ctx is done or Close is called.
+func Discover(ctx context.Context, r registry.Registry, name string, opts ...Option) (*Selector, error) {
+	s := New(opts...)
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	instances, err := r.GetService(ctx, name)
+	if err != nil {
+		s.cancel()
+		return nil, err
+	}
+	s.Apply(instances)
+
+	if s.watcher, err = r.Watch(ctx, name); err != nil {
+		s.cancel()
+		return nil, err
+	}
+	go s.watch(ctx, name)
+	return s, nil
+}
+
+func (s *Selector) watch(ctx context.Context, name string) {
+	for {
+		instances, err := s.watcher.Next()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("[selector] watch service failed", "service", name, "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		s.Apply(instances)
+	}
+}
+
+// Apply replaces the selector nodes with the ones built from instances.
+func (s *Selector) Apply(instances []*registry.ServiceInstance) {
+	nodes := NewNodes(s.options.scheme, instances)
+	s.nodes.Store(&nodes)
+}
+
+// Nodes returns the current nodes.
+func (s *Selector) Nodes() []*Node {
+	if nodes := s.nodes.Load(); nodes != nil {
+		return *nodes
+	}
+	return nil
+}
+
+// Select picks a node among the current nodes passing the selector filters and the given ones.
+func (s *Selector) Select(ctx context.Context, filters ...Filter) (*Node, DoneFunc, error) {
+	nodes := s.Nodes()
+	for _, f := range s.options.filters {
+		nodes = f(ctx, nodes)
+	}
+	for _, f := range filters {
+		nodes = f(ctx, nodes)
+	}
+	if len(nodes) == 0 {
+		return nil, nil, ErrNoAvailable
+	}
+	return s.options.picker.Pick(ctx, nodes)
+}
+
+// Close stops watching the registry.
+func (s *Selector) Close() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	if s.watcher != nil {
+		return s.watcher.Stop()
+	}
+	return nil
+}
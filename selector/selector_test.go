@@ -0,0 +1,168 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/van/registry"
+	"github.com/apus-run/van/registry/memory"
+)
+
+func instances() []*registry.ServiceInstance {
+	return []*registry.ServiceInstance{
+		{ID: "1", Name: "van", Version: "v1", Endpoints: []string{"http://127.0.0.1:8001", "grpc://127.0.0.1:9001"}},
+		{ID: "2", Name: "van", Version: "v2", Metadata: map[string]string{"zone": "a", WeightKey: "300"}, Endpoints: []string{"https://127.0.0.1:8002"}},
+		{ID: "3", Name: "van", Version: "v2", Metadata: map[string]string{"zone": "b"}, Endpoints: []string{"http://127.0.0.1:8003?isSecure=true"}},
+	}
+}
+
+func TestNewNodes(t *testing.T) {
+	nodes := NewNodes("http", instances())
+	require.Len(t, nodes, 3)
+	assert.Equal(t, "127.0.0.1:8001", nodes[0].Address)
+	assert.False(t, nodes[0].Secure)
+	assert.Equal(t, DefaultWeight, nodes[0].Weight)
+	assert.True(t, nodes[1].Secure)
+	assert.Equal(t, "https", nodes[1].URLScheme())
+	assert.Equal(t, 300, nodes[1].Weight)
+	assert.True(t, nodes[2].Secure)
+
+	nodes = NewNodes("grpc", instances())
+	require.Len(t, nodes, 1)
+	assert.Equal(t, "127.0.0.1:9001", nodes[0].Address)
+}
+
+func TestFilter(t *testing.T) {
+	ctx := context.Background()
+	nodes := NewNodes("http", instances())
+	assert.Len(t, Version("v2")(ctx, nodes), 2)
+	got := Metadata(map[string]string{"zone": "b"})(ctx, Version("v2")(ctx, nodes))
+	require.Len(t, got, 1)
+	assert.Equal(t, "3", got[0].InstanceID)
+}
+
+func TestPickers(t *testing.T) {
+	ctx := context.Background()
+	nodes := NewNodes("http", instances())
+
+	t.Run("RoundRobin", func(t *testing.T) {
+		p := NewRoundRobin()
+		var got []string
+		for i := 0; i < 4; i++ {
+			n, _, err := p.Pick(ctx, nodes)
+			require.NoError(t, err)
+			got = append(got, n.InstanceID)
+		}
+		assert.Equal(t, []string{"1", "2", "3", "1"}, got)
+	})
+
+	t.Run("WeightedRandom", func(t *testing.T) {
+		p := NewWeightedRandom()
+		count := map[string]int{}
+		for i := 0; i < 5000; i++ {
+			n, _, err := p.Pick(ctx, nodes)
+			require.NoError(t, err)
+			count[n.InstanceID]++
+		}
+		assert.Greater(t, count["2"], count["1"]+count["3"])
+	})
+
+	t.Run("P2C", func(t *testing.T) {
+		p := NewP2C()
+		count := map[string]int{}
+		for i := 0; i < 2000; i++ {
+			n, done, err := p.Pick(ctx, nodes)
+			require.NoError(t, err)
+			count[n.InstanceID]++
+			var di DoneInfo
+			if n.InstanceID == "1" {
+				di.Err = errors.New("unavailable")
+			}
+			done(ctx, di)
+		}
+		assert.Less(t, count["1"], count["2"])
+		assert.Less(t, count["1"], count["3"])
+
+		// the statistics of a node gone from the discovery are dropped
+		past := time.Now().Add(-2 * idle)
+		p.stats["gone:80"] = &p2cStat{stamp: past, picked: past}
+		p.pruned = past
+		_, done, err := p.Pick(ctx, nodes)
+		require.NoError(t, err)
+		done(ctx, DoneInfo{})
+		assert.NotContains(t, p.stats, "gone:80")
+		assert.Len(t, p.stats, len(nodes))
+	})
+
+	t.Run("ConsistentHash", func(t *testing.T) {
+		p := NewConsistentHash()
+		hctx := WithHashKey(ctx, "user-42")
+		first, _, err := p.Pick(hctx, nodes)
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			n, _, err := p.Pick(hctx, nodes)
+			require.NoError(t, err)
+			assert.Equal(t, first.InstanceID, n.InstanceID)
+		}
+	})
+
+	for _, p := range []Picker{NewRoundRobin(), NewWeightedRandom(), NewP2C(), NewConsistentHash()} {
+		_, _, err := p.Pick(ctx, nil)
+		assert.ErrorIs(t, err, ErrNoAvailable)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	ctx := context.Background()
+	r := memory.New()
+	ins := instances()
+	require.NoError(t, r.Register(ctx, ins[0]))
+
+	s, err := Discover(ctx, r, "van", WithFilter(Version("v2")))
+	require.NoError(t, err)
+	defer s.Close()
+
+	_, _, err = s.Select(ctx)
+	assert.ErrorIs(t, err, ErrNoAvailable)
+
+	require.NoError(t, r.Register(ctx, ins[1]))
+	require.Eventually(t, func() bool {
+		n, _, err := s.Select(ctx)
+		return err == nil && n.InstanceID == "2"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTransport(t *testing.T) {
+	ctx := context.Background()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	r := memory.New()
+	require.NoError(t, r.Register(ctx, &registry.ServiceInstance{
+		ID: "1", Name: "echo", Endpoints: []string{srv.URL},
+	}))
+
+	tr := NewTransport(r)
+	defer tr.Close()
+	client := &http.Client{Transport: tr}
+
+	resp, err := client.Get("discovery://echo/v1/hello")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "/v1/hello", string(body))
+
+	_, err = client.Get("discovery://missing/")
+	assert.ErrorIs(t, err, ErrNoAvailable)
+}
@@ -0,0 +1,115 @@
+package selector
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/apus-run/van/registry"
+)
+
+// DiscoveryScheme is the URL scheme of requests resolved through the registry,
+// e.g. discovery://user-service/v1/users resolves the "user-service" instances.
+const DiscoveryScheme = "discovery"
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// Transport is a http.RoundTripper sending the DiscoveryScheme requests to a
+// node of the service named by the URL host, other requests are sent as is.
+type Transport struct {
+	// Base is the underlying RoundTripper, http.DefaultTransport if nil.
+	Base http.RoundTripper
+
+	registry registry.Registry
+	opts     []Option
+
+	mu        sync.Mutex
+	selectors map[string]*Selector
+}
+
+// NewTransport create a Transport discovering services in r, opts apply to
+// the selectors of every service.
+func NewTransport(r registry.Registry, opts ...Option) *Transport {
+	return &Transport{
+		registry:  r,
+		opts:      append([]Option{WithScheme("http")}, opts...),
+		selectors: make(map[string]*Selector),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.URL.Scheme != DiscoveryScheme {
+		return base.RoundTrip(req)
+	}
+
+	s, err := t.selector(req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	node, done, err := s.Select(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	r.URL.Scheme = node.URLScheme()
+	r.URL.Host = node.Address
+	r.Host = node.Address
+
+	resp, err := base.RoundTrip(r)
+	di := DoneInfo{Err: err}
+	if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+		di.Err = &StatusError{Code: resp.StatusCode}
+	}
+	done(req.Context(), di)
+	return resp, err
+}
+
+// Close stops watching the registry.
+func (t *Transport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for name, s := range t.selectors {
+		_ = s.Close()
+		delete(t.selectors, name)
+	}
+	return nil
+}
+
+func (t *Transport) selector(name string) (*Selector, error) {
+	t.mu.Lock()
+	s, ok := t.selectors[name]
+	t.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+
+	// discover without the lock, so that the requests of the other services don't wait
+	s, err := Discover(context.Background(), t.registry, name, t.opts...)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if existing, ok := t.selectors[name]; ok {
+		// a concurrent request discovered the service first
+		_ = s.Close()
+		return existing, nil
+	}
+	t.selectors[name] = s
+	return s, nil
+}
+
+// StatusError reports a server error response to the picker.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return http.StatusText(e.Code)
+}
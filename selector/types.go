@@ -0,0 +1,29 @@
+package selector
+
+// Option is selector option.
+type Option func(o *options)
+
+// options is selector options.
+type options struct {
+	// endpoint scheme of the nodes
+	scheme string
+	// node filters applied on every selection
+	filters []Filter
+	// node picker
+	picker Picker
+}
+
+// WithScheme with the endpoint scheme of the nodes, "http" by default.
+func WithScheme(scheme string) Option {
+	return func(o *options) { o.scheme = scheme }
+}
+
+// WithFilter with node filters applied on every selection.
+func WithFilter(filters ...Filter) Option {
+	return func(o *options) { o.filters = append(o.filters, filters...) }
+}
+
+// WithPicker with node picker, round-robin by default.
+func WithPicker(p Picker) Option {
+	return func(o *options) { o.picker = p }
+}
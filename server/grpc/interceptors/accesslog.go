@@ -0,0 +1,79 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/apus-run/van/ginx/middlewares/accesslog"
+)
+
+// AccessLogFunc receives the access log of each call, StatusCode holds the gRPC code.
+type AccessLogFunc func(ctx context.Context, al *accesslog.AccessLog)
+
+// accessLog returns the access log of a call, without duration and status.
+func accessLog(ctx context.Context, pid, method string) *accesslog.AccessLog {
+	al := &accesslog.AccessLog{
+		PID:      pid,
+		Protocol: "grpc",
+		Method:   "POST",
+		Path:     method,
+		URL:      method,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, port, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			al.IP, al.Port = host, port
+		}
+		al.ClientIP = al.IP
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		al.Host = first(md, ":authority")
+		al.UA = first(md, "user-agent")
+		al.IPs = first(md, "x-forwarded-for")
+		al.Referer = first(md, "referer")
+	}
+	return al
+}
+
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return strings.Join(v, ",")
+	}
+	return ""
+}
+
+// UnaryAccessLog returns a unary interceptor passing the access log of each call to fn.
+func UnaryAccessLog(fn AccessLogFunc) grpc.UnaryServerInterceptor {
+	pid := strconv.Itoa(os.Getpid())
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		al := accessLog(ctx, pid, info.FullMethod)
+		resp, err := handler(ctx, req)
+		al.Duration = time.Since(start).String()
+		al.StatusCode = int(code(err))
+		fn(ctx, al)
+		return resp, err
+	}
+}
+
+// StreamAccessLog returns a stream interceptor passing the access log of each call to fn.
+func StreamAccessLog(fn AccessLogFunc) grpc.StreamServerInterceptor {
+	pid := strconv.Itoa(os.Getpid())
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := ss.Context()
+		al := accessLog(ctx, pid, info.FullMethod)
+		err := handler(srv, ss)
+		al.Duration = time.Since(start).String()
+		al.StatusCode = int(code(err))
+		fn(ctx, al)
+		return err
+	}
+}
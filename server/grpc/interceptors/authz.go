@@ -0,0 +1,68 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/apus-run/van/authz"
+)
+
+// AuthzAction is the action of the authorization requests of the gRPC calls,
+// their resource is the full method, e.g. "/van.v1.Greeter/SayHello".
+const AuthzAction = "call"
+
+// ClaimsFunc returns the claims of the authenticated caller of ctx.
+type ClaimsFunc func(ctx context.Context) (jwt.Claims, bool)
+
+// UnaryAuthz returns a unary interceptor authorizing the calls with e, a call without
+// claims fails with codes.Unauthenticated and a denied one with codes.PermissionDenied.
+// The "request" attribute has the "method" and the "metadata" of the call.
+func UnaryAuthz(e *authz.Engine, claims ClaimsFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := authorize(ctx, e, claims, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthz returns a stream interceptor authorizing the calls with e.
+func StreamAuthz(e *authz.Engine, claims ClaimsFunc) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), e, claims, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func authorize(ctx context.Context, e *authz.Engine, claims ClaimsFunc, method string) error {
+	c, ok := claims(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "claims missing")
+	}
+	req, err := e.Request(c, AuthzAction, method)
+	if err != nil {
+		return status.Error(codes.Internal, "authorization unavailable")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := make(map[string]string, len(md))
+	for k, v := range md {
+		if len(v) > 0 {
+			values[k] = v[0]
+		}
+	}
+	req.Attrs["request"] = map[string]any{"method": method, "metadata": values}
+
+	// the reason names the roles of the policy, it is only in the decision log
+	if d := e.Authorize(ctx, req); !d.Permitted() {
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return nil
+}
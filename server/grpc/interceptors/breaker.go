@@ -0,0 +1,86 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/apus-run/van/pkg/bbr"
+	"github.com/apus-run/van/pkg/breaker"
+)
+
+// breakerFailed reports whether err means the callee is failing, the errors
+// of the caller such as codes.InvalidArgument are successes for the breaker.
+func breakerFailed(err error) bool {
+	switch status.Code(err) {
+	case codes.Unknown, codes.DeadlineExceeded, codes.ResourceExhausted,
+		codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+func mark(b breaker.Breaker, err error) {
+	if breakerFailed(err) {
+		b.MarkFailed()
+	} else {
+		b.MarkSuccess()
+	}
+}
+
+// UnaryBreaker returns a unary interceptor with a breaker per method of g,
+// a call rejected by the breaker fails with codes.Unavailable.
+func UnaryBreaker(g *breaker.Group) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		b := g.Get(info.FullMethod)
+		if err := b.Allow(); err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		resp, err := handler(ctx, req)
+		mark(b, ToStatus(err).Err())
+		return resp, err
+	}
+}
+
+// UnaryClientBreaker returns a unary client interceptor with a breaker per method of g,
+// it protects the client from a failing service, a call rejected by the breaker fails
+// with codes.Unavailable without reaching the service.
+func UnaryClientBreaker(g *breaker.Group) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		b := g.Get(method)
+		if err := b.Allow(); err != nil {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		mark(b, err)
+		return err
+	}
+}
+
+// UnaryAdaptiveLimit returns a unary interceptor shedding calls with
+// codes.ResourceExhausted once l detects an overload.
+func UnaryAdaptiveLimit(l *bbr.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		done, err := l.Allow()
+		if err != nil {
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+		defer done()
+		return handler(ctx, req)
+	}
+}
+
+// StreamAdaptiveLimit returns a stream interceptor shedding calls with
+// codes.ResourceExhausted once l detects an overload.
+func StreamAdaptiveLimit(l *bbr.Limiter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		done, err := l.Allow()
+		if err != nil {
+			return status.Error(codes.ResourceExhausted, err.Error())
+		}
+		defer done()
+		return handler(srv, ss)
+	}
+}
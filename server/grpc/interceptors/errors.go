@@ -0,0 +1,71 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/apus-run/van/errorsx"
+)
+
+// ToStatus converts err into a *status.Status, *errorsx.Error are converted
+// through errorsx.Error.GRPCStatus, context errors keep their meaning and
+// other errors are reported as internal errors.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	var se interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &se) {
+		return se.GRPCStatus()
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, err.Error())
+	}
+	return errorsx.FromError(err).GRPCStatus()
+}
+
+// UnaryErrors returns a unary interceptor converting the handler errors with ToStatus.
+func UnaryErrors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, ToStatus(err).Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamErrors returns a stream interceptor converting the handler errors with ToStatus.
+func StreamErrors() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return ToStatus(err).Err()
+		}
+		return nil
+	}
+}
+
+// code returns the gRPC code of err, as it will be reported to the client.
+func code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	return ToStatus(err).Code()
+}
+
+// wrappedStream overrides the context of a grpc.ServerStream.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
@@ -0,0 +1,265 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/apus-run/van/authz"
+	"github.com/apus-run/van/errorsx"
+	"github.com/apus-run/van/ginx/middlewares/accesslog"
+	"github.com/apus-run/van/ginx/middlewares/activelimit/locallimit"
+	"github.com/apus-run/van/pkg/bbr"
+	"github.com/apus-run/van/pkg/breaker"
+	"github.com/apus-run/van/server"
+	grpcServer "github.com/apus-run/van/server/grpc"
+)
+
+var info = &grpc.UnaryServerInfo{FullMethod: "/van.v1.Greeter/SayHello"}
+
+func TestUnaryRecovery(t *testing.T) {
+	_, err := UnaryRecovery()(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		panic("boom")
+	})
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.Equal(t, "Panic: server inner error", st.Message())
+}
+
+func TestUnaryErrors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{errorsx.NotFound("UserNotFound"), codes.NotFound},
+		{fmt.Errorf("wrapped: %w", errorsx.Unauthorized("TokenMissing")), codes.Unauthenticated},
+		{status.Error(codes.AlreadyExists, "exists"), codes.AlreadyExists},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{errors.New("oops"), codes.Internal},
+	}
+	for _, tt := range tests {
+		_, err := UnaryErrors()(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			return nil, tt.err
+		})
+		assert.Equal(t, tt.want, status.Code(err), tt.err.Error())
+	}
+
+	st := ToStatus(errorsx.NotFound("UserNotFound"))
+	e := errorsx.FromError(st.Err())
+	assert.Equal(t, "UserNotFound", e.Reason)
+}
+
+func TestUnaryTimeout(t *testing.T) {
+	_, err := UnaryTimeout(10*time.Millisecond)(context.Background(), nil, info, func(ctx context.Context, _ any) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	// 客户端的 deadline 先到期, 不是服务端超时
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = UnaryTimeout(time.Second)(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.NotContains(t, status.Convert(err).Message(), "server timeout")
+}
+
+func TestUnaryActiveLimit(t *testing.T) {
+	limit := locallimit.NewLocalActiveLimit(1)
+	in := UnaryActiveLimit(limit)
+	_, err := in(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		_, err := in(ctx, req, info, func(context.Context, any) (any, error) { return nil, nil })
+		return nil, err
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = in(context.Background(), nil, info, func(context.Context, any) (any, error) { return nil, nil })
+	assert.NoError(t, err)
+}
+
+func TestUnaryMetrics(t *testing.T) {
+	in := UnaryMetrics(WithRegisterer(prometheus.NewRegistry()), WithIgnoreMethods("/ignored/Method"))
+	_, err := in(context.Background(), nil, info, func(context.Context, any) (any, error) { return nil, nil })
+	assert.NoError(t, err)
+	service, method := splitMethod(info.FullMethod)
+	assert.Equal(t, "van.v1.Greeter", service)
+	assert.Equal(t, "SayHello", method)
+
+	// 每个 registerer 有各自的指标, 同一个 registerer 共享指标
+	for _, r := range []*prometheus.Registry{prometheus.NewRegistry(), prometheus.NewRegistry()} {
+		for i := 0; i < 2; i++ {
+			in := UnaryMetrics(WithRegisterer(r))
+			_, err := in(context.Background(), nil, info, func(context.Context, any) (any, error) { return nil, nil })
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2.0, handledTotal(t, r))
+	}
+}
+
+// handledTotal returns the calls counted by grpc_server_handled_total in r.
+func handledTotal(t *testing.T, r *prometheus.Registry) float64 {
+	mfs, err := r.Gather()
+	require.NoError(t, err)
+	total := 0.0
+	for _, mf := range mfs {
+		if mf.GetName() != "grpc_server_handled_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			total += m.GetCounter().GetValue()
+		}
+	}
+	return total
+}
+
+func TestServerInterceptors(t *testing.T) {
+	var logs []*accesslog.AccessLog
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpcServer.NewServer(
+		server.WithListener(lis),
+		server.WithUnaryInterceptor(
+			UnaryRecovery(),
+			UnaryRequestID(),
+			UnaryTracing("van"),
+			UnaryAccessLog(func(_ context.Context, al *accesslog.AccessLog) { logs = append(logs, al) }),
+			UnaryErrors(),
+		),
+		server.WithStreamInterceptor(StreamRecovery(), StreamRequestID(), StreamErrors()),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Start(context.Background()) }()
+	defer srv.Stop(context.Background())
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-1")
+	var header metadata.MD
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req-1"}, header.Get("x-request-id"))
+	require.Len(t, logs, 1)
+	assert.Equal(t, "/grpc.health.v1.Health/Check", logs[0].Path)
+	assert.Equal(t, int(codes.OK), logs[0].StatusCode)
+
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestUnaryBreaker(t *testing.T) {
+	g := breaker.NewGroup(func() breaker.Breaker {
+		return breaker.NewSRE(breaker.WithRequest(10))
+	})
+	failed := func(context.Context, any) (any, error) {
+		return nil, errorsx.InternalServer("Internal")
+	}
+	rejected := 0
+	for i := 0; i < 100; i++ {
+		_, err := UnaryBreaker(g)(context.Background(), nil, info, failed)
+		if status.Code(err) == codes.Unavailable {
+			rejected++
+		}
+	}
+	assert.Greater(t, rejected, 0)
+
+	// errors of the caller don't open the breaker
+	g = breaker.NewGroup(func() breaker.Breaker {
+		return breaker.NewSRE(breaker.WithRequest(10))
+	})
+	for i := 0; i < 100; i++ {
+		_, err := UnaryBreaker(g)(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			return nil, errorsx.NotFound("UserNotFound")
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	}
+}
+
+func TestUnaryAdaptiveLimit(t *testing.T) {
+	l := bbr.New(bbr.WithCPU(func() int64 { return 1000 }))
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	done := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, _ = UnaryAdaptiveLimit(l)(context.Background(), nil, info, func(context.Context, any) (any, error) {
+				entered <- struct{}{}
+				<-release
+				return nil, nil
+			})
+			done <- struct{}{}
+		}()
+		<-entered
+	}
+
+	_, err := UnaryAdaptiveLimit(l)(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, nil
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	close(release)
+	<-done
+	<-done
+}
+
+type authzClaims struct {
+	Roles []string `json:"roles"`
+	jwt.RegisteredClaims
+}
+
+type claimsKey struct{}
+
+func TestUnaryAuthz(t *testing.T) {
+	e, err := authz.New(&authz.Policy{Roles: []authz.Role{{
+		Name: "greeter",
+		Permissions: []authz.Permission{{
+			Actions:   []string{AuthzAction},
+			Resources: []string{"/van.v1.Greeter/*"},
+			Conditions: []authz.Condition{
+				{Attr: "request.metadata.x-tenant", Values: []string{"t1"}},
+			},
+		}},
+	}}})
+	require.NoError(t, err)
+	claims := func(ctx context.Context) (jwt.Claims, bool) {
+		c, ok := ctx.Value(claimsKey{}).(jwt.Claims)
+		return c, ok
+	}
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+	call := func(c jwt.Claims, tenant string) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant", tenant))
+		if c != nil {
+			ctx = context.WithValue(ctx, claimsKey{}, c)
+		}
+		_, err := UnaryAuthz(e, claims)(ctx, nil, info, handler)
+		return err
+	}
+
+	greeter := &authzClaims{Roles: []string{"greeter"}}
+	assert.NoError(t, call(greeter, "t1"))
+	err = call(greeter, "t2")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "permission denied", status.Convert(err).Message())
+	assert.Equal(t, codes.PermissionDenied, status.Code(call(&authzClaims{}, "t1")))
+	assert.Equal(t, codes.Unauthenticated, status.Code(call(nil, "t1")))
+}
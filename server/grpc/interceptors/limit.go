@@ -0,0 +1,94 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryTimeout returns a unary interceptor bounding each call with timeout,
+// a call exceeding it is reported as codes.DeadlineExceeded.
+func UnaryTimeout(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(parent context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		resp, err := handler(ctx, req)
+		if serverTimeout(parent, ctx) {
+			return nil, status.Error(codes.DeadlineExceeded, "server timeout")
+		}
+		return resp, err
+	}
+}
+
+// StreamTimeout returns a stream interceptor bounding each call with timeout.
+func StreamTimeout(timeout time.Duration) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel := context.WithTimeout(ss.Context(), timeout)
+		defer cancel()
+		err := handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+		if serverTimeout(ss.Context(), ctx) {
+			return status.Error(codes.DeadlineExceeded, "server timeout")
+		}
+		return err
+	}
+}
+
+// serverTimeout reports whether ctx expired on the timeout of the interceptor,
+// rather than on an earlier deadline of parent such as the one of the client.
+func serverTimeout(parent, ctx context.Context) bool {
+	if ctx.Err() != context.DeadlineExceeded {
+		return false
+	}
+	pd, ok := parent.Deadline()
+	d, _ := ctx.Deadline()
+	return !ok || d.Before(pd)
+}
+
+// ActiveLimiter limits the number of active calls, it is implemented by
+// locallimit.LocalActiveLimit and redislimit.RedisActiveLimit.
+type ActiveLimiter interface {
+	// Acquire reports whether the call may proceed, Release must be called if err is nil.
+	Acquire(ctx context.Context) (bool, error)
+	Release(ctx context.Context)
+}
+
+func acquire(ctx context.Context, l ActiveLimiter) (func(), error) {
+	ok, err := l.Acquire(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "active limit unavailable")
+	}
+	if !ok {
+		l.Release(ctx)
+		return nil, status.Error(codes.ResourceExhausted, "too many requests")
+	}
+	return func() { l.Release(ctx) }, nil
+}
+
+// UnaryActiveLimit returns a unary interceptor rejecting calls with
+// codes.ResourceExhausted once l is reached.
+func UnaryActiveLimit(l ActiveLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		release, err := acquire(ctx, l)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
+
+// StreamActiveLimit returns a stream interceptor rejecting calls with
+// codes.ResourceExhausted once l is reached.
+func StreamActiveLimit(l ActiveLimiter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		release, err := acquire(ss.Context(), l)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return handler(srv, ss)
+	}
+}
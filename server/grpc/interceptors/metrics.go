@@ -0,0 +1,148 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	namespace = "grpc"
+
+	labels = []string{"code", "service", "method", "type"}
+)
+
+// MetricsOption set the metrics options.
+type MetricsOption func(*metricsOptions)
+
+type metricsOptions struct {
+	registerer    prometheus.Registerer
+	ignoreCodes   map[codes.Code]struct{}
+	ignoreMethods map[string]struct{}
+
+	handledCount    *prometheus.CounterVec
+	handledDuration *prometheus.HistogramVec
+}
+
+// WithRegisterer set the prometheus registerer, prometheus.DefaultRegisterer by default.
+// The interceptors built with the same registerer share its metrics.
+func WithRegisterer(r prometheus.Registerer) MetricsOption {
+	return func(o *metricsOptions) {
+		o.registerer = r
+	}
+}
+
+// WithIgnoreCodes ignore gRPC codes
+func WithIgnoreCodes(cs ...codes.Code) MetricsOption {
+	return func(o *metricsOptions) {
+		o.ignoreCodes = make(map[codes.Code]struct{}, len(cs))
+		for _, c := range cs {
+			o.ignoreCodes[c] = struct{}{}
+		}
+	}
+}
+
+// WithIgnoreMethods ignore full methods, e.g. /grpc.health.v1.Health/Check
+func WithIgnoreMethods(methods ...string) MetricsOption {
+	return func(o *metricsOptions) {
+		o.ignoreMethods = make(map[string]struct{}, len(methods))
+		for _, m := range methods {
+			o.ignoreMethods[m] = struct{}{}
+		}
+	}
+}
+
+func (o *metricsOptions) record(fullMethod, typ string, err error, start time.Time) {
+	if _, ok := o.ignoreMethods[fullMethod]; ok {
+		return
+	}
+	c := code(err)
+	if _, ok := o.ignoreCodes[c]; ok {
+		return
+	}
+	service, method := splitMethod(fullMethod)
+	lvs := []string{c.String(), service, method, typ}
+	o.handledCount.WithLabelValues(lvs...).Inc()
+	o.handledDuration.WithLabelValues(lvs...).Observe(time.Since(start).Seconds())
+}
+
+func newMetricsOptions(opts ...MetricsOption) *metricsOptions {
+	o := &metricsOptions{registerer: prometheus.DefaultRegisterer}
+	for _, opt := range opts {
+		opt(o)
+	}
+	var err error
+	o.handledCount, err = register(o.registerer, prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "server_handled_total",
+			Help:      "Total number of RPCs completed on the server.",
+		}, labels,
+	))
+	if err != nil {
+		panic(err)
+	}
+	o.handledDuration, err = register(o.registerer, prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "server_handling_seconds",
+			Help:      "RPC handling latencies in seconds.",
+		}, labels,
+	))
+	if err != nil {
+		panic(err)
+	}
+	return o
+}
+
+// register registers c, or returns the collector of r already registered with its descriptor.
+func register[T prometheus.Collector](r prometheus.Registerer, c T) (T, error) {
+	if err := r.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return c, err
+	}
+	return c, nil
+}
+
+// splitMethod splits /package.Service/Method into its service and method.
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
+
+// UnaryMetrics returns a unary interceptor collecting "grpc_server_handled_total"
+// and "grpc_server_handling_seconds".
+func UnaryMetrics(opts ...MetricsOption) grpc.UnaryServerInterceptor {
+	o := newMetricsOptions(opts...)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		o.record(info.FullMethod, "unary", err, start)
+		return resp, err
+	}
+}
+
+// StreamMetrics returns a stream interceptor collecting "grpc_server_handled_total"
+// and "grpc_server_handling_seconds".
+func StreamMetrics(opts ...MetricsOption) grpc.StreamServerInterceptor {
+	o := newMetricsOptions(opts...)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		o.record(info.FullMethod, "stream", err, start)
+		return err
+	}
+}
@@ -0,0 +1,51 @@
+package interceptors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+
+	"github.com/apus-run/van/errorsx"
+)
+
+// panicError is the error reported to the client when a handler panics.
+func panicError(method string, r any) error {
+	slog.Error("[gRPC] exec panic error",
+		"method", method,
+		"panic", r,
+		"trace_error", string(debug.Stack()),
+	)
+	return errorsx.InternalServer("Panic").
+		WithMessage("server inner error").
+		WithCause(fmt.Errorf("panic: %v", r)).
+		GRPCStatus().Err()
+}
+
+// UnaryRecovery returns a unary interceptor recovering from panics,
+// they are reported as codes.Internal.
+func UnaryRecovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = panicError(info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecovery returns a stream interceptor recovering from panics,
+// they are reported as codes.Internal.
+func StreamRecovery() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = panicError(info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,54 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/apus-run/van/ginx/middlewares/requstid"
+)
+
+// requestIDKey is the metadata key of the request id, metadata keys are lower case.
+var requestIDKey = strings.ToLower(requstid.HeaderXRequestIDKey)
+
+// RequestIDFromContext returns the request id injected by the RequestID interceptors.
+func RequestIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(requstid.RequestIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// requestID reads the incoming request id or creates one, and sends it back in the header.
+func requestID(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(requestIDKey); len(v) > 0 {
+			id = v[0]
+		}
+	}
+	if id == "" {
+		id = uuid.New().String()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	return context.WithValue(ctx, requstid.RequestIDKey, id)
+}
+
+// UnaryRequestID returns a unary interceptor injecting the 'x-request-id'
+// metadata into the context and the response header of each call.
+func UnaryRequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(requestID(ctx), req)
+	}
+}
+
+// StreamRequestID returns a stream interceptor injecting the 'x-request-id'
+// metadata into the context and the response header of each call.
+func StreamRequestID() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: requestID(ss.Context())})
+	}
+}
@@ -0,0 +1,110 @@
+package interceptors
+
+import (
+	"context"
+
+	otelcontrib "go.opentelemetry.io/contrib"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/apus-run/van/ginx/middlewares/tracing"
+)
+
+const tracerName = "otelgrpc"
+
+// metadataCarrier adapts metadata.MD to propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type tracer struct {
+	serviceName string
+	tracer      oteltrace.Tracer
+	propagators propagation.TextMapPropagator
+}
+
+func newTracer(serviceName string, opts ...tracing.TraceOption) *tracer {
+	tp, propagators := tracing.Config(opts...)
+	return &tracer{
+		serviceName: serviceName,
+		tracer: tp.Tracer(
+			tracerName,
+			oteltrace.WithInstrumentationVersion(otelcontrib.SemVersion()),
+		),
+		propagators: propagators,
+	}
+}
+
+func (t *tracer) start(ctx context.Context, fullMethod string) (context.Context, oteltrace.Span) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		md = metadata.MD{}
+	}
+	ctx = t.propagators.Extract(ctx, metadataCarrier(md))
+	service, method := splitMethod(fullMethod)
+	return t.tracer.Start(ctx, fullMethod,
+		oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+		oteltrace.WithAttributes(
+			semconv.RPCSystemGRPC,
+			semconv.RPCServiceKey.String(service),
+			semconv.RPCMethodKey.String(method),
+			attribute.String("service.name", t.serviceName),
+		),
+	)
+}
+
+func (t *tracer) end(span oteltrace.Span, err error) {
+	c := code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(c)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, ToStatus(err).Message())
+	}
+	span.End()
+}
+
+// UnaryTracing returns a unary interceptor tracing incoming calls, it accepts
+// the same options as the gin tracing middleware.
+func UnaryTracing(serviceName string, opts ...tracing.TraceOption) grpc.UnaryServerInterceptor {
+	t := newTracer(serviceName, opts...)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, span := t.start(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		t.end(span, err)
+		return resp, err
+	}
+}
+
+// StreamTracing returns a stream interceptor tracing incoming calls, it accepts
+// the same options as the gin tracing middleware.
+func StreamTracing(serviceName string, opts ...tracing.TraceOption) grpc.StreamServerInterceptor {
+	t := newTracer(serviceName, opts...)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := t.start(ss.Context(), info.FullMethod)
+		err := handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+		t.end(span, err)
+		return err
+	}
+}
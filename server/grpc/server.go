@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"net"
 	"net/url"
+	"sync/atomic"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -22,6 +23,8 @@ type Server struct {
 
 	baseCtx context.Context
 	options *server.ServerOptions
+
+	serving atomic.Bool
 }
 
 func NewServer(opts ...server.ServerOption) *Server {
@@ -37,6 +40,13 @@ func NewServer(opts ...server.ServerOption) *Server {
 		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(options.TLSConfig)))
 	}
 
+	if len(options.UnaryInterceptors) > 0 {
+		grpcOpts = append(grpcOpts, grpc.ChainUnaryInterceptor(options.UnaryInterceptors...))
+	}
+	if len(options.StreamInterceptors) > 0 {
+		grpcOpts = append(grpcOpts, grpc.ChainStreamInterceptor(options.StreamInterceptors...))
+	}
+
 	if len(options.Options) > 0 {
 		grpcOpts = append(grpcOpts, options.Options...)
 	}
@@ -54,10 +64,13 @@ func (s *Server) Start(ctx context.Context) error {
 
 	slog.Info("[gRPC] server listen on", "address", s.options.Address)
 
+	s.serving.Store(true)
+	defer s.serving.Store(false)
 	return s.Serve(s.options.Listener)
 }
 
 func (s *Server) Stop(ctx context.Context) error {
+	s.serving.Store(false)
 	return shutdown.ShutdownWithContext(ctx, func(_ context.Context) error {
 		s.Server.GracefulStop()
 		return nil
@@ -67,16 +80,10 @@ func (s *Server) Stop(ctx context.Context) error {
 		return nil
 	})
 }
+
+// Health reports whether the server is listening and serving requests.
 func (s *Server) Health() bool {
-	if s.options.Listener == nil {
-		return false
-	}
-	conn, err := s.options.Listener.Accept()
-	if err != nil {
-		return false
-	}
-	e := conn.Close()
-	return e == nil
+	return s.options.Listener != nil && s.serving.Load()
 }
 
 // Endpoint return a real address to registry endpoint.
@@ -0,0 +1,419 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/apus-run/van/errorsx"
+	httpstatus "github.com/apus-run/van/server/http/status"
+)
+
+var (
+	_ grpc.ServiceRegistrar = (*Gateway)(nil)
+	_ http.Handler          = (*Gateway)(nil)
+)
+
+// defaultMaxBodySize is the default maximum message size of a gRPC server.
+const defaultMaxBodySize = 4 << 20
+
+// method is a registered unary gRPC method.
+type method struct {
+	impl    any
+	handler func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error)
+}
+
+type route struct {
+	rule       Rule
+	template   *template
+	fullMethod string
+}
+
+// Gateway serves registered gRPC services as JSON/HTTP endpoints.
+//
+// It implements grpc.ServiceRegistrar, so generated RegisterXxxServer functions
+// register services on it as on a grpc.Server. Routes come from the google.api.http
+// annotations of the registered services, or from Route. Only unary methods are served.
+//
+//	gw := gateway.New()
+//	pb.RegisterGreeterServer(gw, greeter)
+//	srv := http.NewServer(server.WithHandler(gw))
+type Gateway struct {
+	options     *options
+	interceptor grpc.UnaryServerInterceptor
+
+	mu      sync.RWMutex
+	methods map[string]*method
+	routes  []*route
+	// errors of the services registered with RegisterService
+	err error
+}
+
+// New creates a gateway.
+func New(opts ...Option) *Gateway {
+	o := &options{maxBodySize: defaultMaxBodySize}
+	o.marshal.EmitUnpopulated = true
+	o.unmarshal.DiscardUnknown = true
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Gateway{
+		options:     o,
+		interceptor: chainUnary(o.interceptors),
+		methods:     make(map[string]*method),
+	}
+}
+
+// RegisterService implements grpc.ServiceRegistrar, it registers the service as
+// Register does. The routes failing to register are skipped and reported by Err.
+func (g *Gateway) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	if err := g.Register(desc, impl); err != nil {
+		g.mu.Lock()
+		g.err = errors.Join(g.err, err)
+		g.mu.Unlock()
+	}
+}
+
+// Err returns the errors of the services registered with RegisterService, such as
+// a malformed path template in a google.api.http annotation.
+func (g *Gateway) Err() error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.err
+}
+
+// Register registers the unary methods of a service and the routes of its
+// google.api.http annotations, the service descriptor is looked up in
+// protoregistry.GlobalFiles. The invalid routes are skipped and their errors returned.
+func (g *Gateway) Register(desc *grpc.ServiceDesc, impl any) error {
+	var sd protoreflect.ServiceDescriptor
+	if d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(desc.ServiceName)); err == nil {
+		sd, _ = d.(protoreflect.ServiceDescriptor)
+	}
+
+	var errs []error
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, m := range desc.Methods {
+		fullMethod := "/" + desc.ServiceName + "/" + m.MethodName
+		g.methods[fullMethod] = &method{impl: impl, handler: m.Handler}
+		if sd == nil {
+			continue
+		}
+		md := sd.Methods().ByName(protoreflect.Name(m.MethodName))
+		if md == nil {
+			continue
+		}
+		for _, r := range rulesOf(md) {
+			if err := g.addRoute(r, fullMethod); err != nil {
+				errs = append(errs, fmt.Errorf("route %s %s of %s: %w", r.Method, r.Path, fullMethod, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Route maps the HTTP method and path template to a full gRPC method, e.g.
+//
+//	gw.Route(http.MethodGet, "/v1/users/{id}", "/user.v1.User/GetUser")
+//
+// The whole request is decoded from the body for POST, PUT and PATCH, path
+// variables and query parameters fill the request fields otherwise.
+func (g *Gateway) Route(httpMethod, path, fullMethod string) error {
+	r := Rule{Method: httpMethod, Path: path}
+	switch httpMethod {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		r.Body = "*"
+	}
+	return g.Rule(r, fullMethod)
+}
+
+// Rule maps the rule to a full gRPC method.
+func (g *Gateway) Rule(r Rule, fullMethod string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.addRoute(r, fullMethod)
+}
+
+func (g *Gateway) addRoute(r Rule, fullMethod string) error {
+	t, err := parseTemplate(r.Path)
+	if err != nil {
+		return err
+	}
+	g.routes = append(g.routes, &route{rule: r, template: t, fullMethod: fullMethod})
+	return nil
+}
+
+// Routes returns the rules of the gateway by full gRPC method.
+func (g *Gateway) Routes() map[string][]Rule {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	routes := make(map[string][]Rule)
+	for _, r := range g.routes {
+		routes[r.fullMethod] = append(routes[r.fullMethod], r.rule)
+	}
+	return routes
+}
+
+func (g *Gateway) match(r *http.Request) (*route, *method, map[string]string, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	allowed := false
+	for _, rt := range g.routes {
+		vars, ok := rt.template.match(r.URL.Path)
+		if !ok {
+			continue
+		}
+		if rt.rule.Method != r.Method {
+			allowed = true
+			continue
+		}
+		m, ok := g.methods[rt.fullMethod]
+		if !ok {
+			return nil, nil, nil, status.Errorf(codes.Unimplemented, "method %s not implemented", rt.fullMethod)
+		}
+		return rt, m, vars, nil
+	}
+	if allowed {
+		return nil, nil, nil, errorsx.New(http.StatusMethodNotAllowed, "MethodNotAllowed").
+			WithMessage(r.Method + " not allowed")
+	}
+	return nil, nil, nil, nil
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt, m, vars, err := g.match(r)
+	if err != nil {
+		g.writeError(w, err)
+		return
+	}
+	if rt == nil {
+		if g.options.fallback != nil {
+			g.options.fallback.ServeHTTP(w, r)
+			return
+		}
+		g.writeError(w, status.Errorf(codes.NotFound, "route %s %s not found", r.Method, r.URL.Path))
+		return
+	}
+
+	stream := &transportStream{method: rt.fullMethod, header: w.Header()}
+	ctx := metadata.NewIncomingContext(r.Context(), incomingMetadata(r))
+	ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+
+	dec := func(v any) error {
+		msg, ok := v.(proto.Message)
+		if !ok {
+			return status.Errorf(codes.Internal, "request %T is not a proto message", v)
+		}
+		if err := g.decode(w, r, rt.rule, vars, msg); err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				return errorsx.New(http.StatusRequestEntityTooLarge, "RequestEntityTooLarge").
+					WithMessage(fmt.Sprintf("request body larger than %d bytes", maxErr.Limit))
+			}
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil
+	}
+	resp, err := m.handler(m.impl, ctx, dec, g.interceptor)
+	if err != nil {
+		g.writeError(w, err)
+		return
+	}
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		g.writeError(w, status.Errorf(codes.Internal, "response %T is not a proto message", resp))
+		return
+	}
+	b, err := g.encode(msg, rt.rule.ResponseBody)
+	if err != nil {
+		g.writeError(w, status.Error(codes.Internal, err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(b)
+}
+
+// decode fills msg from the body, the path variables and the query parameters.
+func (g *Gateway) decode(w http.ResponseWriter, r *http.Request, rule Rule, vars map[string]string, msg proto.Message) error {
+	if rule.Body != "" && r.Body != nil {
+		reader := r.Body
+		if g.options.maxBodySize > 0 {
+			reader = http.MaxBytesReader(w, r.Body, g.options.maxBodySize)
+		}
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		if len(body) > 0 {
+			if err := g.decodeBody(rule.Body, body, msg); err != nil {
+				return err
+			}
+		}
+	}
+
+	m := msg.ProtoReflect()
+	for field, value := range vars {
+		if err := setField(m, field, []string{value}); err != nil {
+			return err
+		}
+	}
+	if rule.Body == "*" {
+		return nil
+	}
+	for key, values := range r.URL.Query() {
+		if _, ok := vars[key]; ok || key == rule.Body {
+			continue
+		}
+		if err := setField(m, key, values); err != nil && !isUnknownField(m, key) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Gateway) decodeBody(field string, body []byte, msg proto.Message) error {
+	if field == "*" {
+		return g.options.unmarshal.Unmarshal(body, msg)
+	}
+	fd := fieldByName(msg.ProtoReflect().Descriptor(), field)
+	if fd == nil {
+		return fmt.Errorf("unknown body field %q", field)
+	}
+	// decode {"<field>": body} into a new message, so the body of any field kind
+	// follows the protojson mapping
+	wrapped, err := json.Marshal(map[string]json.RawMessage{fd.JSONName(): body})
+	if err != nil {
+		return err
+	}
+	v := msg.ProtoReflect().New().Interface()
+	if err := g.options.unmarshal.Unmarshal(wrapped, v); err != nil {
+		return err
+	}
+	proto.Merge(msg, v)
+	return nil
+}
+
+// encode marshals msg, or its field when field is set.
+func (g *Gateway) encode(msg proto.Message, field string) ([]byte, error) {
+	b, err := g.options.marshal.Marshal(msg)
+	if err != nil || field == "" {
+		return b, err
+	}
+	fd := fieldByName(msg.ProtoReflect().Descriptor(), field)
+	if fd == nil {
+		return nil, fmt.Errorf("unknown response field %q", field)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	name := fd.JSONName()
+	if g.options.marshal.UseProtoNames {
+		name = string(fd.Name())
+	}
+	if v, ok := fields[name]; ok {
+		return v, nil
+	}
+	return []byte("null"), nil
+}
+
+// errorBody is the JSON body of a failed call.
+type errorBody struct {
+	Code     int               `json:"code"`
+	Reason   string            `json:"reason"`
+	Message  string            `json:"message"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// writeError writes err with the HTTP status of its gRPC code.
+func (g *Gateway) writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		err = status.FromContextError(err).Err()
+	}
+	e := errorsx.FromError(err)
+	if e.Code == 0 {
+		e.Code = httpstatus.FromGRPCCode(status.Code(err))
+	}
+	b, _ := json.Marshal(errorBody{
+		Code:     e.Code,
+		Reason:   e.Reason,
+		Message:  e.Message,
+		Metadata: e.Metadata,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.Code)
+	_, _ = w.Write(b)
+}
+
+func isUnknownField(m protoreflect.Message, path string) bool {
+	name, _, _ := strings.Cut(path, ".")
+	return fieldByName(m.Descriptor(), name) == nil
+}
+
+// incomingMetadata converts the request headers to gRPC metadata.
+func incomingMetadata(r *http.Request) metadata.MD {
+	md := metadata.MD{}
+	for k, v := range r.Header {
+		md.Append(k, v...)
+	}
+	if r.Host != "" {
+		md.Set(":authority", r.Host)
+	}
+	return md
+}
+
+// transportStream maps the headers and trailers set by gRPC handlers to HTTP headers.
+type transportStream struct {
+	method string
+	header http.Header
+}
+
+func (s *transportStream) Method() string { return s.method }
+
+func (s *transportStream) SetHeader(md metadata.MD) error {
+	for k, vs := range md {
+		for _, v := range vs {
+			s.header.Add(k, v)
+		}
+	}
+	return nil
+}
+
+func (s *transportStream) SendHeader(md metadata.MD) error { return s.SetHeader(md) }
+
+func (s *transportStream) SetTrailer(md metadata.MD) error { return s.SetHeader(md) }
+
+// chainUnary chains the interceptors into one, the first is the outermost.
+func chainUnary(in []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	switch len(in) {
+	case 0:
+		return nil
+	case 1:
+		return in[0]
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		next := handler
+		for i := len(in) - 1; i > 0; i-- {
+			interceptor, h := in[i], next
+			next = func(ctx context.Context, req any) (any, error) {
+				return interceptor(ctx, req, info, h)
+			}
+		}
+		return in[0](ctx, req, info, next)
+	}
+}
@@ -0,0 +1,228 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		path     string
+		ok       bool
+		vars     map[string]string
+	}{
+		{"/v1/users/{id}", "/v1/users/1", true, map[string]string{"id": "1"}},
+		{"/v1/users/{id}", "/v1/users/1/books", false, nil},
+		{"/v1/users/{user.id}/books", "/v1/users/1/books", true, map[string]string{"user.id": "1"}},
+		{"/v1/{name=files/**}", "/v1/files/a/b.txt", true, map[string]string{"name": "files/a/b.txt"}},
+		{"/v1/{name=files/*}", "/v1/docs/a", false, nil},
+		{"/v1/users/{id}:undelete", "/v1/users/1:undelete", true, map[string]string{"id": "1"}},
+		{"/v1/users/{id}:undelete", "/v1/users/1", false, nil},
+	}
+	for _, tt := range tests {
+		tmpl, err := parseTemplate(tt.template)
+		require.NoError(t, err)
+		vars, ok := tmpl.match(tt.path)
+		assert.Equal(t, tt.ok, ok, tt.template+" "+tt.path)
+		if tt.ok {
+			assert.Equal(t, tt.vars, vars)
+		}
+	}
+
+	_, err := parseTemplate("v1/users")
+	assert.Error(t, err)
+}
+
+func TestRulesOf(t *testing.T) {
+	var custom []byte
+	custom = protowire.AppendTag(custom, 1, protowire.BytesType)
+	custom = protowire.AppendString(custom, "HEAD")
+	custom = protowire.AppendTag(custom, 2, protowire.BytesType)
+	custom = protowire.AppendString(custom, "/v1/users/{id}")
+
+	var additional []byte
+	additional = protowire.AppendTag(additional, 8, protowire.BytesType)
+	additional = protowire.AppendBytes(additional, custom)
+
+	var rule []byte
+	rule = protowire.AppendTag(rule, 4, protowire.BytesType)
+	rule = protowire.AppendString(rule, "/v1/users")
+	rule = protowire.AppendTag(rule, 7, protowire.BytesType)
+	rule = protowire.AppendString(rule, "user")
+	rule = protowire.AppendTag(rule, 11, protowire.BytesType)
+	rule = protowire.AppendBytes(rule, additional)
+
+	var raw []byte
+	raw = protowire.AppendTag(raw, httpRuleField, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, rule)
+
+	opts := &descriptorpb.MethodOptions{}
+	opts.ProtoReflect().SetUnknown(raw)
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:        proto.String("gateway_test.proto"),
+		Package:     proto.String("gateway.test"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("Empty")}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Users"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("CreateUser"),
+				InputType:  proto.String(".gateway.test.Empty"),
+				OutputType: proto.String(".gateway.test.Empty"),
+				Options:    opts,
+			}},
+		}},
+	}, nil)
+	require.NoError(t, err)
+
+	rules := rulesOf(fd.Services().Get(0).Methods().Get(0))
+	assert.Equal(t, []Rule{
+		{Method: http.MethodPost, Path: "/v1/users", Body: "user"},
+		{Method: "HEAD", Path: "/v1/users/{id}"},
+	}, rules)
+}
+
+func TestGateway(t *testing.T) {
+	hs := health.NewServer()
+	hs.SetServingStatus("users", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	var intercepted []string
+	gw := New(
+		WithUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			intercepted = append(intercepted, info.FullMethod)
+			_ = grpc.SetHeader(ctx, map[string][]string{"x-request-id": {"req-1"}})
+			return handler(ctx, req)
+		}),
+		WithFallback(http.NotFoundHandler()),
+	)
+	healthpb.RegisterHealthServer(gw, hs)
+	require.NoError(t, gw.Route(http.MethodGet, "/v1/health/{service}", "/grpc.health.v1.Health/Check"))
+	require.NoError(t, gw.Route(http.MethodGet, "/v1/health", "/grpc.health.v1.Health/Check"))
+	require.NoError(t, gw.Route(http.MethodPost, "/v1/health:check", "/grpc.health.v1.Health/Check"))
+	require.NoError(t, gw.Rule(Rule{Method: http.MethodGet, Path: "/v1/status/{service}", ResponseBody: "status"}, "/grpc.health.v1.Health/Check"))
+	require.NoError(t, gw.Route(http.MethodGet, "/v1/missing", "/grpc.health.v1.Health/Missing"))
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		gw.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := do(http.MethodGet, "/v1/health/users", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"NOT_SERVING"}`, w.Body.String())
+	assert.Equal(t, "req-1", w.Header().Get("X-Request-Id"))
+	assert.Equal(t, []string{"/grpc.health.v1.Health/Check"}, intercepted)
+
+	w = do(http.MethodGet, "/v1/health?service=", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"SERVING"}`, w.Body.String())
+
+	w = do(http.MethodPost, "/v1/health:check", `{"service":"users"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"NOT_SERVING"}`, w.Body.String())
+
+	w = do(http.MethodGet, "/v1/status/users", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"NOT_SERVING"`, w.Body.String())
+
+	var e errorBody
+	w = do(http.MethodGet, "/v1/health/unknown", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &e))
+	assert.Equal(t, http.StatusNotFound, e.Code)
+	assert.Equal(t, "unknown service", e.Message)
+
+	w = do(http.MethodPost, "/v1/health:check", `{"service":`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do(http.MethodDelete, "/v1/health/users", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	w = do(http.MethodGet, "/v1/missing", "")
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	w = do(http.MethodGet, "/other", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "404 page not found\n", w.Body.String())
+
+	assert.Len(t, gw.Routes()["/grpc.health.v1.Health/Check"], 4)
+}
+
+func TestGateway_RegisterInvalidRoute(t *testing.T) {
+	var rule []byte
+	rule = protowire.AppendTag(rule, 2, protowire.BytesType)
+	rule = protowire.AppendString(rule, "v1/users/{id}")
+
+	var raw []byte
+	raw = protowire.AppendTag(raw, httpRuleField, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, rule)
+
+	opts := &descriptorpb.MethodOptions{}
+	opts.ProtoReflect().SetUnknown(raw)
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:        proto.String("gateway_invalid_test.proto"),
+		Package:     proto.String("gateway.invalid"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("Empty")}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Users"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("GetUser"),
+				InputType:  proto.String(".gateway.invalid.Empty"),
+				OutputType: proto.String(".gateway.invalid.Empty"),
+				Options:    opts,
+			}},
+		}},
+	}, nil)
+	require.NoError(t, err)
+	require.NoError(t, protoregistry.GlobalFiles.RegisterFile(fd))
+
+	desc := &grpc.ServiceDesc{
+		ServiceName: "gateway.invalid.Users",
+		Methods:     []grpc.MethodDesc{{MethodName: "GetUser"}},
+	}
+
+	// 非法的路径模板返回错误, 而不是 panic
+	gw := New()
+	err = gw.Register(desc, nil)
+	assert.ErrorContains(t, err, "/gateway.invalid.Users/GetUser")
+	assert.Empty(t, gw.Routes())
+
+	gw = New()
+	require.NotPanics(t, func() { gw.RegisterService(desc, nil) })
+	assert.ErrorContains(t, gw.Err(), "must start with /")
+}
+
+func TestGateway_MaxBodySize(t *testing.T) {
+	gw := New(WithMaxBodySize(32))
+	healthpb.RegisterHealthServer(gw, health.NewServer())
+	require.NoError(t, gw.Route(http.MethodPost, "/v1/health:check", "/grpc.health.v1.Health/Check"))
+
+	do := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		gw.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/health:check", strings.NewReader(body)))
+		return w
+	}
+	assert.Equal(t, http.StatusOK, do(`{"service":""}`).Code)
+
+	w := do(`{"service":"` + strings.Repeat("a", 64) + `"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "RequestEntityTooLarge")
+}
@@ -0,0 +1,138 @@
+package gateway
+
+import (
+	"net/http"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// httpRuleField is the google.api.http extension number of google.protobuf.MethodOptions.
+const httpRuleField protowire.Number = 72295728
+
+// Rule maps an HTTP method and path template to a gRPC method, it mirrors google.api.HttpRule.
+type Rule struct {
+	// Method is the HTTP method, e.g. GET.
+	Method string
+	// Path is the path template, e.g. /v1/users/{id}.
+	Path string
+	// Body is the request field the body maps to, "*" for the whole request, "" for none.
+	Body string
+	// ResponseBody is the response field written as body, "" for the whole response.
+	ResponseBody string
+}
+
+// rulesOf returns the google.api.http rules of a method, including the additional bindings.
+// The annotation is decoded from the raw options so the annotations package is not required.
+func rulesOf(md protoreflect.MethodDescriptor) []Rule {
+	opts := md.Options()
+	if opts == nil {
+		return nil
+	}
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(opts.(proto.Message))
+	if err != nil {
+		return nil
+	}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil
+		}
+		b = b[n:]
+		if num == httpRuleField && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil
+			}
+			return parseRule(v)
+		}
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return nil
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// parseRule decodes a google.api.HttpRule message.
+func parseRule(b []byte) []Rule {
+	var (
+		r          Rule
+		additional []Rule
+	)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil
+		}
+		b = b[n:]
+		if typ != protowire.BytesType {
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return nil
+			}
+			b = b[n:]
+			continue
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return nil
+		}
+		b = b[n:]
+		switch num {
+		case 2:
+			r.Method, r.Path = http.MethodGet, string(v)
+		case 3:
+			r.Method, r.Path = http.MethodPut, string(v)
+		case 4:
+			r.Method, r.Path = http.MethodPost, string(v)
+		case 5:
+			r.Method, r.Path = http.MethodDelete, string(v)
+		case 6:
+			r.Method, r.Path = http.MethodPatch, string(v)
+		case 7:
+			r.Body = string(v)
+		case 8:
+			r.Method, r.Path = parseCustom(v)
+		case 11:
+			additional = append(additional, parseRule(v)...)
+		case 12:
+			r.ResponseBody = string(v)
+		}
+	}
+	if r.Method == "" || r.Path == "" {
+		return additional
+	}
+	return append([]Rule{r}, additional...)
+}
+
+// parseCustom decodes a google.api.CustomHttpPattern message.
+func parseCustom(b []byte) (method, path string) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return "", ""
+		}
+		b = b[n:]
+		if typ != protowire.BytesType {
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return "", ""
+			}
+			b = b[n:]
+			continue
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return "", ""
+		}
+		b = b[n:]
+		switch num {
+		case 1:
+			method = string(v)
+		case 2:
+			path = string(v)
+		}
+	}
+	return method, path
+}
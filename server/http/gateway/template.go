@@ -0,0 +1,114 @@
+package gateway
+
+import (
+	"fmt"
+	"strings"
+)
+
+// segment is a path template segment, either a literal or a variable.
+type segment struct {
+	literal string
+	// field is the request field path bound by a variable, e.g. "user.id"
+	field string
+	// prefix is the literal prefix of the variable pattern, e.g. "files" in {name=files/*}
+	prefix []string
+	// rest reports whether the variable matches the remaining segments, e.g. {name=**}
+	rest bool
+}
+
+// template is a parsed path template, such as /v1/users/{id} or /v1/{name=files/**}.
+// A variable matches a single segment unless its pattern ends with "**", its
+// value includes the literal prefix of its pattern.
+type template struct {
+	segments []segment
+	verb     string
+}
+
+func parseTemplate(path string) (*template, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("gateway: path template %q must start with /", path)
+	}
+	t := &template{}
+	path = path[1:]
+	if i := strings.LastIndex(path, ":"); i >= 0 && !strings.Contains(path[i:], "}") {
+		path, t.verb = path[:i], path[i+1:]
+	}
+
+	for len(path) > 0 {
+		if !strings.HasPrefix(path, "{") {
+			var lit string
+			lit, path, _ = strings.Cut(path, "/")
+			t.segments = append(t.segments, segment{literal: lit})
+			continue
+		}
+
+		end := strings.Index(path, "}")
+		if end < 0 {
+			return nil, fmt.Errorf("gateway: unclosed variable in path template %q", path)
+		}
+		v := path[1:end]
+		path = strings.TrimPrefix(path[end+1:], "/")
+
+		field, pattern, _ := strings.Cut(v, "=")
+		s := segment{field: field}
+		if pattern != "" {
+			parts := strings.Split(pattern, "/")
+			last := parts[len(parts)-1]
+			if last != "*" && last != "**" {
+				return nil, fmt.Errorf("gateway: unsupported variable pattern %q", pattern)
+			}
+			s.rest = last == "**"
+			s.prefix = parts[:len(parts)-1]
+		}
+		t.segments = append(t.segments, s)
+	}
+	return t, nil
+}
+
+// match matches path against the template and returns the bound variables.
+func (t *template) match(path string) (map[string]string, bool) {
+	path = strings.TrimPrefix(path, "/")
+	if t.verb != "" {
+		if !strings.HasSuffix(path, ":"+t.verb) {
+			return nil, false
+		}
+		path = strings.TrimSuffix(path, ":"+t.verb)
+	}
+	var parts []string
+	if path != "" {
+		parts = strings.Split(path, "/")
+	}
+
+	vars := make(map[string]string)
+	j := 0
+	for _, s := range t.segments {
+		if s.field == "" {
+			if j >= len(parts) || parts[j] != s.literal {
+				return nil, false
+			}
+			j++
+			continue
+		}
+
+		start := j
+		for _, p := range s.prefix {
+			if j >= len(parts) || (p != "*" && parts[j] != p) {
+				return nil, false
+			}
+			j++
+		}
+		if j >= len(parts) || parts[j] == "" {
+			return nil, false
+		}
+		if s.rest {
+			j = len(parts)
+		} else {
+			j++
+		}
+		vars[s.field] = strings.Join(parts[start:j], "/")
+	}
+	if j != len(parts) {
+		return nil, false
+	}
+	return vars, true
+}
@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Option is gateway option.
+type Option func(o *options)
+
+type options struct {
+	interceptors []grpc.UnaryServerInterceptor
+	fallback     http.Handler
+	marshal      protojson.MarshalOptions
+	unmarshal    protojson.UnmarshalOptions
+	maxBodySize  int64
+}
+
+// WithUnaryInterceptor set the unary interceptors run around every transcoded call,
+// e.g. the ones of server/grpc/interceptors.
+func WithUnaryInterceptor(in ...grpc.UnaryServerInterceptor) Option {
+	return func(o *options) {
+		o.interceptors = append(o.interceptors, in...)
+	}
+}
+
+// WithFallback set the handler serving requests matching no route.
+func WithFallback(h http.Handler) Option {
+	return func(o *options) {
+		o.fallback = h
+	}
+}
+
+// WithMarshalOptions set the JSON marshal options of responses.
+func WithMarshalOptions(opts protojson.MarshalOptions) Option {
+	return func(o *options) {
+		o.marshal = opts
+	}
+}
+
+// WithUnmarshalOptions set the JSON unmarshal options of request bodies.
+func WithUnmarshalOptions(opts protojson.UnmarshalOptions) Option {
+	return func(o *options) {
+		o.unmarshal = opts
+	}
+}
+
+// WithMaxBodySize set the maximum size of request bodies, 4 MiB by default as the maximum
+// message size of a gRPC server, a larger body is rejected with 413. A size <= 0 disables
+// the limit.
+func WithMaxBodySize(size int64) Option {
+	return func(o *options) {
+		o.maxBodySize = size
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"sync"
+	"sync/atomic"
 
 	"github.com/apus-run/van/server"
 	"github.com/apus-run/van/server/internal/endpoint"
@@ -19,6 +21,11 @@ var _ server.Server = (*Server)(nil)
 type Server struct {
 	*http.Server
 	options *server.ServerOptions
+
+	mu     sync.RWMutex
+	routes map[string]http.Handler
+
+	serving atomic.Bool
 }
 
 func NewServer(opts ...server.ServerOption) *Server {
@@ -45,6 +52,9 @@ func (s *Server) Start(ctx context.Context) error {
 		return ctx
 	}
 
+	s.serving.Store(true)
+	defer s.serving.Store(false)
+
 	var err error
 	if s.options.TLSConfig != nil {
 		slog.Info("[HTTPS] server listen on", "address", s.options.Address)
@@ -61,6 +71,7 @@ func (s *Server) Start(ctx context.Context) error {
 }
 
 func (s *Server) Stop(ctx context.Context) error {
+	s.serving.Store(false)
 	return shutdown.ShutdownWithContext(ctx, func(ctx context.Context) error {
 		return s.Server.Shutdown(ctx)
 	}, func() error {
@@ -84,23 +95,31 @@ func (s *Server) Endpoint() (*url.URL, error) {
 	return s.options.Endpoint, nil
 }
 
+// Handle registers the handler for the exact path pattern, it takes
+// precedence over the server handler.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.routes == nil {
+		s.routes = make(map[string]http.Handler)
+	}
+	s.routes[pattern] = handler
+}
+
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	h, ok := s.routes[r.URL.Path]
+	s.mu.RUnlock()
+	if ok {
+		h.ServeHTTP(w, r)
+		return
+	}
 	s.options.Handler.ServeHTTP(w, r)
 }
 
-// Health
+// Health reports whether the server is listening and serving requests.
 func (s *Server) Health() bool {
-	if s.options.Listener == nil {
-		return false
-	}
-
-	conn, err := s.options.Listener.Accept()
-	if err != nil {
-		return false
-	}
-
-	e := conn.Close()
-	return e == nil
+	return s.options.Listener != nil && s.serving.Load()
 }
 
 func (s *Server) listenAndEndpoint() error {
@@ -0,0 +1,231 @@
+package mux
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/apus-run/van/server"
+	"github.com/apus-run/van/server/internal/endpoint"
+	"github.com/apus-run/van/server/internal/host"
+	"github.com/apus-run/van/server/internal/shutdown"
+)
+
+var (
+	_ server.Server     = (*Server)(nil)
+	_ server.Endpointer = (*Server)(nil)
+)
+
+// Server serves HTTP/1.1, h2c and gRPC on a single listener.
+//
+// HTTP/2 requests with an application/grpc content type are dispatched to the
+// embedded grpc.Server, the others to the HTTP handler. gRPC is served through
+// grpc.Server.ServeHTTP, the TLS config, if any, applies to both protocols.
+type Server struct {
+	*grpc.Server
+
+	http    *http.Server
+	options *server.ServerOptions
+
+	mu     sync.RWMutex
+	routes map[string]http.Handler
+
+	serving atomic.Bool
+
+	// the h2c connections are hijacked, http.Server.Shutdown doesn't wait for
+	// the gRPC calls served over them, Stop waits for calls instead
+	callsMu  sync.Mutex
+	draining bool
+	calls    sync.WaitGroup
+}
+
+func NewServer(opts ...server.ServerOption) *Server {
+	options := Apply(opts...)
+
+	srv := &Server{
+		options: options,
+	}
+
+	grpcOpts := []grpc.ServerOption{}
+	if len(options.UnaryInterceptors) > 0 {
+		grpcOpts = append(grpcOpts, grpc.ChainUnaryInterceptor(options.UnaryInterceptors...))
+	}
+	if len(options.StreamInterceptors) > 0 {
+		grpcOpts = append(grpcOpts, grpc.ChainStreamInterceptor(options.StreamInterceptors...))
+	}
+	if len(options.Options) > 0 {
+		grpcOpts = append(grpcOpts, options.Options...)
+	}
+	srv.Server = grpc.NewServer(grpcOpts...)
+
+	handler := http.Handler(srv)
+	if options.TLSConfig == nil {
+		handler = h2c.NewHandler(srv, &http2.Server{})
+	}
+	srv.http = &http.Server{
+		Handler:   handler,
+		TLSConfig: options.TLSConfig,
+	}
+
+	return srv
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	if err := s.listenAndEndpoint(); err != nil {
+		return err
+	}
+
+	s.http.BaseContext = func(listener net.Listener) context.Context {
+		return ctx
+	}
+
+	s.serving.Store(true)
+	defer s.serving.Store(false)
+
+	var err error
+	if s.options.TLSConfig != nil {
+		slog.Info("[HTTPS/gRPC] server listen on", "address", s.options.Address)
+		err = s.http.ServeTLS(s.options.Listener, "", "")
+	} else {
+		slog.Info("[HTTP/gRPC] server listen on", "address", s.options.Address)
+		err = s.http.Serve(s.options.Listener)
+	}
+
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Stop stops accepting connections and gRPC calls, and waits for the requests and
+// the gRPC calls in flight. The gRPC calls are canceled when ctx is done.
+func (s *Server) Stop(ctx context.Context) error {
+	s.serving.Store(false)
+	s.callsMu.Lock()
+	s.draining = true
+	s.callsMu.Unlock()
+	return shutdown.ShutdownWithContext(ctx, func(ctx context.Context) error {
+		err := s.http.Shutdown(ctx)
+		s.calls.Wait()
+		return err
+	}, func() error {
+		// closing the connections cancels the gRPC streams served over them
+		s.Server.Stop()
+		return s.http.Close()
+	})
+}
+
+// Handle registers the handler for the exact path pattern, it takes
+// precedence over the server handler.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.routes == nil {
+		s.routes = make(map[string]http.Handler)
+	}
+	s.routes[pattern] = handler
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if isGRPC(r) {
+		if !s.acquireCall() {
+			// trailers-only response, the client may retry on another server
+			w.Header().Set("Content-Type", "application/grpc")
+			w.Header().Set("Grpc-Status", strconv.Itoa(int(codes.Unavailable)))
+			w.Header().Set("Grpc-Message", "server is stopping")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		defer s.calls.Done()
+		s.Server.ServeHTTP(w, r)
+		return
+	}
+	s.mu.RLock()
+	h, ok := s.routes[r.URL.Path]
+	s.mu.RUnlock()
+	if ok {
+		h.ServeHTTP(w, r)
+		return
+	}
+	s.options.Handler.ServeHTTP(w, r)
+}
+
+// acquireCall tracks a gRPC call, it returns false once the server is stopping.
+func (s *Server) acquireCall() bool {
+	s.callsMu.Lock()
+	defer s.callsMu.Unlock()
+	if s.draining {
+		return false
+	}
+	s.calls.Add(1)
+	return true
+}
+
+// isGRPC reports whether r is a gRPC request.
+func isGRPC(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// Health reports whether the server is listening and serving requests.
+func (s *Server) Health() bool {
+	return s.options.Listener != nil && s.serving.Load()
+}
+
+// Endpoint return the HTTP endpoint, Endpoints returns both the HTTP and gRPC ones.
+// examples:
+//
+//	http://127.0.0.1:8000
+func (s *Server) Endpoint() (*url.URL, error) {
+	if err := s.listenAndEndpoint(); err != nil {
+		return nil, s.options.Error
+	}
+	return s.options.Endpoint, nil
+}
+
+// Endpoints return the real addresses to registry endpoint.
+// examples:
+//
+//	http://127.0.0.1:8000
+//	grpc://127.0.0.1:8000
+func (s *Server) Endpoints() ([]*url.URL, error) {
+	if err := s.listenAndEndpoint(); err != nil {
+		return nil, s.options.Error
+	}
+	secure := s.options.TLSConfig != nil
+	return []*url.URL{
+		s.options.Endpoint,
+		endpoint.NewEndpoint(endpoint.Scheme("grpc", secure), s.options.Endpoint.Host),
+	}, nil
+}
+
+func (s *Server) listenAndEndpoint() error {
+	if s.options.Listener == nil {
+		lis, err := net.Listen(s.options.Network, s.options.Address)
+		if err != nil {
+			s.options.Error = err
+			return err
+		}
+		s.options.Listener = lis
+	}
+	if s.options.Endpoint == nil {
+		addr, err := host.Extract(s.options.Address, s.options.Listener)
+		if err != nil {
+			s.options.Error = err
+			return err
+		}
+		s.options.Endpoint = endpoint.NewEndpoint(endpoint.Scheme("http", s.options.TLSConfig != nil), addr)
+	}
+	return s.options.Error
+}
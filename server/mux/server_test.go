@@ -0,0 +1,148 @@
+package mux_test
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/apus-run/van/server"
+	"github.com/apus-run/van/server/mux"
+)
+
+func TestServer(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	})
+	srv := mux.NewServer(server.WithListener(lis), server.WithHandler(handler))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	srv.Handle("/ping", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "pong")
+	}))
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Start(context.Background()) }()
+	require.Eventually(t, srv.Health, time.Second, 10*time.Millisecond)
+
+	endpoints, err := srv.Endpoints()
+	require.NoError(t, err)
+	require.Len(t, endpoints, 2)
+	assert.Equal(t, "http", endpoints[0].Scheme)
+	assert.Equal(t, "grpc", endpoints[1].Scheme)
+	assert.Equal(t, endpoints[0].Host, endpoints[1].Host)
+	e, err := srv.Endpoint()
+	require.NoError(t, err)
+	assert.Equal(t, endpoints[0], e)
+
+	base := "http://" + lis.Addr().String()
+	get := func(c *http.Client, path string) string {
+		resp, err := c.Get(base + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+	assert.Equal(t, "HTTP/1.1", get(http.DefaultClient, "/"))
+	assert.Equal(t, "pong", get(http.DefaultClient, "/ping"))
+
+	h2c := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+	assert.Equal(t, "HTTP/2.0", get(h2c, "/"))
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, srv.Stop(ctx))
+	assert.NoError(t, <-done)
+	assert.False(t, srv.Health())
+}
+
+// slowHealth 的 Check 在 release 关闭前不返回
+type slowHealth struct {
+	healthpb.UnimplementedHealthServer
+	once    sync.Once
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *slowHealth) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	h.once.Do(func() { close(h.started) })
+	<-h.release
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func TestServer_StopDrainsGRPC(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	h := &slowHealth{started: make(chan struct{}), release: make(chan struct{})}
+	srv := mux.NewServer(server.WithListener(lis), server.WithHandler(http.NotFoundHandler()))
+	healthpb.RegisterHealthServer(srv, h)
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Start(context.Background()) }()
+	require.Eventually(t, srv.Health, time.Second, 10*time.Millisecond)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	called := make(chan error, 1)
+	go func() {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		called <- err
+	}()
+	<-h.started
+
+	stopped := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		stopped <- srv.Stop(ctx)
+	}()
+
+	// Stop 等待进行中的调用, 新的调用被拒绝
+	require.Eventually(t, func() bool {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		return status.Code(err) == codes.Unavailable
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case err := <-stopped:
+		t.Fatalf("Stop returned with a call in flight: %v", err)
+	default:
+	}
+
+	close(h.release)
+	require.NoError(t, <-called)
+	require.NoError(t, <-stopped)
+	require.NoError(t, <-done)
+}
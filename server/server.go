@@ -12,3 +12,9 @@ type Server interface {
 	Health() bool
 	Endpoint() (*url.URL, error)
 }
+
+// Endpointer is implemented by servers advertising several endpoints,
+// e.g. a server serving HTTP and gRPC on one listener.
+type Endpointer interface {
+	Endpoints() ([]*url.URL, error)
+}
@@ -22,6 +22,10 @@ type ServerOptions struct {
 	Handler http.Handler
 	// Options 指定 gRPC 服务器的选项。
 	Options []grpc.ServerOption
+	// UnaryInterceptors 指定 gRPC 服务器的一元拦截器，按顺序链式执行。
+	UnaryInterceptors []grpc.UnaryServerInterceptor
+	// StreamInterceptors 指定 gRPC 服务器的流式拦截器，按顺序链式执行。
+	StreamInterceptors []grpc.StreamServerInterceptor
 
 	// TLSConfig 指定 TLS 配置。
 	TLSConfig *tls.Config
@@ -74,3 +78,17 @@ func WithOptions(gopts ...grpc.ServerOption) ServerOption {
 		options.Options = append(options.Options, gopts...)
 	}
 }
+
+// WithUnaryInterceptor 设置 gRPC 服务器的一元拦截器。
+func WithUnaryInterceptor(in ...grpc.UnaryServerInterceptor) ServerOption {
+	return func(options *ServerOptions) {
+		options.UnaryInterceptors = append(options.UnaryInterceptors, in...)
+	}
+}
+
+// WithStreamInterceptor 设置 gRPC 服务器的流式拦截器。
+func WithStreamInterceptor(in ...grpc.StreamServerInterceptor) ServerOption {
+	return func(options *ServerOptions) {
+		options.StreamInterceptors = append(options.StreamInterceptors, in...)
+	}
+}
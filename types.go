@@ -4,7 +4,9 @@ import (
 	"context"
 	"net/url"
 	"os"
+	"time"
 
+	"github.com/apus-run/van/health"
 	"github.com/apus-run/van/registry"
 	"github.com/apus-run/van/server"
 )
@@ -29,10 +31,21 @@ type options struct {
 	registry registry.Registry
 	// sevice servers
 	servers []server.Server
+	// lifecycle components
+	components []*component
+
+	// default stop timeout of servers and components
+	stopTimeout time.Duration
+	// delay between readiness failing and the servers stopping
+	drainDelay time.Duration
 
 	context context.Context
 	signals []os.Signal
 
+	// health checks
+	health       *health.Health
+	healthChecks map[string]health.Checker
+
 	// Before and After funcs
 	beforeStart []func(context.Context) error
 	beforeStop  []func(context.Context) error
@@ -75,6 +88,29 @@ func WithServer(srv ...server.Server) Option {
 	return func(o *options) { o.servers = srv }
 }
 
+// WithComponent with a lifecycle component.
+func WithComponent(c Component, opts ...ComponentOption) Option {
+	return func(o *options) {
+		comp := &component{Component: c}
+		for _, opt := range opts {
+			opt(comp)
+		}
+		o.components = append(o.components, comp)
+	}
+}
+
+// WithStopTimeout with the default stop timeout of servers and components.
+func WithStopTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.stopTimeout = timeout }
+}
+
+// WithDrainDelay with the delay Stop waits between readiness reporting NOT_SERVING and the
+// servers stopping, so that load balancers and the registry watchers stop routing to the
+// service first. It is 3s by default, capped by the stop timeout, 0 stops the servers at once.
+func WithDrainDelay(delay time.Duration) Option {
+	return func(o *options) { o.drainDelay = delay }
+}
+
 // WithSignal with exit signals.
 func WithSignal(sigs ...os.Signal) Option {
 	return func(o *options) { o.signals = sigs }
@@ -85,6 +121,21 @@ func WithRegistry(r registry.Registry) Option {
 	return func(o *options) { o.registry = r }
 }
 
+// WithHealth with service health checks aggregator.
+func WithHealth(h *health.Health) Option {
+	return func(o *options) { o.health = h }
+}
+
+// WithHealthCheck with a named readiness check, such as a db or redis ping.
+func WithHealthCheck(name string, checker health.Checker) Option {
+	return func(o *options) {
+		if o.healthChecks == nil {
+			o.healthChecks = make(map[string]health.Checker)
+		}
+		o.healthChecks[name] = checker
+	}
+}
+
 // Before and Afters
 
 // BeforeStart run funcs before app starts
@@ -69,6 +69,39 @@ func (v *Validator) validCtx(ctx context.Context, obj any) error {
 	return nil
 }
 
+// FieldError 字段验证错误
+type FieldError struct {
+	// Namespace 字段路径，不含结构体名称，例如 DB.DSN
+	Namespace string
+	// Message 翻译后的错误信息
+	Message string
+}
+
+// ValidateFields 验证结构体并返回全部字段错误，非字段错误通过 error 返回
+func (v *Validator) ValidateFields(s any) ([]FieldError, error) {
+	if reflect.Indirect(reflect.ValueOf(s)).Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	e := v.validate.Struct(s)
+	if e == nil {
+		return nil, nil
+	}
+	var errs validator.ValidationErrors
+	if !errors.As(e, &errs) {
+		return nil, e
+	}
+	fields := make([]FieldError, 0, len(errs))
+	for _, fe := range errs {
+		ns := fe.StructNamespace()
+		if i := strings.Index(ns, "."); i >= 0 {
+			ns = ns[i+1:]
+		}
+		fields = append(fields, FieldError{Namespace: ns, Message: fe.Translate(v.translator)})
+	}
+	return fields, nil
+}
+
 func removeStructName(fields map[string]string) error {
 	errs := make([]string, 0, len(fields))
 	for _, err := range fields {
@@ -171,3 +171,30 @@ func TestGetLanguage2(t *testing.T) {
 	}
 	t.Log(idx)
 }
+
+func TestValidateFields(t *testing.T) {
+	type Address struct {
+		City string `validate:"required"`
+	}
+	type User struct {
+		Name      string    `validate:"required"`
+		Addresses []Address `validate:"dive"`
+	}
+
+	zhTrans := zh.New()
+	trans, _ := ut.New(zhTrans, zhTrans).GetTranslator("zh")
+	v := &Validator{validate: validator.New(), translator: trans}
+
+	fields, err := v.ValidateFields(User{Addresses: []Address{{City: "sh"}, {}}})
+	assert.NoError(t, err)
+	namespaces := make([]string, 0, len(fields))
+	for _, f := range fields {
+		namespaces = append(namespaces, f.Namespace)
+		assert.NotEmpty(t, f.Message)
+	}
+	assert.ElementsMatch(t, []string{"Name", "Addresses[1].City"}, namespaces)
+
+	fields, err = v.ValidateFields(User{Name: "tom"})
+	assert.NoError(t, err)
+	assert.Empty(t, fields)
+}
@@ -3,6 +3,7 @@ package van
 import (
 	"context"
 	"errors"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
@@ -11,8 +12,12 @@ import (
 
 	"github.com/google/uuid"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
+	"github.com/apus-run/van/health"
 	"github.com/apus-run/van/registry"
+	"github.com/apus-run/van/server"
 )
 
 // Service is an application components lifecycle manager.
@@ -24,11 +29,17 @@ type Service struct {
 	instance *registry.ServiceInstance
 }
 
+// defaultDrainDelay is the default delay between readiness failing and the servers stopping,
+// long enough for the load balancers probing readiness every second or so.
+const defaultDrainDelay = 3 * time.Second
+
 // New create an application lifecycle manager.
 func New(opts ...Option) *Service {
 	o := &options{
-		context: context.Background(),
-		signals: []os.Signal{syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGINT},
+		context:     context.Background(),
+		signals:     []os.Signal{syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGINT},
+		stopTimeout: 10 * time.Second,
+		drainDelay:  defaultDrainDelay,
 	}
 	if id, err := uuid.NewUUID(); err == nil {
 		o.id = id.String()
@@ -36,6 +47,12 @@ func New(opts ...Option) *Service {
 	for _, opt := range opts {
 		opt(o)
 	}
+	if o.health == nil {
+		o.health = health.New()
+	}
+	for name, checker := range o.healthChecks {
+		o.health.Register(name, checker)
+	}
 
 	ctx, cancel := context.WithCancel(o.context)
 	return &Service{
@@ -57,6 +74,9 @@ func (s *Service) Version() string { return s.options.version }
 // Metadata returns service metadata.
 func (s *Service) Metadata() map[string]string { return s.options.metadata }
 
+// Health returns the health checks aggregator, components may register their checks on it.
+func (s *Service) Health() *health.Health { return s.options.health }
+
 // Endpoint returns endpoints.
 func (s *Service) Endpoint() []string {
 	if s.instance != nil {
@@ -83,11 +103,20 @@ func (s *Service) Run() error {
 			return err
 		}
 	}
+	components, err := sortComponents(s.options.components)
+	if err != nil {
+		return err
+	}
+	if err = s.startComponents(c, components); err != nil {
+		return err
+	}
+
+	s.registerHealth()
 	for _, srv := range s.options.servers {
 		server := srv
 		eg.Go(func() error {
 			<-ctx.Done() // wait for stop signal
-			stopCtx, cancel := context.WithTimeout(NewContext(s.options.context, s), 10*time.Second)
+			stopCtx, cancel := context.WithTimeout(NewContext(s.options.context, s), s.options.stopTimeout)
 			defer cancel()
 			return server.Stop(stopCtx)
 		})
@@ -102,12 +131,12 @@ func (s *Service) Run() error {
 		rctx, rcancel := context.WithTimeout(ctx, 10*time.Second)
 		defer rcancel()
 		if err = s.options.registry.Register(rctx, instance); err != nil {
-			return err
+			return s.rollback(eg, components, err)
 		}
 	}
 	for _, fn := range s.options.afterStart {
 		if err = fn(c); err != nil {
-			return err
+			return s.rollback(eg, components, err)
 		}
 	}
 
@@ -122,17 +151,31 @@ func (s *Service) Run() error {
 		}
 	})
 	if err = eg.Wait(); err != nil && !errors.Is(err, context.Canceled) {
-		return err
+		return errors.Join(err, s.stopComponents(components))
 	}
-	err = nil
+	err = s.stopComponents(components)
 	for _, fn := range s.options.afterStop {
-		err = fn(c)
+		if e := fn(c); e != nil {
+			err = e
+		}
 	}
 	return err
 }
 
+// rollback stops the started servers and components after a startup failure.
+func (s *Service) rollback(eg *errgroup.Group, components []*component, err error) error {
+	s.cancel()
+	_ = eg.Wait()
+	return errors.Join(err, s.stopComponents(components))
+}
+
 // Stop gracefully stops the application.
 func (s *Service) Stop() (err error) {
+	// flip readiness first so load balancers drain us before shutdown
+	if s.options.health != nil {
+		s.options.health.Shutdown()
+	}
+
 	sctx := NewContext(s.ctx, s)
 	for _, fn := range s.options.beforeStop {
 		if err = fn(sctx); err != nil {
@@ -150,12 +193,47 @@ func (s *Service) Stop() (err error) {
 			return err
 		}
 	}
+	if d := min(s.options.drainDelay, s.options.stopTimeout); d > 0 && len(s.options.servers) > 0 {
+		t := time.NewTimer(d)
+		select {
+		case <-t.C:
+		case <-s.ctx.Done():
+			t.Stop()
+		}
+	}
 	if s.cancel != nil {
 		s.cancel()
 	}
 	return err
 }
 
+// registerHealth serves the health checks on every server supporting it,
+// /healthz, /readyz and /livez on HTTP servers and grpc.health.v1 on gRPC servers.
+func (s *Service) registerHealth() {
+	h := s.options.health
+	if h == nil {
+		return
+	}
+	for _, srv := range s.options.servers {
+		if r, ok := srv.(interface {
+			Handle(pattern string, handler http.Handler)
+		}); ok {
+			handler := h.Handler()
+			for _, p := range []string{health.HealthzPath, health.ReadyzPath, health.LivezPath} {
+				r.Handle(p, handler)
+			}
+		}
+		if r, ok := srv.(interface {
+			grpc.ServiceRegistrar
+			GetServiceInfo() map[string]grpc.ServiceInfo
+		}); ok {
+			if _, exists := r.GetServiceInfo()[healthpb.Health_ServiceDesc.ServiceName]; !exists {
+				healthpb.RegisterHealthServer(r, h.GRPCServer())
+			}
+		}
+	}
+}
+
 func (s *Service) registryService() (*registry.ServiceInstance, error) {
 	endpoints := make([]string, 0, len(s.options.endpoints))
 	for _, e := range s.options.endpoints {
@@ -163,6 +241,16 @@ func (s *Service) registryService() (*registry.ServiceInstance, error) {
 	}
 	if len(endpoints) == 0 {
 		for _, srv := range s.options.servers {
+			if m, ok := srv.(server.Endpointer); ok {
+				es, err := m.Endpoints()
+				if err != nil {
+					return nil, err
+				}
+				for _, e := range es {
+					endpoints = append(endpoints, e.String())
+				}
+				continue
+			}
 			e, err := srv.Endpoint()
 			if err != nil {
 				return nil, err
@@ -3,12 +3,14 @@ package van
 import (
 	"context"
 	"errors"
+	nethttp "net/http"
 	"net/url"
 	"reflect"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/apus-run/van/health"
 	"github.com/apus-run/van/registry"
 	"github.com/apus-run/van/server"
 	"github.com/apus-run/van/server/grpc"
@@ -323,3 +325,108 @@ func Test_App_Service(t *testing.T) {
 	}
 
 }
+
+func TestApp_Health(t *testing.T) {
+	hs := http.NewServer(server.WithAddress("127.0.0.1:0"))
+	gs := grpc.NewServer(server.WithAddress("127.0.0.1:0"))
+	app := New(
+		WithName("van"),
+		WithServer(hs, gs),
+		WithHealthCheck("db", health.CheckerFunc(func(context.Context) error { return nil })),
+		// the default drain delay is capped by the stop timeout
+		WithStopTimeout(500*time.Millisecond),
+	)
+
+	done := make(chan error, 1)
+	go func() { done <- app.Run() }()
+
+	u, err := hs.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp *nethttp.Response
+	for i := 0; i < 50; i++ {
+		if resp, err = nethttp.Get(u.String() + health.ReadyzPath); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusOK {
+		t.Fatalf("readyz status = %d, want %d", resp.StatusCode, nethttp.StatusOK)
+	}
+	if _, ok := gs.GetServiceInfo()["grpc.health.v1.Health"]; !ok {
+		t.Fatal("grpc health service is not registered")
+	}
+
+	start := time.Now()
+	if err = app.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 500*time.Millisecond || d >= defaultDrainDelay {
+		t.Fatalf("Stop returned after %v, want the drain delay capped by the stop timeout", d)
+	}
+	if app.Health().Ready(context.Background()).Status != health.StatusNotServing {
+		t.Fatal("readiness should be NOT_SERVING after Stop")
+	}
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApp_DrainDelay(t *testing.T) {
+	hs := http.NewServer(server.WithAddress("127.0.0.1:0"))
+	app := New(
+		WithName("van"),
+		WithServer(hs),
+		WithDrainDelay(300*time.Millisecond),
+	)
+
+	// listen before Run, Endpoint is not safe for concurrent use
+	u, err := hs.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- app.Run() }()
+
+	var resp *nethttp.Response
+	for i := 0; i < 50; i++ {
+		if resp, err = nethttp.Get(u.String() + health.ReadyzPath); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	start := time.Now()
+	stopped := make(chan error, 1)
+	go func() { stopped <- app.Stop() }()
+
+	// the servers keep serving during the drain delay, readiness fails
+	time.Sleep(100 * time.Millisecond)
+	resp, err = nethttp.Get(u.String() + health.ReadyzPath)
+	if err != nil {
+		t.Fatalf("server stopped before the drain delay: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusServiceUnavailable {
+		t.Fatalf("readyz status = %d, want %d", resp.StatusCode, nethttp.StatusServiceUnavailable)
+	}
+
+	if err = <-stopped; err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 300*time.Millisecond {
+		t.Fatalf("Stop returned after %v, before the drain delay", d)
+	}
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+}